@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"go.uber.org/zap"
+
+	"go.infratographer.com/tenant-api/internal/config"
+	"go.infratographer.com/tenant-api/internal/metrics"
+	"go.infratographer.com/tenant-api/internal/pubsub"
+)
+
+// newEventsPublisher returns the publisher changes are published with, which
+// fans changes out to the events publisher and any additional publishers
+func newEventsPublisher() pubsub.Publisher {
+	if err := pubsub.ValidatePrimary(config.AppConfig.Events.Publisher, changesSubscribed()); err != nil {
+		logger.Fatalw("invalid events publisher", "error", err)
+	}
+
+	cfgs := pubsub.PublisherConfigs(config.AppConfig.Events.Publisher, config.AppConfig.Events.Publishers)
+
+	backends := make([]pubsub.Backend, 0, len(cfgs))
+
+	for _, cfg := range cfgs {
+		p, err := pubsub.NewPublisher(cfg, logger.Named("events").With("backend", cfg.Name))
+		if err != nil {
+			logger.Fatalw("unable to initialize event publisher", "backend", cfg.Name, "error", err)
+		}
+
+		backends = append(backends, metrics.InstrumentBackend(pubsub.Backend{
+			Name:      cfg.Name,
+			Policy:    cfg.Policy,
+			Publisher: p,
+		}))
+	}
+
+	publisher, err := pubsub.NewFanoutPublisher(backends, logger.Named("events"))
+	if err != nil {
+		logger.Fatal("unable to initialize event publisher", zap.Error(err))
+	}
+
+	return publisher
+}
+
+// changesSubscribed returns whether the events publisher's changes are
+// subscribed to by tenant-api, wherever they're published from. Webhooks
+// always subscribe, and the cache does when there's a subscriber to
+// invalidate it with. gRPC watches are left unavailable rather than
+// subscribing to changes they can't read, and the worker only subscribes to
+// commands.
+func changesSubscribed() bool {
+	if config.AppConfig.Webhooks.Enabled {
+		return true
+	}
+
+	return config.AppConfig.Events.Subscriber.URL != "" && config.AppConfig.Cache.Size > 0
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/tenant-api/internal/config"
+	"go.infratographer.com/tenant-api/internal/database"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate <command> [args]",
+	Short: "Manage database schema migrations",
+	Long: `Migrate provides a wrapper around the "goose" migration tool, using the
+migrations for the configured database driver.
+
+Commands:
+up                   Migrate the DB to the most recent version available
+up-by-one            Migrate the DB up by 1
+up-to VERSION        Migrate the DB to a specific VERSION
+down                 Roll back the version by 1
+down-to VERSION      Roll back to a specific VERSION
+redo                 Re-run the latest migration
+reset                Roll back all migrations
+status               Dump the migration status for the current DB
+version              Print the current version of the database
+	`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		migrate(args[0], args[1:])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+}
+
+func migrate(command string, args []string) {
+	database.SetMigrationLogger(logger)
+
+	db, err := database.Open(config.AppConfig.DB, config.AppConfig.CRDB, false)
+	if err != nil {
+		logger.Fatal("unable to initialize database", zap.Error(err))
+	}
+
+	defer db.Close()
+
+	if err := db.Migrate(command, args...); err != nil {
+		logger.Fatalw("migrate command failed", "command", command, "error", err)
+	}
+}
+
+// openDatabase opens the configured database. SQLite databases belong to a
+// single process, so they're migrated to the latest version when opened.
+func openDatabase() *database.DB {
+	db, err := database.Open(config.AppConfig.DB, config.AppConfig.CRDB, config.AppConfig.Tracing.Enabled)
+	if err != nil {
+		logger.Fatal("unable to initialize database", zap.Error(err))
+	}
+
+	if db.Driver == database.DriverSQLite {
+		database.SetMigrationLogger(logger)
+
+		if err := db.MigrateUp(); err != nil {
+			logger.Fatal("failed to migrate sqlite database", zap.Error(err))
+		}
+	}
+
+	return db
+}
@@ -8,15 +8,16 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.infratographer.com/x/crdbx"
-	"go.infratographer.com/x/goosex"
 	"go.infratographer.com/x/loggingx"
 	"go.infratographer.com/x/otelx"
 	"go.infratographer.com/x/versionx"
 	"go.infratographer.com/x/viperx"
 	"go.uber.org/zap"
 
-	dbm "go.infratographer.com/tenant-api/db"
 	"go.infratographer.com/tenant-api/internal/config"
+	"go.infratographer.com/tenant-api/internal/database"
+	"go.infratographer.com/tenant-api/internal/limits"
+	"go.infratographer.com/tenant-api/internal/validation"
 )
 
 const appName = "tenant-api"
@@ -58,13 +59,15 @@ func init() {
 
 	// Database Flags
 	crdbx.MustViperFlags(viper.GetViper(), rootCmd.Flags())
+	database.MustViperFlags(viper.GetViper(), rootCmd.PersistentFlags())
 
-	// Add migrate command
-	goosex.RegisterCobraCommand(rootCmd, func() {
-		goosex.SetBaseFS(dbm.Migrations)
-		goosex.SetLogger(logger)
-		goosex.SetDBURI(config.AppConfig.CRDB.GetURI())
-	})
+	// Validation policy flags, persistent as every command changing tenants
+	// enforces it
+	validation.MustViperFlags(viper.GetViper(), rootCmd.PersistentFlags())
+
+	// Tenant hierarchy limit flags, persistent as every command creating
+	// tenants enforces them
+	limits.MustViperFlags(viper.GetViper(), rootCmd.PersistentFlags())
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -83,18 +86,43 @@ func initConfig() {
 
 	viper.AutomaticEnv() // read in environment variables that match
 
+	// If a config file is found, read it in. It's read before the app config
+	// is loaded for settings which can't be set by flags or environment
+	// variables, such as additional events publishers.
+	err := viper.ReadInConfig()
+
 	setupAppConfig()
 
 	// setupLogging()
 	logger = loggingx.InitLogger(appName, config.AppConfig.Logging)
 
-	// If a config file is found, read it in.
-	err := viper.ReadInConfig()
 	if err == nil {
 		logger.Infow("using config file",
 			"file", viper.ConfigFileUsed(),
 		)
 	}
+
+	setupValidationPolicy()
+}
+
+// setupValidationPolicy loads the validation policy of tenant names and
+// descriptions from its file, the default policy is kept when it isn't set
+func setupValidationPolicy() {
+	path := config.AppConfig.Validation.PolicyFile
+	if path == "" {
+		return
+	}
+
+	policy, err := validation.LoadPolicy(path)
+	if err != nil {
+		logger.Fatalw("failed to load validation policy", "file", path, "error", err)
+	}
+
+	if err := validation.SetPolicy(policy); err != nil {
+		logger.Fatalw("failed to set validation policy", "file", path, "error", err)
+	}
+
+	logger.Infow("using validation policy", "file", path)
 }
 
 // setupAppConfig loads our config.AppConfig struct with the values bound by
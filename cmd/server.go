@@ -2,28 +2,46 @@ package cmd
 
 import (
 	"context"
+	"database/sql"
+	"net"
+	"os"
+	"strings"
+	"time"
 
-	"entgo.io/ent/dialect"
-	entsql "entgo.io/ent/dialect/sql"
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/nats-io/nats.go"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"go.infratographer.com/x/crdbx"
 	"go.infratographer.com/x/echojwtx"
 	"go.infratographer.com/x/echox"
 	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
 	"go.infratographer.com/x/otelx"
 	"go.infratographer.com/x/versionx"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
 	"go.infratographer.com/permissions-api/pkg/permissions"
 
+	"go.infratographer.com/tenant-api/internal/admission"
+	"go.infratographer.com/tenant-api/internal/checks"
 	"go.infratographer.com/tenant-api/internal/config"
+	"go.infratographer.com/tenant-api/internal/database"
+	"go.infratographer.com/tenant-api/internal/enrichment"
 	ent "go.infratographer.com/tenant-api/internal/ent/generated"
 	"go.infratographer.com/tenant-api/internal/ent/generated/eventhooks"
 	"go.infratographer.com/tenant-api/internal/graphapi"
+	"go.infratographer.com/tenant-api/internal/grpcapi"
+	"go.infratographer.com/tenant-api/internal/kinds"
+	"go.infratographer.com/tenant-api/internal/limits"
+	"go.infratographer.com/tenant-api/internal/metrics"
+	"go.infratographer.com/tenant-api/internal/pubsub"
+	"go.infratographer.com/tenant-api/internal/restapi"
+	"go.infratographer.com/tenant-api/internal/tenantcache"
+	"go.infratographer.com/tenant-api/internal/validation/hooks"
+	"go.infratographer.com/tenant-api/internal/webhooks"
 )
 
 // APIDefaultListen defines the default listening address for the tenant-api.
@@ -38,7 +56,7 @@ var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Start Tenant API",
 	Run: func(cmd *cobra.Command, args []string) {
-		serve(cmd.Context())
+		serve(cmd.Context(), cmd)
 	},
 }
 
@@ -48,16 +66,43 @@ func init() {
 	echox.MustViperFlags(viper.GetViper(), serveCmd.Flags(), APIDefaultListen)
 	echojwtx.MustViperFlags(viper.GetViper(), serveCmd.Flags())
 	events.MustViperFlagsForPublisher(viper.GetViper(), serveCmd.Flags(), appName)
+	pubsub.MustViperFlags(viper.GetViper(), serveCmd.Flags())
+	enrichment.MustViperFlags(viper.GetViper(), serveCmd.Flags())
+	events.MustViperFlagsForSubscriber(viper.GetViper(), serveCmd.Flags())
 	permissions.MustViperFlags(viper.GetViper(), serveCmd.Flags())
+	checks.MustViperFlags(viper.GetViper(), serveCmd.Flags())
+	metrics.MustViperFlags(viper.GetViper(), serveCmd.Flags())
+	graphapi.MustViperFlags(viper.GetViper(), serveCmd.Flags())
+	grpcapi.MustViperFlags(viper.GetViper(), serveCmd.Flags())
+	tenantcache.MustViperFlags(viper.GetViper(), serveCmd.Flags())
+	webhooks.MustViperFlags(viper.GetViper(), serveCmd.Flags())
+	admission.MustViperFlags(viper.GetViper(), serveCmd.Flags())
 
 	// only available as a CLI arg because it shouldn't be something that could accidentially end up in a config file or env var
-	serveCmd.Flags().BoolVar(&serveDevMode, "dev", false, "dev mode: enables playground, disables all auth checks, sets CORS to allow all, pretty logging, etc.")
+	serveCmd.Flags().BoolVar(&serveDevMode, "dev", false, "dev mode: enables playground, disables all auth checks, sets CORS to allow all, pretty logging, uses sqlite unless a db driver is set, writes events to stdout unless a publisher url is set, etc.")
 	serveCmd.Flags().BoolVar(&enablePlayground, "playground", false, "enable the graph playground")
 }
 
-func serve(ctx context.Context) {
+func serve(ctx context.Context, cmd *cobra.Command) {
 	if serveDevMode {
 		enablePlayground = true
+
+		if !settingSet(cmd, "db-driver", "db.driver") {
+			config.AppConfig.DB.Driver = database.DriverSQLite
+		}
+
+		// changes are written to stdout rather than needing a NATS server,
+		// read them with tenant events tail
+		if !settingSet(cmd, "events-publisher-url", "events.publisher.url") {
+			config.AppConfig.Events.Publisher.URL = pubsub.StdoutURL
+		}
+
+		// there's nothing to subscribe to without a NATS server, so gRPC
+		// watches are unavailable
+		if !settingSet(cmd, "events-subscriber-url", "events.subscriber.url") {
+			config.AppConfig.Events.Subscriber.URL = ""
+		}
+
 		config.AppConfig.Logging.Debug = true
 		config.AppConfig.Logging.Pretty = true
 		config.AppConfig.Server.WithMiddleware(middleware.CORS())
@@ -65,26 +110,35 @@ func serve(ctx context.Context) {
 		viper.Set("oidc.enabled", false)
 	}
 
-	publisher, err := events.NewPublisher(config.AppConfig.Events.Publisher)
-	if err != nil {
-		logger.Fatal("unable to initialize event publisher", zap.Error(err))
-	}
+	publisher := newEventsPublisher()
 
-	err = otelx.InitTracer(config.AppConfig.Tracing, appName, logger)
+	err := otelx.InitTracer(config.AppConfig.Tracing, appName, logger)
 	if err != nil {
 		logger.Fatal("unable to initialize tracing system", zap.Error(err))
 	}
 
-	db, err := crdbx.NewDB(config.AppConfig.CRDB, config.AppConfig.Tracing.Enabled)
-	if err != nil {
-		logger.Fatal("unable to initialize crdb client", zap.Error(err))
+	db := openDatabase()
+	defer db.Close()
+
+	logger.Infow("using database", "driver", db.Driver, "reads", config.AppConfig.DB.Reads.Mode)
+
+	if err := metrics.RegisterDBStats(db.DB, appName); err != nil {
+		logger.Fatal("failed to register database metrics", zap.Error(err))
 	}
 
-	defer db.Close()
+	if db.Reads != nil {
+		if err := metrics.RegisterDBStats(db.Reads, appName+"-reads"); err != nil {
+			logger.Fatal("failed to register read pool metrics", zap.Error(err))
+		}
+	}
 
-	entDB := entsql.OpenDB(dialect.Postgres, db)
+	entDB := db.EntDriver()
 
-	cOpts := []ent.Option{ent.Driver(entDB), ent.EventsPublisher(publisher)}
+	if config.AppConfig.Events.Enrichment.Enabled {
+		publisher = enrichment.NewPublisher(publisher)
+	}
+
+	cOpts := []ent.Option{ent.Driver(entDB), ent.EventsPublisher(metrics.InstrumentPublisher(publisher))}
 
 	if config.AppConfig.Logging.Debug {
 		cOpts = append(cOpts,
@@ -96,15 +150,108 @@ func serve(ctx context.Context) {
 	client := ent.NewClient(cOpts...)
 	defer client.Close()
 
+	client.Use(metrics.MutationHook)
+	client.Use(hooks.TenantPolicy)
+	limiter := useLimits(client)
+	useKinds(client)
+	useAdmission(client)
+	targets := useWebhookTargets(client)
 	eventhooks.EventHooks(client)
 
-	srv, err := echox.NewServer(logger.Desugar(), echox.ConfigFromViper(viper.GetViper()), versionx.BuildDetails())
+	var cache *tenantcache.Cache
+
+	if cfg := config.AppConfig.Cache; cfg.Size > 0 {
+		cache = newTenantCache(ctx, client, cfg)
+	}
+
+	if config.AppConfig.Webhooks.Enabled {
+		startWebhookWorker(ctx, client, targets)
+	}
+
+	if interval := config.AppConfig.Metrics.TenantStatsInterval; interval > 0 {
+		var opts []metrics.TenantStatsOption
+
+		if limiter != nil {
+			opts = append(opts, metrics.WithRootLimits(func(root gidx.PrefixedID) (int, int) {
+				l := limiter.For(root)
+
+				return l.MaxDepth, l.MaxChildren
+			}))
+		}
+
+		go metrics.NewTenantStats(client, interval, logger.Named("metrics"), opts...).Run(ctx)
+	}
+
+	serverConfig := echox.ConfigFromViper(viper.GetViper())
+
+	srv, err := echox.NewServer(logger.Desugar(), serverConfig, versionx.BuildDetails())
 	if err != nil {
 		logger.Fatal("failed to initialize new server", zap.Error(err))
 	}
 
 	var middleware []echo.MiddlewareFunc
 
+	if config.AppConfig.Events.Enrichment.Enabled {
+		middleware = append(middleware, enrichment.Middleware())
+	}
+
+	middleware = append(middleware, authMiddleware(ctx)...)
+
+	handlerOpts := []graphapi.HandlerOption{graphapi.WithHandlerConfig(config.AppConfig.GraphQL)}
+
+	if dir := config.AppConfig.GraphQL.AllowListDir; dir != "" {
+		allowList, err := graphapi.LoadAllowList(dir)
+		if err != nil {
+			logger.Fatal("failed to load graphql allow list", zap.Error(err))
+		}
+
+		logger.Infow("graphql allow list enabled", "dir", dir, "operations", allowList.Len())
+
+		handlerOpts = append(handlerOpts, graphapi.WithAllowList(allowList))
+	}
+
+	var resolverOpts []graphapi.ResolverOption
+
+	if cache != nil {
+		resolverOpts = append(resolverOpts, graphapi.WithCache(cache))
+	}
+
+	r := graphapi.NewResolver(client, logger.Named("resolvers"), resolverOpts...)
+	handler := r.Handler(enablePlayground, middleware, handlerOpts...)
+
+	srv.AddHandler(handler)
+	srv.AddHandler(restapi.NewHandler(client, middleware))
+
+	addReadinessChecks(srv, db.DB)
+
+	if listen := config.AppConfig.GRPC.Listen; listen != "" {
+		grpcSrv := newGRPCServer(client, middleware, cache)
+		defer stopGRPCServer(grpcSrv, serverConfig.ShutdownGracePeriod)
+
+		lis, err := net.Listen("tcp", listen)
+		if err != nil {
+			logger.Fatal("failed to listen for grpc", zap.Error(err))
+		}
+
+		go func() {
+			logger.Infow("starting grpc server", "address", listen)
+
+			if err := grpcSrv.Serve(lis); err != nil {
+				logger.Fatal("failed to run grpc server", zap.Error(err))
+			}
+		}()
+	}
+
+	if err := srv.Run(); err != nil {
+		logger.Fatal("failed to run server", zap.Error(err))
+	}
+}
+
+// authMiddleware returns the middleware requests are authenticated and
+// authorized with, it's also run against the authorization of commands
+func authMiddleware(ctx context.Context) []echo.MiddlewareFunc {
+	var middleware []echo.MiddlewareFunc
+
 	if authConfig := config.AppConfig.OIDC; authConfig.Issuer != "" {
 		auth, err := echojwtx.NewAuth(ctx, authConfig, echojwtx.WithJWTConfig(echojwt.Config{
 			Skipper: echox.SkipDefaultEndpoints,
@@ -124,17 +271,265 @@ func serve(ctx context.Context) {
 		logger.Fatal("failed to initialize permissions", zap.Error(err))
 	}
 
-	middleware = append(middleware, perms.Middleware())
+	return append(middleware, perms.Middleware(), metrics.PermissionsMiddleware())
+}
 
-	r := graphapi.NewResolver(client, logger.Named("resolvers"))
-	handler := r.Handler(enablePlayground, middleware)
+// useAdmission reviews the tenant changes made with the client with the
+// configured admission hooks
+func useAdmission(client *ent.Client) {
+	cfg := config.AppConfig.Admission
+	if len(cfg.Hooks) == 0 {
+		return
+	}
 
-	srv.AddHandler(handler)
+	controller, err := admission.NewController(cfg, admission.WithLogger(logger.Named("admission")))
+	if err != nil {
+		logger.Fatalw("failed to initialize admission hooks", "error", err)
+	}
 
-	// TODO: we should have a database check
-	// srv.AddReadinessCheck("database", r.DatabaseCheck)
+	client.Use(controller.Hook)
 
-	if err := srv.Run(); err != nil {
-		logger.Fatal("failed to run server", zap.Error(err))
+	logger.Infow("admission hooks enabled", "hooks", len(cfg.Hooks))
+}
+
+// useLimits enforces the configured tenant hierarchy limits on the tenants
+// created with the client, it returns nil when no limits are set
+func useLimits(client *ent.Client) *limits.Limiter {
+	limiter := newLimiter()
+	if limiter == nil {
+		return nil
+	}
+
+	client.Use(limiter.Hook)
+
+	cfg := config.AppConfig.Limits
+
+	logger.Infow("tenant limits enabled", "max_depth", cfg.MaxDepth, "max_children", cfg.MaxChildren, "overrides", len(cfg.Overrides))
+
+	return limiter
+}
+
+// newLimiter returns the configured tenant hierarchy limits, or nil when no
+// limit is set
+func newLimiter() *limits.Limiter {
+	cfg := config.AppConfig.Limits
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	limiter, err := limits.NewLimiter(cfg)
+	if err != nil {
+		logger.Fatalw("failed to initialize tenant limits", "error", err)
+	}
+
+	return limiter
+}
+
+// useKinds enforces the configured kind graph on the tenants created with the
+// client
+func useKinds(client *ent.Client) {
+	graph := newKindGraph()
+	if graph == nil {
+		return
+	}
+
+	client.Use(graph.Hook)
+
+	logger.Infow("tenant kinds enabled", "rules", len(config.AppConfig.Kinds.Rules))
+}
+
+// newKindGraph returns the configured kind graph, or nil when there are no
+// rules
+func newKindGraph() *kinds.Graph {
+	cfg := config.AppConfig.Kinds
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	graph, err := kinds.NewGraph(cfg)
+	if err != nil {
+		logger.Fatalw("failed to initialize tenant kinds", "error", err)
+	}
+
+	return graph
+}
+
+// useWebhookTargets rejects webhook subscriptions saved with the client whose
+// url doesn't resolve to a public address or an allowed network, it returns
+// the targets deliveries are checked against when they're dialed
+func useWebhookTargets(client *ent.Client) *webhooks.Targets {
+	targets, err := webhooks.NewTargets(config.AppConfig.Webhooks.AllowedNetworks)
+	if err != nil {
+		logger.Fatalw("failed to initialize webhook targets", "error", err)
+	}
+
+	client.Use(targets.Hook)
+
+	return targets
+}
+
+func newGRPCServer(client *ent.Client, middleware []echo.MiddlewareFunc, cache *tenantcache.Cache) *grpc.Server {
+	opts := []grpcapi.Option{grpcapi.WithLogger(logger.Named("grpc"))}
+
+	if cache != nil {
+		opts = append(opts, grpcapi.WithCache(cache))
+	}
+
+	subCfg := config.AppConfig.Events.Subscriber
+
+	switch {
+	case subCfg.URL == "":
+		// watches are unavailable without a subscriber
+	case !config.AppConfig.Events.Publisher.PublishesChangeMessages():
+		logger.Warnw("grpc watches are unavailable, the events publisher doesn't publish change messages",
+			"encoding", config.AppConfig.Events.Publisher.Encoding)
+	default:
+		// watch streams only changes published after the call starts, and
+		// watchers may be connected to any replica, so each needs every change
+		// rather than sharing them with a queue group
+		subCfg.QueueGroup = ""
+
+		subscriber, err := events.NewSubscriberWithLogger(subCfg, logger.Named("grpc"), nats.DeliverNew())
+		if err != nil {
+			logger.Fatal("unable to initialize event subscriber", zap.Error(err))
+		}
+
+		opts = append(opts, grpcapi.WithSubscriber(subscriber))
+	}
+
+	return grpcapi.NewServer(client, opts...).GRPCServer(middleware)
+}
+
+// newTenantCache returns an in-memory tenant cache, which is invalidated by
+// change events when a subscriber is configured
+func newTenantCache(ctx context.Context, client *ent.Client, cfg tenantcache.Config) *tenantcache.Cache {
+	backend, err := tenantcache.NewLRU(cfg.Size)
+	if err != nil {
+		logger.Fatal("unable to initialize tenant cache", zap.Error(err))
+	}
+
+	cache := tenantcache.New(client, backend, tenantcache.WithTTL(cfg.TTL), tenantcache.WithLogger(logger.Named("cache")))
+
+	subCfg := config.AppConfig.Events.Subscriber
+	if subCfg.URL == "" {
+		logger.Warnw("tenant cache isn't invalidated by change events, other replicas' changes are visible once entries expire", "ttl", cfg.TTL)
+
+		return cache
+	}
+
+	// every replica holds its own cache, so each needs every change rather
+	// than sharing them with a queue group
+	subCfg.QueueGroup = ""
+
+	subscriber, err := events.NewSubscriberWithLogger(subCfg, logger.Named("cache"), nats.DeliverNew())
+	if err != nil {
+		logger.Fatal("unable to initialize tenant cache subscriber", zap.Error(err))
+	}
+
+	go func() {
+		if err := cache.Run(ctx, subscriber); err != nil {
+			logger.Errorw("tenant cache invalidation stopped", "error", err)
+		}
+	}()
+
+	return cache
+}
+
+// startWebhookWorker delivers tenant changes to webhook subscriptions, the
+// replicas share change messages through the subscriber's queue group so each
+// change is recorded once
+func startWebhookWorker(ctx context.Context, client *ent.Client, targets *webhooks.Targets) {
+	subCfg := config.AppConfig.Events.Subscriber
+	if subCfg.URL == "" {
+		logger.Fatal("webhooks require an events subscriber")
+	}
+
+	subscriber, err := events.NewSubscriberWithLogger(subCfg, logger.Named("webhooks"))
+	if err != nil {
+		logger.Fatal("unable to initialize webhooks subscriber", zap.Error(err))
+	}
+
+	worker := webhooks.NewWorker(client, config.AppConfig.Webhooks,
+		webhooks.WithLogger(logger.Named("webhooks")),
+		webhooks.WithTargets(targets),
+	)
+
+	go func() {
+		if err := worker.Run(ctx, subscriber); err != nil {
+			logger.Errorw("webhook delivery stopped", "error", err)
+		}
+	}()
+}
+
+// stopGRPCServer waits for in flight calls to finish, watch streams don't end
+// on their own so the server is stopped once the grace period is over
+func stopGRPCServer(srv *grpc.Server, gracePeriod time.Duration) {
+	stopped := make(chan struct{})
+
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(gracePeriod):
+		srv.Stop()
+	}
+}
+
+// settingSet reports whether the setting was chosen by its flag, env var or
+// config file rather than left as the default
+func settingSet(cmd *cobra.Command, flag, key string) bool {
+	if f := cmd.Flag(flag); f != nil && f.Changed {
+		return true
+	}
+
+	env := "TENANTAPI_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+
+	if _, ok := os.LookupEnv(env); ok {
+		return true
+	}
+
+	return viper.InConfig(key)
+}
+
+func addReadinessChecks(srv *echox.Server, db *sql.DB) {
+	cfg := config.AppConfig.Checks
+
+	if cfg.Database.Enabled {
+		srv.AddReadinessCheck("database", checks.Database(db, cfg.Database))
+	}
+
+	if cfg.Events.Enabled {
+		// file publishers have no server to check
+		if pubCfg := config.AppConfig.Events.Publisher; !pubsub.IsFileURL(pubCfg.URL) {
+			eventsChecker := checks.NewEventsChecker(pubCfg.PublisherConfig, cfg.Events)
+
+			srv.AddReadinessCheck("events", eventsChecker.Check)
+		}
+
+		// best effort backends don't fail changes, so they don't fail readiness either
+		pubCfgs := pubsub.PublisherConfigs(config.AppConfig.Events.Publisher, config.AppConfig.Events.Publishers)
+
+		for _, pubCfg := range pubCfgs[1:] {
+			if pubCfg.Policy == pubsub.PolicyBestEffort || pubsub.IsFileURL(pubCfg.URL) {
+				continue
+			}
+
+			backendChecker := checks.NewEventsChecker(pubCfg.PublisherConfig, cfg.Events)
+
+			srv.AddReadinessCheck("events-"+pubCfg.Name, backendChecker.Check)
+		}
+	}
+
+	// permission checks are skipped entirely when no permissions-api is configured
+	if cfg.Permissions.Enabled && config.AppConfig.Permissions.URL != "" {
+		permissionsCheck, err := checks.Permissions(config.AppConfig.Permissions.URL, cfg.Permissions)
+		if err != nil {
+			logger.Fatal("failed to initialize permissions readiness check", zap.Error(err))
+		}
+
+		srv.AddReadinessCheck("permissions", permissionsCheck)
 	}
 }
@@ -1,17 +1,15 @@
 package cmd
 
 import (
-	"entgo.io/ent/dialect"
-	entsql "entgo.io/ent/dialect/sql"
 	"github.com/spf13/cobra"
-	"go.infratographer.com/x/crdbx"
-	"go.infratographer.com/x/events"
 	"go.infratographer.com/x/otelx"
 	"go.uber.org/zap"
 
 	"go.infratographer.com/tenant-api/internal/config"
+	"go.infratographer.com/tenant-api/internal/enrichment"
 	ent "go.infratographer.com/tenant-api/internal/ent/generated"
 	"go.infratographer.com/tenant-api/internal/ent/generated/eventhooks"
+	"go.infratographer.com/tenant-api/internal/validation/hooks"
 )
 
 var tenantCmd = &cobra.Command{
@@ -20,22 +18,20 @@ var tenantCmd = &cobra.Command{
 }
 
 func initializeGraphClient() (*ent.Client, func()) {
-	publisher, err := events.NewPublisher(config.AppConfig.Events.Publisher)
-	if err != nil {
-		logger.Fatal("unable to initialize event publisher", zap.Error(err))
-	}
+	publisher := newEventsPublisher()
 
-	err = otelx.InitTracer(config.AppConfig.Tracing, appName, logger)
+	err := otelx.InitTracer(config.AppConfig.Tracing, appName, logger)
 	if err != nil {
 		logger.Fatal("unable to initialize tracing system", zap.Error(err))
 	}
 
-	db, err := crdbx.NewDB(config.AppConfig.CRDB, config.AppConfig.Tracing.Enabled)
-	if err != nil {
-		logger.Fatal("unable to initialize crdb client", zap.Error(err))
-	}
+	db := openDatabase()
+
+	entDB := db.EntDriver()
 
-	entDB := entsql.OpenDB(dialect.Postgres, db)
+	if config.AppConfig.Events.Enrichment.Enabled {
+		publisher = enrichment.NewPublisher(publisher)
+	}
 
 	cOpts := []ent.Option{ent.Driver(entDB), ent.EventsPublisher(publisher)}
 
@@ -48,6 +44,9 @@ func initializeGraphClient() (*ent.Client, func()) {
 
 	client := ent.NewClient(cOpts...)
 
+	client.Use(hooks.TenantPolicy)
+	useLimits(client)
+	useKinds(client)
 	eventhooks.EventHooks(client)
 
 	return client, func() { db.Close(); client.Close() }
@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -11,7 +12,11 @@ import (
 
 	"go.infratographer.com/permissions-api/pkg/permissions"
 
+	"go.infratographer.com/tenant-api/internal/enrichment"
 	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	enttenant "go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/pubsub"
+	"go.infratographer.com/tenant-api/internal/tenants"
 )
 
 var tenantCreateCmd = &cobra.Command{
@@ -25,10 +30,13 @@ func init() {
 	tenantCmd.AddCommand(tenantCreateCmd)
 
 	events.MustViperFlagsForPublisher(viper.GetViper(), tenantCreateCmd.Flags(), appName)
+	pubsub.MustViperFlags(viper.GetViper(), tenantCreateCmd.Flags())
+	enrichment.MustViperFlags(viper.GetViper(), tenantCreateCmd.Flags())
 	permissions.MustViperFlags(viper.GetViper(), tenantCreateCmd.Flags())
 
 	tenantCreateCmd.Flags().String("description", "", "description of tenant")
 	tenantCreateCmd.Flags().String("parent", "", "parent tenant id")
+	tenantCreateCmd.Flags().String("kind", "", "kind of tenant, such as ORGANIZATION or PROJECT")
 }
 
 func createTenant(cmd *cobra.Command, args []string) {
@@ -64,13 +72,24 @@ func createTenant(cmd *cobra.Command, args []string) {
 		tenantParentID = &parentID
 	}
 
-	tenant, err := client.Tenant.Create().SetInput(
-		ent.CreateTenantInput{
-			Name:        tenantName,
-			Description: tenantDescription,
-			ParentID:    tenantParentID,
-		},
-	).Save(cmd.Context())
+	kind, err := cmd.Flags().GetString("kind")
+	if err != nil {
+		logger.Fatalw("failed to get kind flag value", "error", err)
+	}
+
+	var tenantKind *enttenant.Kind
+
+	if kind != "" {
+		k := enttenant.Kind(strings.ToUpper(kind))
+		tenantKind = &k
+	}
+
+	tenant, err := tenants.Create(cmd.Context(), client, ent.CreateTenantInput{
+		Name:        tenantName,
+		Description: tenantDescription,
+		Kind:        tenantKind,
+		ParentID:    tenantParentID,
+	})
 	if err != nil {
 		logger.Fatalw("failed to create tenant", "error", err)
 	}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.infratographer.com/x/events"
+
+	"go.infratographer.com/tenant-api/internal/config"
+	"go.infratographer.com/tenant-api/internal/pubsub"
+)
+
+var tenantEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Tenant events",
+}
+
+var tenantEventsTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Print tenant events written by a file or stdout publisher",
+	Long: `Print the tenant events written by a stdout:// or file:// events publisher,
+such as by serve --dev, following events as they're written.
+
+Events are read from --file, the file of a file:// events publisher when it
+isn't set, or stdin when it's -, which reads events piped from serve --dev.`,
+	Run: tailEvents,
+}
+
+func init() {
+	tenantCmd.AddCommand(tenantEventsCmd)
+	tenantEventsCmd.AddCommand(tenantEventsTailCmd)
+
+	tenantEventsTailCmd.Flags().String("file", "", "file to read events from, - for stdin, defaults to the file of a file:// events publisher")
+	tenantEventsTailCmd.Flags().BoolP("follow", "f", true, "keep printing events as they're written")
+	tenantEventsTailCmd.Flags().Bool("json", false, "print events as they were written")
+}
+
+func tailEvents(cmd *cobra.Command, _ []string) {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	path, _ := cmd.Flags().GetString("file")
+	follow, _ := cmd.Flags().GetBool("follow")
+	raw, _ := cmd.Flags().GetBool("json")
+
+	if path == "" {
+		url := config.AppConfig.Events.Publisher.URL
+		if !strings.HasPrefix(url, pubsub.FileScheme) {
+			logger.Fatalw("--file is required when events aren't published to a file", "url", url)
+		}
+
+		path = strings.TrimPrefix(url, pubsub.FileScheme)
+	}
+
+	var r io.Reader = os.Stdin
+
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			logger.Fatalw("failed to open events file", "error", err)
+		}
+
+		defer f.Close()
+
+		r = f
+	}
+
+	out := cmd.OutOrStdout()
+
+	err := pubsub.ReadChanges(ctx, r, follow, func(line []byte, change events.ChangeMessage) error {
+		if raw {
+			_, err := fmt.Fprintf(out, "%s\n", line)
+
+			return err
+		}
+
+		return printChange(out, change)
+	})
+	if err != nil {
+		logger.Fatalw("failed to read events", "error", err)
+	}
+}
+
+// printChange prints a summary of the change followed by its field changes
+func printChange(w io.Writer, change events.ChangeMessage) error {
+	_, err := fmt.Fprintf(w, "%s %s %s actor=%s\n",
+		change.Timestamp.Format(time.RFC3339),
+		change.EventType,
+		change.SubjectID,
+		change.ActorID,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, fc := range change.FieldChanges {
+		if _, err := fmt.Fprintf(w, "  %s: %q -> %q\n", fc.Field, fc.PreviousValue, fc.CurrentValue); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/permissions-api/pkg/permissions"
+
+	"go.infratographer.com/tenant-api/internal/enrichment"
+	"go.infratographer.com/tenant-api/internal/fsck"
+	"go.infratographer.com/tenant-api/internal/pubsub"
+)
+
+// fsckIssuesExitCode is returned when the check finds issues that were not fixed.
+const fsckIssuesExitCode = 2
+
+var tenantFsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Check the tenant hierarchy for integrity problems",
+	Long: `Check the tenant hierarchy for orphaned tenants, unexpected roots, parent cycles,
+duplicate sibling names and invalid IDs.
+
+Fixes which move tenants are checked against the tenant kind graph and
+hierarchy limits, and nothing is fixed when any move would break either.
+
+A JSON report is written to stdout. The command exits with status 2 when issues
+remain after any requested fixes have been applied, making it suitable to run
+as a cron job.`,
+	Run: fsckTenants,
+}
+
+func init() {
+	tenantCmd.AddCommand(tenantFsckCmd)
+
+	events.MustViperFlagsForPublisher(viper.GetViper(), tenantFsckCmd.Flags(), appName)
+	pubsub.MustViperFlags(viper.GetViper(), tenantFsckCmd.Flags())
+	enrichment.MustViperFlags(viper.GetViper(), tenantFsckCmd.Flags())
+	permissions.MustViperFlags(viper.GetViper(), tenantFsckCmd.Flags())
+
+	tenantFsckCmd.Flags().StringSlice("expected-root", nil, "tenant ids which are expected to be roots, any other root is reported")
+	tenantFsckCmd.Flags().StringSlice("fix", nil, "issue kinds to fix: orphan, unexpected_root, cycle, duplicate_name")
+	tenantFsckCmd.Flags().String("reparent-to", "", "tenant id fixed orphans, unexpected roots and cycle members are moved under, defaults to making them roots")
+}
+
+func fsckTenants(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Context()
+
+	client, closeFn := initializeGraphClient()
+	defer closeFn()
+
+	opts := fsck.Options{}
+
+	expectedRoots, err := cmd.Flags().GetStringSlice("expected-root")
+	if err != nil {
+		logger.Fatalw("failed to get expected-root flag value", "error", err)
+	}
+
+	for _, root := range expectedRoots {
+		rootID, err := gidx.Parse(root)
+		if err != nil {
+			logger.Fatalw("failed to parse expected root ID", "error", err)
+		}
+
+		opts.ExpectedRoots = append(opts.ExpectedRoots, rootID)
+	}
+
+	// moves are made with SQL, so they're checked against the kind graph and
+	// limits here rather than by the client's hooks
+	fixOpts := fsck.FixOptions{Limiter: newLimiter(), KindGraph: newKindGraph()}
+
+	fixKinds, err := cmd.Flags().GetStringSlice("fix")
+	if err != nil {
+		logger.Fatalw("failed to get fix flag value", "error", err)
+	}
+
+	for _, kind := range fixKinds {
+		issueKind, err := fsck.ParseIssueKind(kind)
+		if err != nil {
+			logger.Fatalw("failed to parse fix flag value", "error", err)
+		}
+
+		fixOpts.Kinds = append(fixOpts.Kinds, issueKind)
+	}
+
+	if reparentTo, _ := cmd.Flags().GetString("reparent-to"); reparentTo != "" {
+		fixOpts.ReparentTo, err = gidx.Parse(reparentTo)
+		if err != nil {
+			logger.Fatalw("failed to parse reparent-to ID", "error", err)
+		}
+	}
+
+	tenants, err := client.Tenant.Query().All(ctx)
+	if err != nil {
+		logger.Fatalw("failed to query all tenants", "error", err)
+	}
+
+	report := fsck.Check(tenants, opts)
+
+	if len(fixOpts.Kinds) != 0 && len(report.Issues) != 0 {
+		tx, err := client.Tx(ctx)
+		if err != nil {
+			logger.Fatalw("failed to start transaction", "error", err)
+		}
+
+		changes, err := fsck.Fix(ctx, tx.Client(), report, fixOpts)
+		if err != nil {
+			_ = tx.Rollback()
+
+			logger.Fatalw("failed to fix issues", "error", err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			logger.Fatalw("failed to commit fixes", "error", err)
+		}
+
+		// moves bypass the ent hooks, so publish them for downstream services ourselves
+		for _, change := range changes {
+			if change.Published {
+				continue
+			}
+
+			msg := events.ChangeMessage{
+				EventType:     string(events.UpdateChangeType),
+				SubjectID:     change.TenantID,
+				SubjectFields: change.SubjectFields,
+				Timestamp:     time.Now().UTC(),
+				FieldChanges: []events.FieldChange{{
+					Field:         change.Field,
+					PreviousValue: change.PreviousValue,
+					CurrentValue:  change.CurrentValue,
+				}},
+			}
+
+			if change.ParentID != gidx.NullPrefixedID {
+				msg.AdditionalSubjectIDs = []gidx.PrefixedID{change.ParentID}
+			}
+
+			if err := client.EventsPublisher.PublishChange(ctx, "tenant", msg); err != nil {
+				logger.Errorw("failed to publish change", "error", err, "tenant_id", change.TenantID)
+			}
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(report); err != nil {
+		logger.Fatalw("failed to encode payload", "error", err)
+	}
+
+	if report.Unresolved() != 0 {
+		closeFn()
+		os.Exit(fsckIssuesExitCode)
+	}
+}
@@ -11,8 +11,10 @@ import (
 
 	"go.infratographer.com/permissions-api/pkg/permissions"
 
+	"go.infratographer.com/tenant-api/internal/enrichment"
 	ent "go.infratographer.com/tenant-api/internal/ent/generated"
 	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/pubsub"
 )
 
 var tenantList = &cobra.Command{
@@ -25,6 +27,8 @@ func init() {
 	tenantCmd.AddCommand(tenantList)
 
 	events.MustViperFlagsForPublisher(viper.GetViper(), tenantList.Flags(), appName)
+	pubsub.MustViperFlags(viper.GetViper(), tenantList.Flags())
+	enrichment.MustViperFlags(viper.GetViper(), tenantList.Flags())
 	permissions.MustViperFlags(viper.GetViper(), tenantList.Flags())
 
 	tenantList.Flags().Bool("all", false, "query all")
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/events"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/permissions-api/pkg/permissions"
+
+	"go.infratographer.com/tenant-api/internal/admission"
+	"go.infratographer.com/tenant-api/internal/commands"
+	"go.infratographer.com/tenant-api/internal/config"
+	"go.infratographer.com/tenant-api/internal/enrichment"
+	"go.infratographer.com/tenant-api/internal/pubsub"
+)
+
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Execute tenant commands consumed from the events subscriber",
+	Long: `Execute tenant create, update and delete commands consumed from the events
+subscriber, and publish the result of each command.
+
+Commands are authorized with the bearer token they carry, with the same
+authentication and permission checks as the APIs, so OIDC authentication is
+required unless --worker-allow-unauthenticated is set. Replicas share commands
+through the subscriber's queue group.
+
+Results are published with the events publisher, which has to be NATS.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		runWorker(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workerCmd)
+
+	echojwtx.MustViperFlags(viper.GetViper(), workerCmd.Flags())
+	events.MustViperFlagsForPublisher(viper.GetViper(), workerCmd.Flags(), appName)
+	pubsub.MustViperFlags(viper.GetViper(), workerCmd.Flags())
+	enrichment.MustViperFlags(viper.GetViper(), workerCmd.Flags())
+	events.MustViperFlagsForSubscriber(viper.GetViper(), workerCmd.Flags())
+	permissions.MustViperFlags(viper.GetViper(), workerCmd.Flags())
+	commands.MustViperFlags(viper.GetViper(), workerCmd.Flags())
+	admission.MustViperFlags(viper.GetViper(), workerCmd.Flags())
+}
+
+func runWorker(ctx context.Context) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if config.AppConfig.OIDC.Issuer == "" {
+		if !config.AppConfig.Worker.AllowUnauthenticated {
+			logger.Fatal("the worker requires oidc authentication, set --worker-allow-unauthenticated to execute commands without it")
+		}
+
+		logger.Warn("OIDC AUTHENTICATION IS DISABLED, every tenant command is executed without authentication by anyone who can publish to the command topic")
+	}
+
+	// results are events rather than changes, they're published with the
+	// events publisher whatever the change encoding, which doesn't support
+	// file publishers
+	if pubURL := config.AppConfig.Events.Publisher.URL; pubsub.IsFileURL(pubURL) {
+		logger.Fatalw("the worker can't publish command results to a file, the events publisher has to be NATS", "url", pubURL)
+	}
+
+	client, closeFn := initializeGraphClient()
+	defer closeFn()
+
+	useAdmission(client)
+
+	resultPublisher, err := events.NewPublisherWithLogger(config.AppConfig.Events.Publisher.PublisherConfig, logger.Named("worker"))
+	if err != nil {
+		logger.Fatal("unable to initialize result publisher", zap.Error(err))
+	}
+
+	defer resultPublisher.Close()
+
+	subscriber, err := events.NewSubscriberWithLogger(config.AppConfig.Events.Subscriber, logger.Named("worker"))
+	if err != nil {
+		logger.Fatal("unable to initialize command subscriber", zap.Error(err))
+	}
+
+	defer subscriber.Close()
+
+	worker := commands.NewWorker(client, resultPublisher, config.AppConfig.Worker,
+		commands.WithMiddleware(authMiddleware(ctx)),
+		commands.WithLogger(logger.Named("worker")),
+	)
+
+	logger.Infow("executing tenant commands", "topic", config.AppConfig.Worker.Topic, "result_topic", config.AppConfig.Worker.ResultTopic)
+
+	if err := worker.Run(ctx, subscriber); err != nil {
+		logger.Fatal("tenant command worker stopped", zap.Error(err))
+	}
+}
@@ -9,18 +9,26 @@ import (
 
 	_ "ariga.io/atlas/sql/postgres"
 	_ "ariga.io/atlas/sql/postgres/postgrescheck"
+	_ "ariga.io/atlas/sql/sqlite"
 	"ariga.io/atlas/sql/sqltool"
 	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql/schema"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 
 	"go.infratographer.com/tenant-api/internal/ent/generated/migrate"
 )
 
 func main() {
 	ctx := context.Background()
+	// Migrations for the sqlite storage backend are kept in their own directory,
+	// set ATLAS_DIALECT=sqlite and an ATLAS_DB_URI such as sqlite://dev?mode=memory&_fk=1 to create them.
+	migrationsDir, entDialect := "db/migrations", dialect.Postgres
+	if os.Getenv("ATLAS_DIALECT") == "sqlite" {
+		migrationsDir, entDialect = "db/migrations-sqlite", dialect.SQLite
+	}
 	// Create a local migration directory able to understand Atlas migration file format for replay.
-	dir, err := sqltool.NewGooseDir("db/migrations")
+	dir, err := sqltool.NewGooseDir(migrationsDir)
 	if err != nil {
 		log.Fatalf("failed creating atlas migration directory: %v", err)
 	}
@@ -28,7 +36,7 @@ func main() {
 	opts := []schema.MigrateOption{
 		schema.WithDir(dir),                         // provide migration directory
 		schema.WithMigrationMode(schema.ModeReplay), // provide migration mode
-		schema.WithDialect(dialect.Postgres),        // Ent dialect to use
+		schema.WithDialect(entDialect),              // Ent dialect to use
 	}
 	if len(os.Args) != 2 {
 		log.Fatalln("migration name is required. Use: 'go run -mod=mod db/create_migration.go <name>'")
@@ -0,0 +1,35 @@
+-- +goose Up
+-- create "webhook_deliveries" table
+CREATE TABLE `webhook_deliveries` (`id` text NOT NULL, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `event_type` text NOT NULL, `subject_id` text NOT NULL, `payload` text NOT NULL, `status` text NOT NULL DEFAULT 'PENDING', `attempts` integer NOT NULL DEFAULT 0, `next_attempt_at` datetime NOT NULL, `last_attempt_at` datetime NULL, `response_status` integer NULL, `last_error` text NULL, `subscription_id` text NOT NULL, PRIMARY KEY (`id`), CONSTRAINT `webhook_deliveries_webhook_subscriptions_deliveries` FOREIGN KEY (`subscription_id`) REFERENCES `webhook_subscriptions` (`id`) ON DELETE CASCADE);
+-- create index "webhookdelivery_created_at" to table: "webhook_deliveries"
+CREATE INDEX `webhookdelivery_created_at` ON `webhook_deliveries` (`created_at`);
+-- create index "webhookdelivery_updated_at" to table: "webhook_deliveries"
+CREATE INDEX `webhookdelivery_updated_at` ON `webhook_deliveries` (`updated_at`);
+-- create index "webhookdelivery_status_next_attempt_at" to table: "webhook_deliveries"
+CREATE INDEX `webhookdelivery_status_next_attempt_at` ON `webhook_deliveries` (`status`, `next_attempt_at`);
+-- create "webhook_subscriptions" table
+CREATE TABLE `webhook_subscriptions` (`id` text NOT NULL, `created_at` datetime NOT NULL, `updated_at` datetime NOT NULL, `url` text NOT NULL, `secret` text NOT NULL, `event_types` json NULL, `tenant_id` text NULL, PRIMARY KEY (`id`));
+-- create index "webhooksubscription_created_at" to table: "webhook_subscriptions"
+CREATE INDEX `webhooksubscription_created_at` ON `webhook_subscriptions` (`created_at`);
+-- create index "webhooksubscription_updated_at" to table: "webhook_subscriptions"
+CREATE INDEX `webhooksubscription_updated_at` ON `webhook_subscriptions` (`updated_at`);
+-- create index "webhooksubscription_tenant_id" to table: "webhook_subscriptions"
+CREATE INDEX `webhooksubscription_tenant_id` ON `webhook_subscriptions` (`tenant_id`);
+
+-- +goose Down
+-- reverse: create index "webhooksubscription_tenant_id" to table: "webhook_subscriptions"
+DROP INDEX `webhooksubscription_tenant_id`;
+-- reverse: create index "webhooksubscription_updated_at" to table: "webhook_subscriptions"
+DROP INDEX `webhooksubscription_updated_at`;
+-- reverse: create index "webhooksubscription_created_at" to table: "webhook_subscriptions"
+DROP INDEX `webhooksubscription_created_at`;
+-- reverse: create "webhook_subscriptions" table
+DROP TABLE `webhook_subscriptions`;
+-- reverse: create index "webhookdelivery_status_next_attempt_at" to table: "webhook_deliveries"
+DROP INDEX `webhookdelivery_status_next_attempt_at`;
+-- reverse: create index "webhookdelivery_updated_at" to table: "webhook_deliveries"
+DROP INDEX `webhookdelivery_updated_at`;
+-- reverse: create index "webhookdelivery_created_at" to table: "webhook_deliveries"
+DROP INDEX `webhookdelivery_created_at`;
+-- reverse: create "webhook_deliveries" table
+DROP TABLE `webhook_deliveries`;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- add column "event_sequence" to table: "tenants"
+ALTER TABLE `tenants` ADD COLUMN `event_sequence` integer NOT NULL DEFAULT 0;
+
+-- +goose Down
+-- reverse: add column "event_sequence" to table: "tenants"
+ALTER TABLE `tenants` DROP COLUMN `event_sequence`;
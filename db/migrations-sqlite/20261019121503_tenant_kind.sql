@@ -0,0 +1,7 @@
+-- +goose Up
+-- add column "kind" to table: "tenants"
+ALTER TABLE `tenants` ADD COLUMN `kind` text NOT NULL DEFAULT 'TENANT';
+
+-- +goose Down
+-- reverse: add column "kind" to table: "tenants"
+ALTER TABLE `tenants` DROP COLUMN `kind`;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- add column "event_sequence" to table: "webhook_deliveries"
+ALTER TABLE `webhook_deliveries` ADD COLUMN `event_sequence` integer NULL;
+-- create index "webhookdelivery_subscription_id_subject_id_event_sequence" to table: "webhook_deliveries"
+CREATE UNIQUE INDEX `webhookdelivery_subscription_id_subject_id_event_sequence` ON `webhook_deliveries` (`subscription_id`, `subject_id`, `event_sequence`);
+
+-- +goose Down
+-- reverse: create index "webhookdelivery_subscription_id_subject_id_event_sequence" to table: "webhook_deliveries"
+DROP INDEX `webhookdelivery_subscription_id_subject_id_event_sequence`;
+-- reverse: add column "event_sequence" to table: "webhook_deliveries"
+ALTER TABLE `webhook_deliveries` DROP COLUMN `event_sequence`;
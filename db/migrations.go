@@ -9,3 +9,10 @@ import (
 //
 //go:embed migrations/*.sql
 var Migrations embed.FS
+
+// SQLiteMigrations contain an embedded filesystem with the sql migration
+// files for the sqlite storage backend. Every change to Migrations needs a
+// matching migration here.
+//
+//go:embed migrations-sqlite/*.sql
+var SQLiteMigrations embed.FS
@@ -0,0 +1,59 @@
+-- +goose Up
+-- create "webhook_subscriptions" table
+CREATE TABLE "webhook_subscriptions" (
+  "id" character varying NOT NULL,
+  "created_at" timestamptz NOT NULL,
+  "updated_at" timestamptz NOT NULL,
+  "url" character varying NOT NULL,
+  "secret" character varying NOT NULL,
+  "event_types" jsonb NULL,
+  "tenant_id" character varying NULL,
+  PRIMARY KEY ("id")
+);
+-- create index "webhooksubscription_created_at" to table: "webhook_subscriptions"
+CREATE INDEX "webhooksubscription_created_at" ON "webhook_subscriptions" ("created_at");
+-- create index "webhooksubscription_updated_at" to table: "webhook_subscriptions"
+CREATE INDEX "webhooksubscription_updated_at" ON "webhook_subscriptions" ("updated_at");
+-- create index "webhooksubscription_tenant_id" to table: "webhook_subscriptions"
+CREATE INDEX "webhooksubscription_tenant_id" ON "webhook_subscriptions" ("tenant_id");
+-- create "webhook_deliveries" table
+CREATE TABLE "webhook_deliveries" (
+  "id" character varying NOT NULL,
+  "created_at" timestamptz NOT NULL,
+  "updated_at" timestamptz NOT NULL,
+  "event_type" character varying NOT NULL,
+  "subject_id" character varying NOT NULL,
+  "payload" text NOT NULL,
+  "status" character varying NOT NULL DEFAULT 'PENDING',
+  "attempts" bigint NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL,
+  "last_attempt_at" timestamptz NULL,
+  "response_status" bigint NULL,
+  "last_error" character varying NULL,
+  "subscription_id" character varying NOT NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "webhook_deliveries_webhook_subscriptions_deliveries" FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- create index "webhookdelivery_created_at" to table: "webhook_deliveries"
+CREATE INDEX "webhookdelivery_created_at" ON "webhook_deliveries" ("created_at");
+-- create index "webhookdelivery_updated_at" to table: "webhook_deliveries"
+CREATE INDEX "webhookdelivery_updated_at" ON "webhook_deliveries" ("updated_at");
+-- create index "webhookdelivery_status_next_attempt_at" to table: "webhook_deliveries"
+CREATE INDEX "webhookdelivery_status_next_attempt_at" ON "webhook_deliveries" ("status", "next_attempt_at");
+-- +goose Down
+-- reverse: create index "webhookdelivery_status_next_attempt_at" to table: "webhook_deliveries"
+DROP INDEX "webhookdelivery_status_next_attempt_at";
+-- reverse: create index "webhookdelivery_updated_at" to table: "webhook_deliveries"
+DROP INDEX "webhookdelivery_updated_at";
+-- reverse: create index "webhookdelivery_created_at" to table: "webhook_deliveries"
+DROP INDEX "webhookdelivery_created_at";
+-- reverse: create "webhook_deliveries" table
+DROP TABLE "webhook_deliveries";
+-- reverse: create index "webhooksubscription_tenant_id" to table: "webhook_subscriptions"
+DROP INDEX "webhooksubscription_tenant_id";
+-- reverse: create index "webhooksubscription_updated_at" to table: "webhook_subscriptions"
+DROP INDEX "webhooksubscription_updated_at";
+-- reverse: create index "webhooksubscription_created_at" to table: "webhook_subscriptions"
+DROP INDEX "webhooksubscription_created_at";
+-- reverse: create "webhook_subscriptions" table
+DROP TABLE "webhook_subscriptions";
@@ -0,0 +1,7 @@
+-- +goose Up
+-- modify "tenants" table
+ALTER TABLE "tenants" ADD COLUMN "event_sequence" bigint NOT NULL DEFAULT 0;
+
+-- +goose Down
+-- reverse: modify "tenants" table
+ALTER TABLE "tenants" DROP COLUMN "event_sequence";
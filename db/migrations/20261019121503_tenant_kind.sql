@@ -0,0 +1,7 @@
+-- +goose Up
+-- modify "tenants" table
+ALTER TABLE "tenants" ADD COLUMN "kind" character varying NOT NULL DEFAULT 'TENANT';
+
+-- +goose Down
+-- reverse: modify "tenants" table
+ALTER TABLE "tenants" DROP COLUMN "kind";
@@ -0,0 +1,11 @@
+-- +goose Up
+-- modify "webhook_deliveries" table
+ALTER TABLE "webhook_deliveries" ADD COLUMN "event_sequence" bigint NULL;
+-- create index "webhookdelivery_subscription_id_subject_id_event_sequence" to table: "webhook_deliveries"
+CREATE UNIQUE INDEX "webhookdelivery_subscription_id_subject_id_event_sequence" ON "webhook_deliveries" ("subscription_id", "subject_id", "event_sequence");
+
+-- +goose Down
+-- reverse: create index "webhookdelivery_subscription_id_subject_id_event_sequence" to table: "webhook_deliveries"
+DROP INDEX "webhookdelivery_subscription_id_subject_id_event_sequence";
+-- reverse: modify "webhook_deliveries" table
+ALTER TABLE "webhook_deliveries" DROP COLUMN "event_sequence";
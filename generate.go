@@ -5,3 +5,4 @@ package main
 //go:generate go run -mod=mod github.com/99designs/gqlgen
 //go:generate go run -mod=mod ./gen_schema.go
 //go:generate go run -mod=mod github.com/Yamashou/gqlgenc
+//go:generate protoc -I proto --go_out=. --go_opt=module=go.infratographer.com/tenant-api --go-grpc_out=. --go-grpc_opt=module=go.infratographer.com/tenant-api proto/infratographer/tenant/v1/tenant.proto
@@ -0,0 +1,425 @@
+// Package admission reviews tenant changes with external admission hooks
+// before they're made, so policies such as naming and placement rules can be
+// enforced without changing tenant-api.
+//
+// The proposed create, update or delete is POSTed to each hook as a Review,
+// with the tenant's parent chain and the actor making the change, and the
+// hook responds with whether it's allowed and why not. A change denied by any
+// hook is rejected. When a review fails the hook's failure policy decides
+// whether the change is made.
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"entgo.io/ent"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/gidx"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/tenant-api/internal/enrichment"
+	generated "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/metrics"
+)
+
+const (
+	// OperationCreate reviews the creation of a tenant
+	OperationCreate = "create"
+	// OperationUpdate reviews the update of a tenant
+	OperationUpdate = "update"
+	// OperationDelete reviews the deletion of a tenant
+	OperationDelete = "delete"
+
+	userAgent       = "tenant-api-admission"
+	maxResponseBody = 64 << 10
+
+	resultAllowed      = "allowed"
+	resultDenied       = "denied"
+	resultFailedOpen   = "failed_open"
+	resultFailedClosed = "failed_closed"
+)
+
+// Operations are the operations which can be reviewed
+var Operations = []string{OperationCreate, OperationUpdate, OperationDelete}
+
+var (
+	// ErrDenied is returned when a change is denied by an admission hook
+	ErrDenied = errors.New("denied by admission hook")
+	// ErrUnavailable is returned when a change is rejected because its review
+	// failed and the hook fails closed
+	ErrUnavailable = errors.New("admission hook unavailable")
+	// ErrUnexpectedStatus is returned when a hook responds with a status other
+	// than 200 OK
+	ErrUnexpectedStatus = errors.New("unexpected admission hook response status")
+	// ErrInvalidHook is returned when a hook is misconfigured
+	ErrInvalidHook = errors.New("invalid admission hook")
+)
+
+// Tenant is the state of a tenant in a review
+type Tenant struct {
+	ID          gidx.PrefixedID `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	ParentID    gidx.PrefixedID `json:"parent_id,omitempty"`
+	Kind        tenant.Kind     `json:"kind"`
+}
+
+// Review is the proposed change POSTed to admission hooks
+type Review struct {
+	// Operation is create, update or delete
+	Operation string `json:"operation"`
+	// Tenant is the tenant once the change is made, or the tenant being
+	// deleted
+	Tenant Tenant `json:"tenant"`
+	// OldTenant is the tenant before an update
+	OldTenant *Tenant `json:"old_tenant,omitempty"`
+	// Parents are the ancestors of Tenant, nearest first
+	Parents []Tenant `json:"parents"`
+	// Actor is the ID of the subject making the change, when it's known
+	Actor string `json:"actor,omitempty"`
+	// RequestID is the ID of the request making the change, when it's known
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Response is the response of admission hooks to a review
+type Response struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Option configures a Controller
+type Option func(c *Controller)
+
+// WithLogger sets the logger reviews which fail open are logged with
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(c *Controller) {
+		c.logger = logger
+	}
+}
+
+// WithHTTPClient sets the http client reviews are POSTed with
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Controller) {
+		c.httpClient = client
+	}
+}
+
+// Controller reviews tenant changes with admission hooks
+type Controller struct {
+	hooks        []HookConfig
+	totalTimeout time.Duration
+	logger       *zap.SugaredLogger
+	httpClient   *http.Client
+}
+
+// NewController returns a controller reviewing changes with the configured hooks
+func NewController(cfg Config, opts ...Option) (*Controller, error) {
+	c := &Controller{
+		hooks:        cfg.hooks(),
+		totalTimeout: cfg.totalTimeout(),
+		logger:       zap.NewNop().Sugar(),
+		httpClient:   http.DefaultClient,
+	}
+
+	for _, h := range c.hooks {
+		if err := h.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// validate returns an error when the hook is misconfigured
+func (h HookConfig) validate() error {
+	u, err := url.Parse(h.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %s: url must be an http or https url", ErrInvalidHook, h.Name)
+	}
+
+	if h.FailurePolicy != FailClosed && h.FailurePolicy != FailOpen {
+		return fmt.Errorf("%w: %s: unknown failure policy %q", ErrInvalidHook, h.Name, h.FailurePolicy)
+	}
+
+	for _, op := range h.Operations {
+		if !contains(Operations, op) {
+			return fmt.Errorf("%w: %s: unknown operation %q", ErrInvalidHook, h.Name, op)
+		}
+	}
+
+	return nil
+}
+
+// Hook is an ent hook which reviews tenant mutations before they're made. The
+// reviews are made while the mutation's transaction is open, so together
+// they're bounded by the total timeout.
+func (c *Controller) Hook(next ent.Mutator) ent.Mutator {
+	return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+		tm, ok := m.(*generated.TenantMutation)
+		if !ok || len(c.hooks) == 0 {
+			return next.Mutate(ctx, m)
+		}
+
+		reviews, err := c.reviews(ctx, tm)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.reviewAll(ctx, reviews); err != nil {
+			return nil, err
+		}
+
+		return next.Mutate(ctx, m)
+	})
+}
+
+// reviewAll reviews each change within the total timeout
+func (c *Controller) reviewAll(ctx context.Context, reviews []Review) error {
+	ctx, cancel := context.WithTimeout(ctx, c.totalTimeout)
+	defer cancel()
+
+	for _, review := range reviews {
+		if err := c.Review(ctx, review); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Review reviews the change with every hook for its operation, in order. It
+// returns ErrDenied when a hook denies the change, and ErrUnavailable when a
+// review fails for a hook which fails closed.
+func (c *Controller) Review(ctx context.Context, review Review) error {
+	for _, h := range c.hooks {
+		if !contains(h.Operations, review.Operation) {
+			continue
+		}
+
+		start := time.Now()
+
+		resp, err := c.post(ctx, h, review)
+
+		result := resultAllowed
+
+		switch {
+		case err != nil && h.FailurePolicy == FailOpen:
+			result = resultFailedOpen
+
+			c.logger.Warnw("admission hook review failed, allowing change", "hook", h.Name, "operation", review.Operation, "tenant_id", review.Tenant.ID, "error", err)
+		case err != nil:
+			result = resultFailedClosed
+
+			err = fmt.Errorf("%w: %s: %v", ErrUnavailable, h.Name, err)
+		case !resp.Allowed:
+			result = resultDenied
+
+			err = fmt.Errorf("%w: %s: %s", ErrDenied, h.Name, resp.Reason)
+		}
+
+		metrics.ObserveAdmissionReview(h.Name, result, time.Since(start))
+
+		if result == resultDenied || result == resultFailedClosed {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// post POSTs the review to the hook and returns its response
+func (c *Controller) post(ctx context.Context, h HookConfig, review Review) (Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+
+	body, err := json.Marshal(review)
+	if err != nil {
+		return Response{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return Response{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Response{}, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Response{}, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+
+	var result Response
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBody)).Decode(&result); err != nil {
+		return Response{}, fmt.Errorf("decoding admission hook response: %w", err)
+	}
+
+	return result, nil
+}
+
+// reviews returns the reviews of the tenants changed by the mutation
+func (c *Controller) reviews(ctx context.Context, m *generated.TenantMutation) ([]Review, error) {
+	client := m.Client()
+
+	actor, _ := ctx.Value(echojwtx.ActorCtxKey).(string)
+	requestID := enrichment.RequestIDFromContext(ctx)
+
+	if m.Op().Is(ent.OpCreate) {
+		id, _ := m.ID()
+
+		proposed := apply(Tenant{ID: id, Kind: tenant.DefaultKind}, m)
+
+		parents, err := ancestors(ctx, client, proposed)
+		if err != nil {
+			return nil, err
+		}
+
+		return []Review{{
+			Operation: OperationCreate,
+			Tenant:    proposed,
+			Parents:   parents,
+			Actor:     actor,
+			RequestID: requestID,
+		}}, nil
+	}
+
+	ids, err := m.IDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make([]Review, 0, len(ids))
+
+	for _, id := range ids {
+		t, err := client.Tenant.Get(ctx, id)
+
+		switch {
+		case generated.IsNotFound(err):
+			// the mutation fails with not found, there's nothing to review
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("loading tenant %s for review: %w", id, err)
+		}
+
+		old := toTenant(t)
+
+		review := Review{
+			Operation: OperationDelete,
+			Tenant:    old,
+			Actor:     actor,
+			RequestID: requestID,
+		}
+
+		if !m.Op().Is(ent.OpDelete | ent.OpDeleteOne) {
+			review.Operation = OperationUpdate
+			review.Tenant = apply(old, m)
+			review.OldTenant = &old
+		}
+
+		review.Parents, err = ancestors(ctx, client, review.Tenant)
+		if err != nil {
+			return nil, err
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+// apply returns the tenant with the mutation's changes
+func apply(t Tenant, m *generated.TenantMutation) Tenant {
+	if name, ok := m.Name(); ok {
+		t.Name = name
+	}
+
+	if desc, ok := m.Description(); ok {
+		t.Description = desc
+	}
+
+	if m.DescriptionCleared() {
+		t.Description = ""
+	}
+
+	if kind, ok := m.Kind(); ok {
+		t.Kind = kind
+	}
+
+	if parent, ok := m.ParentTenantID(); ok {
+		t.ParentID = parent
+	}
+
+	if m.ParentCleared() {
+		t.ParentID = gidx.NullPrefixedID
+	}
+
+	return t
+}
+
+// ancestors returns the ancestors of the tenant, nearest first
+func ancestors(ctx context.Context, client *generated.Client, t Tenant) ([]Tenant, error) {
+	parents := []Tenant{}
+	seen := map[gidx.PrefixedID]bool{t.ID: true}
+
+	// guard against a cycle in corrupt data looping forever
+	for id := t.ParentID; id != gidx.NullPrefixedID && !seen[id]; {
+		seen[id] = true
+
+		p, err := client.Tenant.Get(ctx, id)
+
+		switch {
+		case generated.IsNotFound(err):
+			// a missing parent fails the mutation, the chain ends before it
+			return parents, nil
+		case err != nil:
+			return nil, fmt.Errorf("loading ancestor %s for review: %w", id, err)
+		}
+
+		parents = append(parents, toTenant(p))
+
+		id = p.ParentTenantID
+	}
+
+	return parents, nil
+}
+
+func toTenant(t *generated.Tenant) Tenant {
+	return Tenant{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		ParentID:    t.ParentTenantID,
+		Kind:        t.Kind,
+	}
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
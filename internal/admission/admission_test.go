@@ -0,0 +1,266 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+)
+
+// testHook records the reviews it receives and responds with respond
+type testHook struct {
+	mu      sync.Mutex
+	reviews []Review
+	respond func(w http.ResponseWriter, review Review)
+}
+
+func (h *testHook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var review Review
+
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	h.mu.Lock()
+	h.reviews = append(h.reviews, review)
+	h.mu.Unlock()
+
+	h.respond(w, review)
+}
+
+func (h *testHook) last(t *testing.T) Review {
+	t.Helper()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	require.NotEmpty(t, h.reviews)
+
+	return h.reviews[len(h.reviews)-1]
+}
+
+func respondWith(resp Response) func(http.ResponseWriter, Review) {
+	return func(w http.ResponseWriter, _ Review) {
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func newTestClient(t *testing.T, name string, cfg Config) *ent.Client {
+	t.Helper()
+
+	client := enttest.Open(t, "sqlite3", "file:"+name+"?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { client.Close() })
+
+	controller, err := NewController(cfg)
+	require.NoError(t, err)
+
+	client.Use(controller.Hook)
+
+	return client
+}
+
+func TestHookReviews(t *testing.T) {
+	ctx := context.WithValue(context.Background(), echojwtx.ActorCtxKey, "idntusr-admin")
+
+	hook := &testHook{respond: respondWith(Response{Allowed: true})}
+
+	srv := httptest.NewServer(hook)
+	defer srv.Close()
+
+	client := newTestClient(t, "admission-reviews", Config{Hooks: []HookConfig{{URL: srv.URL}}})
+
+	acme := client.Tenant.Create().SetName("acme").SaveX(ctx)
+	finance := client.Tenant.Create().SetName("finance").SetParent(acme).SaveX(ctx)
+
+	review := hook.last(t)
+	assert.Equal(t, OperationCreate, review.Operation)
+	assert.Equal(t, finance.ID, review.Tenant.ID)
+	assert.Equal(t, "finance", review.Tenant.Name)
+	assert.Equal(t, acme.ID, review.Tenant.ParentID)
+	assert.Equal(t, tenant.KindTenant, review.Tenant.Kind, "the default kind is reviewed")
+	assert.Nil(t, review.OldTenant)
+	assert.Equal(t, []Tenant{{ID: acme.ID, Name: "acme", Kind: tenant.KindTenant}}, review.Parents)
+	assert.Equal(t, "idntusr-admin", review.Actor)
+
+	payroll := client.Tenant.Create().SetName("payroll").SetParent(finance).SaveX(ctx)
+
+	review = hook.last(t)
+	assert.Equal(t, []Tenant{
+		{ID: finance.ID, Name: "finance", ParentID: acme.ID, Kind: tenant.KindTenant},
+		{ID: acme.ID, Name: "acme", Kind: tenant.KindTenant},
+	}, review.Parents, "parents are nearest first")
+
+	client.Tenant.UpdateOne(payroll).SetName("salaries").SetDescription("payroll team").ExecX(ctx)
+
+	review = hook.last(t)
+	assert.Equal(t, OperationUpdate, review.Operation)
+	assert.Equal(t, Tenant{ID: payroll.ID, Name: "salaries", Description: "payroll team", ParentID: finance.ID, Kind: tenant.KindTenant}, review.Tenant)
+	require.NotNil(t, review.OldTenant)
+	assert.Equal(t, Tenant{ID: payroll.ID, Name: "payroll", ParentID: finance.ID, Kind: tenant.KindTenant}, *review.OldTenant)
+	assert.Len(t, review.Parents, 2)
+
+	client.Tenant.Create().SetName("apps").SetKind(tenant.KindProject).SetParent(acme).ExecX(ctx)
+
+	review = hook.last(t)
+	assert.Equal(t, tenant.KindProject, review.Tenant.Kind)
+
+	client.Tenant.DeleteOneID(payroll.ID).ExecX(ctx)
+	client.Tenant.DeleteOneID(finance.ID).ExecX(ctx)
+
+	review = hook.last(t)
+	assert.Equal(t, OperationDelete, review.Operation)
+	assert.Equal(t, Tenant{ID: finance.ID, Name: "finance", ParentID: acme.ID, Kind: tenant.KindTenant}, review.Tenant)
+	assert.Equal(t, []Tenant{{ID: acme.ID, Name: "acme", Kind: tenant.KindTenant}}, review.Parents)
+}
+
+func TestHookDenies(t *testing.T) {
+	ctx := context.Background()
+
+	// no tenants under finance without a cost center in their description
+	hook := &testHook{respond: func(w http.ResponseWriter, review Review) {
+		resp := Response{Allowed: true}
+
+		if len(review.Parents) > 0 && review.Parents[0].Name == "finance" && review.Tenant.Description == "" {
+			resp = Response{Allowed: false, Reason: "tenants under finance need a cost center"}
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	}}
+
+	srv := httptest.NewServer(hook)
+	defer srv.Close()
+
+	client := newTestClient(t, "admission-denies", Config{Hooks: []HookConfig{{Name: "finance-policy", URL: srv.URL}}})
+
+	finance := client.Tenant.Create().SetName("finance").SaveX(ctx)
+
+	_, err := client.Tenant.Create().SetName("payroll").SetParent(finance).Save(ctx)
+	require.ErrorIs(t, err, ErrDenied)
+	assert.EqualError(t, err, "denied by admission hook: finance-policy: tenants under finance need a cost center")
+
+	assert.Equal(t, 1, client.Tenant.Query().CountX(ctx), "denied tenant isn't created")
+
+	client.Tenant.Create().SetName("payroll").SetDescription("cc-1234").SetParent(finance).ExecX(ctx)
+}
+
+func TestHookFailurePolicy(t *testing.T) {
+	ctx := context.Background()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+
+		_ = json.NewEncoder(w).Encode(Response{Allowed: true})
+	}))
+	defer slow.Close()
+
+	tests := []struct {
+		name    string
+		hook    HookConfig
+		wantErr error
+	}{
+		{name: "fail closed", hook: HookConfig{URL: failing.URL}, wantErr: ErrUnavailable},
+		{name: "fail open", hook: HookConfig{URL: failing.URL, FailurePolicy: FailOpen}},
+		{name: "timeout", hook: HookConfig{URL: slow.URL, Timeout: 10 * time.Millisecond}, wantErr: ErrUnavailable},
+		{name: "unreachable", hook: HookConfig{URL: "http://127.0.0.1:1", FailurePolicy: FailOpen}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, err := NewController(Config{Hooks: []HookConfig{tt.hook}})
+			require.NoError(t, err)
+
+			err = controller.Review(ctx, Review{Operation: OperationCreate, Tenant: Tenant{Name: "tenant"}})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHookTotalTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+
+		_ = json.NewEncoder(w).Encode(Response{Allowed: true})
+	}))
+	defer slow.Close()
+
+	hook := HookConfig{URL: slow.URL, Timeout: time.Second}
+
+	client := newTestClient(t, "admission-total-timeout", Config{
+		TotalTimeout: 50 * time.Millisecond,
+		Hooks:        []HookConfig{hook, hook, hook},
+	})
+
+	start := time.Now()
+
+	_, err := client.Tenant.Create().SetName("tenant").Save(ctx)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "the reviews are bounded by the total timeout")
+}
+
+func TestHookOperations(t *testing.T) {
+	ctx := context.Background()
+
+	hook := &testHook{respond: respondWith(Response{Allowed: false, Reason: "tenants can't be deleted"})}
+
+	srv := httptest.NewServer(hook)
+	defer srv.Close()
+
+	client := newTestClient(t, "admission-operations", Config{Hooks: []HookConfig{{URL: srv.URL, Operations: []string{OperationDelete}}}})
+
+	tnt := client.Tenant.Create().SetName("tenant").SaveX(ctx)
+	client.Tenant.UpdateOne(tnt).SetName("renamed").ExecX(ctx)
+
+	assert.ErrorIs(t, client.Tenant.DeleteOne(tnt).Exec(ctx), ErrDenied)
+	assert.Len(t, hook.reviews, 1, "only deletes are reviewed")
+}
+
+func TestNewControllerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		hook HookConfig
+	}{
+		{name: "missing url", hook: HookConfig{}},
+		{name: "unsupported url", hook: HookConfig{URL: "ftp://policy"}},
+		{name: "unknown failure policy", hook: HookConfig{URL: "http://policy", FailurePolicy: "sometimes"}},
+		{name: "unknown operation", hook: HookConfig{URL: "http://policy", Operations: []string{"archive"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewController(Config{Hooks: []HookConfig{tt.hook}})
+			assert.ErrorIs(t, err, ErrInvalidHook)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package admission
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+const (
+	defaultTimeout      = 5 * time.Second
+	defaultTotalTimeout = 10 * time.Second
+)
+
+// FailurePolicy is what happens to a change when its review fails, because the
+// hook can't be reached, times out or doesn't respond with a review result
+type FailurePolicy string
+
+const (
+	// FailClosed rejects the change when its review fails
+	FailClosed FailurePolicy = "closed"
+	// FailOpen allows the change when its review fails
+	FailOpen FailurePolicy = "open"
+)
+
+// Config stores the configuration for admission hooks
+type Config struct {
+	// Timeout bounds each review of hooks without a timeout
+	Timeout time.Duration `mapstructure:"timeout"`
+	// TotalTimeout bounds all the reviews of a change, which are made while
+	// its transaction is open
+	TotalTimeout time.Duration `mapstructure:"total_timeout"`
+	// FailurePolicy is the failure policy of hooks without one, defaults to
+	// FailClosed
+	FailurePolicy FailurePolicy `mapstructure:"failure_policy"`
+	// Hooks review every tenant change in order, they're set in the config
+	// file
+	Hooks []HookConfig `mapstructure:"hooks"`
+}
+
+// HookConfig stores the configuration for an admission hook
+type HookConfig struct {
+	// Name identifies the hook in errors, logs and metrics, defaults to its URL
+	Name string `mapstructure:"name"`
+	// URL is the endpoint reviews are POSTed to
+	URL string `mapstructure:"url"`
+	// Timeout bounds each review, defaults to Config.Timeout
+	Timeout time.Duration `mapstructure:"timeout"`
+	// FailurePolicy defaults to Config.FailurePolicy
+	FailurePolicy FailurePolicy `mapstructure:"failure_policy"`
+	// Operations are the operations reviewed by the hook, create, update or
+	// delete, defaults to all of them
+	Operations []string `mapstructure:"operations"`
+}
+
+// MustViperFlags returns the cobra flags and viper config for admission hooks
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Duration("admission-timeout", defaultTimeout, "timeout for admission hook reviews, unless set by the hook")
+	viperx.MustBindFlag(v, "admission.timeout", flags.Lookup("admission-timeout"))
+
+	flags.Duration("admission-total-timeout", defaultTotalTimeout, "timeout for all the admission hook reviews of a change")
+	viperx.MustBindFlag(v, "admission.total_timeout", flags.Lookup("admission-total-timeout"))
+
+	flags.String("admission-failure-policy", string(FailClosed), "whether changes are allowed when an admission hook review fails, closed or open, unless set by the hook")
+	viperx.MustBindFlag(v, "admission.failure_policy", flags.Lookup("admission-failure-policy"))
+}
+
+// totalTimeout returns the timeout for all the reviews of a change
+func (c Config) totalTimeout() time.Duration {
+	if c.TotalTimeout <= 0 {
+		return defaultTotalTimeout
+	}
+
+	return c.TotalTimeout
+}
+
+// hooks returns the hooks with defaults for unset values
+func (c Config) hooks() []HookConfig {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	policy := c.FailurePolicy
+	if policy == "" {
+		policy = FailClosed
+	}
+
+	hooks := make([]HookConfig, len(c.Hooks))
+
+	for i, h := range c.Hooks {
+		if h.Name == "" {
+			h.Name = h.URL
+		}
+
+		if h.Timeout <= 0 {
+			h.Timeout = timeout
+		}
+
+		if h.FailurePolicy == "" {
+			h.FailurePolicy = policy
+		}
+
+		if len(h.Operations) == 0 {
+			h.Operations = Operations
+		}
+
+		hooks[i] = h
+	}
+
+	return hooks
+}
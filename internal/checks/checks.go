@@ -0,0 +1,174 @@
+// Package checks provides the readiness checks for the services tenant-api depends on.
+package checks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.infratographer.com/x/echox"
+	"go.infratographer.com/x/events"
+)
+
+var (
+	// ErrLatencyThresholdExceeded is returned when the database responds slower than the configured threshold
+	ErrLatencyThresholdExceeded = errors.New("latency threshold exceeded")
+	// ErrEventsNotConnected is returned when the connection to the events server is down
+	ErrEventsNotConnected = errors.New("events server not connected")
+	// ErrUnexpectedStatus is returned when a dependency responds with a non 200 status
+	ErrUnexpectedStatus = errors.New("unexpected status code")
+)
+
+// Database returns a readiness check which pings the database, failing if it
+// can't be reached or responds slower than the latency threshold.
+func Database(db *sql.DB, cfg DatabaseConfig) echox.CheckFunc {
+	return func(ctx context.Context) error {
+		ctx, cancel := withTimeout(ctx, cfg.Timeout)
+		defer cancel()
+
+		start := time.Now()
+
+		if err := db.PingContext(ctx); err != nil {
+			return err
+		}
+
+		if latency := time.Since(start); cfg.LatencyThreshold > 0 && latency > cfg.LatencyThreshold {
+			return fmt.Errorf("%w: ping took %s, threshold %s", ErrLatencyThresholdExceeded, latency, cfg.LatencyThreshold)
+		}
+
+		return nil
+	}
+}
+
+// Permissions returns a readiness check which requests the configured path on
+// the permissions-api host.
+func Permissions(permissionsURL string, cfg PermissionsConfig) (echox.CheckFunc, error) {
+	u, err := url.Parse(permissionsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	checkURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: cfg.Path}
+
+	client := &http.Client{}
+
+	return func(ctx context.Context) error {
+		ctx, cancel := withTimeout(ctx, cfg.Timeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, checkURL.String(), nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+		}
+
+		return nil
+	}, nil
+}
+
+// EventsChecker checks the events server used by the publisher can be reached.
+//
+// The publisher doesn't expose its connection, so the checker maintains its own
+// using the same connection settings.
+type EventsChecker struct {
+	cfg     events.PublisherConfig
+	timeout time.Duration
+
+	mu   sync.Mutex
+	conn *nats.Conn
+}
+
+// NewEventsChecker returns a checker for the events server in the publisher config
+func NewEventsChecker(pubCfg events.PublisherConfig, cfg CheckConfig) *EventsChecker {
+	return &EventsChecker{
+		cfg:     pubCfg,
+		timeout: cfg.Timeout,
+	}
+}
+
+// Check is a readiness check which fails when the events server can't be reached.
+func (c *EventsChecker) Check(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx, c.timeout)
+	defer cancel()
+
+	conn, err := c.connection()
+	if err != nil {
+		return err
+	}
+
+	if !conn.IsConnected() {
+		return fmt.Errorf("%w: %s", ErrEventsNotConnected, conn.Status())
+	}
+
+	// flushing waits for a round trip to the server
+	return conn.FlushWithContext(ctx)
+}
+
+// Close closes the connection to the events server.
+func (c *EventsChecker) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+}
+
+func (c *EventsChecker) connection() (*nats.Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		return c.conn, nil
+	}
+
+	options := []nats.Option{
+		nats.Name("tenant-api-readiness"),
+		nats.MaxReconnects(-1),
+	}
+
+	if c.timeout > 0 {
+		options = append(options, nats.Timeout(c.timeout))
+	}
+
+	switch {
+	case c.cfg.NATSConfig.CredsFile != "":
+		options = append(options, nats.UserCredentials(c.cfg.NATSConfig.CredsFile))
+	case c.cfg.NATSConfig.Token != "":
+		options = append(options, nats.Token(c.cfg.NATSConfig.Token))
+	}
+
+	conn, err := nats.Connect(c.cfg.URL, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.conn = conn
+
+	return conn, nil
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
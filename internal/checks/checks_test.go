@@ -0,0 +1,83 @@
+package checks_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/testing/eventtools"
+
+	"go.infratographer.com/tenant-api/internal/checks"
+)
+
+func TestDatabaseCheck(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite3", "file:checks?mode=memory&cache=shared")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	cfg := checks.DatabaseConfig{CheckConfig: checks.CheckConfig{Timeout: time.Second}}
+
+	assert.NoError(t, checks.Database(db, cfg)(ctx))
+
+	cfg.LatencyThreshold = time.Nanosecond
+	assert.ErrorIs(t, checks.Database(db, cfg)(ctx), checks.ErrLatencyThresholdExceeded)
+
+	require.NoError(t, db.Close())
+	assert.Error(t, checks.Database(db, checks.DatabaseConfig{})(ctx))
+}
+
+func TestPermissionsCheck(t *testing.T) {
+	ctx := context.Background()
+
+	status := http.StatusOK
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/livez", r.URL.Path)
+
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	check, err := checks.Permissions(srv.URL+"/api/v1/allow", checks.PermissionsConfig{
+		CheckConfig: checks.CheckConfig{Timeout: time.Second},
+		Path:        "/livez",
+	})
+	require.NoError(t, err)
+
+	assert.NoError(t, check(ctx))
+
+	status = http.StatusServiceUnavailable
+	assert.ErrorIs(t, check(ctx), checks.ErrUnexpectedStatus)
+
+	srv.Close()
+	assert.Error(t, check(ctx))
+}
+
+func TestEventsCheck(t *testing.T) {
+	ctx := context.Background()
+
+	nats, err := eventtools.NewNatsServer()
+	require.NoError(t, err)
+
+	defer nats.Close()
+
+	checker := checks.NewEventsChecker(nats.PublisherConfig, checks.CheckConfig{Timeout: time.Second})
+	defer checker.Close()
+
+	assert.NoError(t, checker.Check(ctx))
+
+	unreachable := checks.NewEventsChecker(events.PublisherConfig{URL: "nats://127.0.0.1:1"}, checks.CheckConfig{Timeout: time.Second})
+	defer unreachable.Close()
+
+	assert.Error(t, unreachable.Check(ctx))
+}
@@ -0,0 +1,68 @@
+package checks
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+const (
+	defaultTimeout          = 2 * time.Second
+	defaultLatencyThreshold = 500 * time.Millisecond
+	defaultPermissionsPath  = "/livez"
+)
+
+// Config stores the configuration for the readiness checks
+type Config struct {
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Events      CheckConfig       `mapstructure:"events"`
+	Permissions PermissionsConfig `mapstructure:"permissions"`
+}
+
+// CheckConfig stores the configuration shared by all readiness checks
+type CheckConfig struct {
+	// Enabled registers the check with the server
+	Enabled bool `mapstructure:"enabled"`
+	// Timeout is how long the check may run before it fails
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// DatabaseConfig stores the configuration for the database readiness check
+type DatabaseConfig struct {
+	CheckConfig `mapstructure:",squash"`
+	// LatencyThreshold fails the check when pinging the database takes longer, zero disables it
+	LatencyThreshold time.Duration `mapstructure:"latency_threshold"`
+}
+
+// PermissionsConfig stores the configuration for the permissions-api readiness check
+type PermissionsConfig struct {
+	CheckConfig `mapstructure:",squash"`
+	// Path is the path requested on the permissions-api host
+	Path string `mapstructure:"path"`
+}
+
+// MustViperFlags returns the cobra flags and viper config for the readiness checks
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Bool("check-database", true, "enable the database readiness check")
+	viperx.MustBindFlag(v, "checks.database.enabled", flags.Lookup("check-database"))
+
+	flags.Bool("check-events", true, "enable the events publisher readiness check")
+	viperx.MustBindFlag(v, "checks.events.enabled", flags.Lookup("check-events"))
+
+	flags.Bool("check-permissions", true, "enable the permissions-api readiness check")
+	viperx.MustBindFlag(v, "checks.permissions.enabled", flags.Lookup("check-permissions"))
+
+	v.MustBindEnv("checks.database.timeout")
+	v.MustBindEnv("checks.database.latency_threshold")
+	v.MustBindEnv("checks.events.timeout")
+	v.MustBindEnv("checks.permissions.timeout")
+	v.MustBindEnv("checks.permissions.path")
+
+	v.SetDefault("checks.database.timeout", defaultTimeout)
+	v.SetDefault("checks.database.latency_threshold", defaultLatencyThreshold)
+	v.SetDefault("checks.events.timeout", defaultTimeout)
+	v.SetDefault("checks.permissions.timeout", defaultTimeout)
+	v.SetDefault("checks.permissions.path", defaultPermissionsPath)
+}
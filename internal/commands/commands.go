@@ -0,0 +1,467 @@
+// Package commands executes tenant commands consumed from the events
+// subscriber, so provisioning pipelines can create, update and delete tenants
+// asynchronously.
+//
+// A command is an events.EventMessage published to the command topic, its
+// event type is the operation and its data is a CommandData. Commands are
+// authorized with the bearer token they carry, by the same middleware and
+// permission checks as the APIs. The outcome of every command is published to
+// the result topic as an event whose data is a Result, correlated with the
+// command by its request ID. Tenant changes made by commands publish change
+// events as usual.
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/tenant-api/internal/admission"
+	"go.infratographer.com/tenant-api/internal/enrichment"
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/graphapi"
+	"go.infratographer.com/tenant-api/internal/kinds"
+	"go.infratographer.com/tenant-api/internal/limits"
+	"go.infratographer.com/tenant-api/internal/tenants"
+	"go.infratographer.com/tenant-api/internal/validation"
+)
+
+// Operations a command can request, they're the command's event type and
+// match the change types of the changes they make
+const (
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// Result statuses
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Error codes of failed results
+const (
+	CodeInvalid          = "invalid"
+	CodeUnauthenticated  = "unauthenticated"
+	CodePermissionDenied = "permission_denied"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeLimitExceeded    = "limit_exceeded"
+	CodeInternal         = "internal"
+)
+
+var (
+	// ErrSubscriptionClosed is returned by Run when the command subscription ends
+	ErrSubscriptionClosed = errors.New("command subscription closed")
+	// ErrUnknownOperation is the error of a command with an unknown event type
+	ErrUnknownOperation = errors.New("unknown command operation")
+	// ErrSubjectRequired is the error of an update or delete command without
+	// the tenant as its subject
+	ErrSubjectRequired = errors.New("command subject is required")
+	// ErrNameRequired is the error of a create command without a name
+	ErrNameRequired = errors.New("name is required")
+	// ErrTenantHasChildren is the error of a delete command for a tenant
+	// with children
+	ErrTenantHasChildren = tenants.ErrHasChildren
+	// ErrFieldNotUpdatable is the error of an update command setting a field
+	// which can't be changed once the tenant is created
+	ErrFieldNotUpdatable = errors.New("field can't be updated")
+
+	errUnauthenticated = errors.New("unauthenticated")
+)
+
+// CommandData is the data of a command
+type CommandData struct {
+	// RequestID correlates the command with its result, the command's
+	// message ID is used when it's empty
+	RequestID string `json:"request_id,omitempty"`
+	// Authorization is the bearer token the command is authorized with, as
+	// it would be sent in an Authorization header
+	Authorization string `json:"authorization,omitempty"`
+
+	Name             *string          `json:"name,omitempty"`
+	Description      *string          `json:"description,omitempty"`
+	ClearDescription bool             `json:"clear_description,omitempty"`
+	Kind             *tenant.Kind     `json:"kind,omitempty"`
+	ParentID         *gidx.PrefixedID `json:"parent_id,omitempty"`
+}
+
+// Result is the data of a command's result
+type Result struct {
+	RequestID string `json:"request_id"`
+	Status    string `json:"status"`
+	// TenantID is the tenant the command changed, the created tenant's ID
+	// for a create command
+	TenantID gidx.PrefixedID `json:"tenant_id,omitempty"`
+	Code     string          `json:"code,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// EventSubscriber subscribes to event messages, it's satisfied by
+// *events.Subscriber
+type EventSubscriber interface {
+	SubscribeEvents(ctx context.Context, topic string) (<-chan *message.Message, error)
+}
+
+// EventPublisher publishes event messages, it's satisfied by *events.Publisher
+type EventPublisher interface {
+	PublishEvent(ctx context.Context, subjectType string, event events.EventMessage) error
+}
+
+// Worker executes commands and publishes their results
+type Worker struct {
+	client     *ent.Client
+	publisher  EventPublisher
+	cfg        Config
+	echo       *echo.Echo
+	middleware []echo.MiddlewareFunc
+	logger     *zap.SugaredLogger
+}
+
+// Option configures a Worker
+type Option func(*Worker)
+
+// WithLogger sets the logger for the worker
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(w *Worker) {
+		w.logger = logger
+	}
+}
+
+// WithMiddleware sets the echo middleware commands are authorized with, it's
+// the middleware used by the HTTP APIs and is run against the command's
+// authorization. Commands are checked with the permissions checker it sets,
+// without middleware they're checked with the checker in Run's context.
+func WithMiddleware(middleware []echo.MiddlewareFunc) Option {
+	return func(w *Worker) {
+		w.middleware = middleware
+	}
+}
+
+// NewWorker returns a worker executing commands with client and publishing
+// their results with publisher
+func NewWorker(client *ent.Client, publisher EventPublisher, cfg Config, opts ...Option) *Worker {
+	w := &Worker{
+		client:    client,
+		publisher: publisher,
+		cfg:       cfg.withDefaults(),
+		echo:      echo.New(),
+		logger:    zap.NewNop().Sugar(),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Run executes the commands received from sub, it returns when the context is
+// canceled or the subscription ends. Commands are executed one at a time.
+func (w *Worker) Run(ctx context.Context, sub EventSubscriber) error {
+	messages, err := sub.SubscribeEvents(ctx, w.cfg.Topic+".*")
+	if err != nil {
+		return fmt.Errorf("subscribing to tenant commands: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return ErrSubscriptionClosed
+			}
+
+			w.handleMessage(ctx, msg)
+		}
+	}
+}
+
+// handleMessage executes the command and publishes its result. The command is
+// acked whatever its outcome, redelivering a command which failed after
+// changing a tenant would repeat the change, so retrying is left to the
+// producer.
+func (w *Worker) handleMessage(ctx context.Context, msg *message.Message) {
+	defer msg.Ack()
+
+	cmd, err := events.UnmarshalEventMessage(msg.Payload)
+	if err != nil {
+		w.logger.Warnw("failed to unmarshal command", "error", err, "message_id", msg.UUID)
+
+		return
+	}
+
+	var data CommandData
+
+	if err := decodeData(cmd.Data, &data); err != nil {
+		w.logger.Warnw("failed to decode command data", "error", err, "message_id", msg.UUID)
+
+		return
+	}
+
+	if data.RequestID == "" {
+		data.RequestID = msg.UUID
+	}
+
+	ctx = events.TraceContextFromEventMessage(ctx, cmd)
+	ctx = enrichment.ContextWithRequestID(ctx, data.RequestID)
+
+	result := w.Execute(ctx, cmd.EventType, cmd.SubjectID, data)
+
+	logger := w.logger.With("request_id", result.RequestID, "operation", cmd.EventType, "tenant_id", result.TenantID)
+
+	if result.Status == StatusFailed {
+		logger.Infow("tenant command failed", "code", result.Code, "error", result.Error)
+	} else {
+		logger.Debugw("tenant command succeeded")
+	}
+
+	if err := w.publishResult(ctx, cmd.EventType, result); err != nil {
+		logger.Errorw("failed to publish tenant command result", "error", err)
+	}
+}
+
+// Execute authorizes and executes a command, and returns its result
+func (w *Worker) Execute(ctx context.Context, operation string, subjectID gidx.PrefixedID, data CommandData) Result {
+	result := Result{
+		RequestID: data.RequestID,
+		Status:    StatusSucceeded,
+		TenantID:  subjectID,
+	}
+
+	id, err := w.execute(ctx, operation, subjectID, data)
+	if err != nil {
+		result.Status = StatusFailed
+		result.Code = errorCode(err)
+		result.Error = err.Error()
+
+		return result
+	}
+
+	result.TenantID = id
+
+	return result
+}
+
+func (w *Worker) execute(ctx context.Context, operation string, subjectID gidx.PrefixedID, data CommandData) (gidx.PrefixedID, error) {
+	if operation != OperationCreate && operation != OperationUpdate && operation != OperationDelete {
+		return gidx.NullPrefixedID, fmt.Errorf("%w: %q", ErrUnknownOperation, operation)
+	}
+
+	if operation != OperationCreate && subjectID == gidx.NullPrefixedID {
+		return gidx.NullPrefixedID, ErrSubjectRequired
+	}
+
+	ctx, err := w.authenticate(ctx, data.Authorization)
+	if err != nil {
+		return gidx.NullPrefixedID, err
+	}
+
+	switch operation {
+	case OperationCreate:
+		return w.create(ctx, data)
+	case OperationUpdate:
+		return subjectID, w.update(ctx, subjectID, data)
+	default:
+		return subjectID, w.delete(ctx, subjectID)
+	}
+}
+
+func (w *Worker) create(ctx context.Context, data CommandData) (gidx.PrefixedID, error) {
+	if data.Name == nil || *data.Name == "" {
+		return gidx.NullPrefixedID, ErrNameRequired
+	}
+
+	resource := gidx.NullPrefixedID
+
+	if data.ParentID != nil {
+		resource = *data.ParentID
+	}
+
+	if err := permissions.CheckAccess(ctx, resource, graphapi.ActionTenantCreate); err != nil {
+		return gidx.NullPrefixedID, err
+	}
+
+	t, err := tenants.Create(ctx, w.client, ent.CreateTenantInput{
+		Name:        *data.Name,
+		Description: data.Description,
+		Kind:        data.Kind,
+		ParentID:    data.ParentID,
+	})
+	if err != nil {
+		return gidx.NullPrefixedID, err
+	}
+
+	return t.ID, nil
+}
+
+func (w *Worker) update(ctx context.Context, id gidx.PrefixedID, data CommandData) error {
+	switch {
+	case data.Kind != nil:
+		return fmt.Errorf("%w: %s", ErrFieldNotUpdatable, tenant.FieldKind)
+	case data.ParentID != nil:
+		return fmt.Errorf("%w: %s", ErrFieldNotUpdatable, tenant.FieldParentTenantID)
+	}
+
+	if err := permissions.CheckAccess(ctx, id, graphapi.ActionTenantUpdate); err != nil {
+		return err
+	}
+
+	_, err := tenants.Update(ctx, w.client, id, ent.UpdateTenantInput{
+		Name:             data.Name,
+		Description:      data.Description,
+		ClearDescription: data.ClearDescription,
+	})
+
+	return err
+}
+
+func (w *Worker) delete(ctx context.Context, id gidx.PrefixedID) error {
+	if err := permissions.CheckAccess(ctx, id, graphapi.ActionTenantDelete); err != nil {
+		return err
+	}
+
+	return tenants.Delete(ctx, w.client, id)
+}
+
+// discardResponse is the response of the request commands are authenticated
+// with, the middleware's responses are returned to the worker as errors so
+// nothing written to it is kept
+type discardResponse struct {
+	header http.Header
+}
+
+// Header returns the response's header
+func (r *discardResponse) Header() http.Header {
+	return r.header
+}
+
+// Write discards the response body
+func (r *discardResponse) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// WriteHeader discards the response status
+func (r *discardResponse) WriteHeader(int) {}
+
+// authenticate runs the middleware against a request with the command's
+// authorization, and returns the context it sets up with the actor and
+// permissions checker. Commands are authenticated by the APIs' middleware so
+// they're authorized exactly like API requests.
+func (w *Worker) authenticate(ctx context.Context, authorization string) (context.Context, error) {
+	if len(w.middleware) == 0 {
+		return ctx, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/"+w.cfg.Topic, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+
+	c := w.echo.NewContext(req, &discardResponse{header: http.Header{}})
+
+	var authCtx context.Context
+
+	h := func(c echo.Context) error {
+		authCtx = c.Request().Context()
+
+		return nil
+	}
+
+	for i := len(w.middleware) - 1; i >= 0; i-- {
+		h = w.middleware[i](h)
+	}
+
+	if err := h(c); err != nil {
+		var httpErr *echo.HTTPError
+
+		if errors.As(err, &httpErr) {
+			switch httpErr.Code {
+			case http.StatusUnauthorized:
+				return nil, errUnauthenticated
+			case http.StatusForbidden:
+				return nil, permissions.ErrPermissionDenied
+			}
+		}
+
+		return nil, err
+	}
+
+	return authCtx, nil
+}
+
+func (w *Worker) publishResult(ctx context.Context, operation string, result Result) error {
+	data, err := encodeData(result)
+	if err != nil {
+		return err
+	}
+
+	return w.publisher.PublishEvent(ctx, w.cfg.ResultTopic, events.EventMessage{
+		SubjectID: result.TenantID,
+		EventType: operation,
+		Data:      data,
+	})
+}
+
+// errorCode returns the code of a failed command's error
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrUnknownOperation), errors.Is(err, ErrSubjectRequired), errors.Is(err, ErrNameRequired), errors.Is(err, ErrFieldNotUpdatable),
+		ent.IsValidationError(err), ent.IsConstraintError(err), validation.IsFieldError(err), errors.Is(err, kinds.ErrKindNotAllowed):
+		return CodeInvalid
+	case errors.Is(err, errUnauthenticated):
+		return CodeUnauthenticated
+	case errors.Is(err, permissions.ErrPermissionDenied), errors.Is(err, admission.ErrDenied):
+		return CodePermissionDenied
+	case ent.IsNotFound(err):
+		return CodeNotFound
+	case errors.Is(err, ErrTenantHasChildren):
+		return CodeConflict
+	case errors.Is(err, limits.ErrMaxDepth), errors.Is(err, limits.ErrMaxChildren):
+		return CodeLimitExceeded
+	default:
+		return CodeInternal
+	}
+}
+
+// decodeData decodes an event's data into v
+func decodeData(data map[string]interface{}, v interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// encodeData encodes v as an event's data
+func encodeData(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,300 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/labstack/echo/v4"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/limits"
+)
+
+type fakeSubscriber struct {
+	topic    string
+	messages chan *message.Message
+}
+
+func (s *fakeSubscriber) SubscribeEvents(_ context.Context, topic string) (<-chan *message.Message, error) {
+	s.topic = topic
+
+	return s.messages, nil
+}
+
+type publishedEvent struct {
+	subjectType string
+	event       events.EventMessage
+}
+
+type fakePublisher struct {
+	events chan publishedEvent
+}
+
+func (p *fakePublisher) PublishEvent(_ context.Context, subjectType string, event events.EventMessage) error {
+	p.events <- publishedEvent{subjectType: subjectType, event: event}
+
+	return nil
+}
+
+type testWorker struct {
+	client   *ent.Client
+	sub      *fakeSubscriber
+	messages chan *message.Message
+	results  chan publishedEvent
+}
+
+func (tw *testWorker) send(t *testing.T, operation string, subjectID gidx.PrefixedID, data CommandData) *message.Message {
+	t.Helper()
+
+	d, err := encodeData(data)
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(events.EventMessage{
+		SubjectID: subjectID,
+		EventType: operation,
+		Data:      d,
+	})
+	require.NoError(t, err)
+
+	msg := message.NewMessage(watermill.NewUUID(), payload)
+
+	tw.messages <- msg
+
+	return msg
+}
+
+func (tw *testWorker) result(t *testing.T) (publishedEvent, Result) {
+	t.Helper()
+
+	select {
+	case published := <-tw.results:
+		var result Result
+
+		require.NoError(t, decodeData(published.event.Data, &result))
+
+		return published, result
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for command result")
+	}
+
+	return publishedEvent{}, Result{}
+}
+
+func newTestWorker(t *testing.T, name string, opts ...Option) *testWorker {
+	t.Helper()
+
+	client := enttest.Open(t, "sqlite3", "file:"+name+"?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { client.Close() })
+
+	tw := &testWorker{
+		client:   client,
+		messages: make(chan *message.Message),
+		results:  make(chan publishedEvent, 1),
+	}
+
+	tw.sub = &fakeSubscriber{messages: tw.messages}
+	worker := NewWorker(client, &fakePublisher{events: tw.results}, Config{}, opts...)
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), permissions.CheckerCtxKey, permissions.DefaultAllowChecker))
+
+	done := make(chan error)
+
+	go func() {
+		done <- worker.Run(ctx, tw.sub)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	return tw
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestWorker(t *testing.T) {
+	ctx := context.Background()
+
+	tw := newTestWorker(t, "commands-worker")
+
+	// create a root
+	tw.send(t, OperationCreate, gidx.NullPrefixedID, CommandData{RequestID: "request-1", Name: ptr("root")})
+
+	published, result := tw.result(t)
+	assert.Equal(t, "tenant-commands.*", tw.sub.topic)
+	assert.Equal(t, defaultResultTopic, published.subjectType)
+	assert.Equal(t, OperationCreate, published.event.EventType)
+	assert.Equal(t, StatusSucceeded, result.Status, result.Error)
+	assert.Equal(t, "request-1", result.RequestID)
+	assert.Equal(t, result.TenantID, published.event.SubjectID)
+
+	root := tw.client.Tenant.GetX(ctx, result.TenantID)
+	assert.Equal(t, "root", root.Name)
+
+	// create a child, the message ID is the request ID when there isn't one
+	msg := tw.send(t, OperationCreate, gidx.NullPrefixedID, CommandData{Name: ptr("child"), ParentID: &root.ID})
+
+	_, result = tw.result(t)
+	assert.Equal(t, StatusSucceeded, result.Status, result.Error)
+	assert.Equal(t, msg.UUID, result.RequestID)
+
+	child := tw.client.Tenant.GetX(ctx, result.TenantID)
+	assert.Equal(t, root.ID, child.ParentTenantID)
+
+	// update the child
+	tw.send(t, OperationUpdate, child.ID, CommandData{Name: ptr("renamed"), Description: ptr("described")})
+
+	_, result = tw.result(t)
+	assert.Equal(t, StatusSucceeded, result.Status, result.Error)
+	assert.Equal(t, child.ID, result.TenantID)
+
+	child = tw.client.Tenant.GetX(ctx, child.ID)
+	assert.Equal(t, "renamed", child.Name)
+	assert.Equal(t, "described", child.Description)
+
+	// a tenant with children can't be deleted
+	tw.send(t, OperationDelete, root.ID, CommandData{})
+
+	_, result = tw.result(t)
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.Equal(t, CodeConflict, result.Code)
+	assert.Equal(t, ErrTenantHasChildren.Error(), result.Error)
+
+	// delete the child then the root
+	for _, id := range []gidx.PrefixedID{child.ID, root.ID} {
+		tw.send(t, OperationDelete, id, CommandData{})
+
+		_, result = tw.result(t)
+		assert.Equal(t, StatusSucceeded, result.Status, result.Error)
+		assert.Equal(t, id, result.TenantID)
+	}
+
+	assert.Zero(t, tw.client.Tenant.Query().CountX(ctx))
+}
+
+func TestWorkerFailures(t *testing.T) {
+	tw := newTestWorker(t, "commands-worker-failures")
+
+	tests := []struct {
+		name      string
+		operation string
+		subjectID gidx.PrefixedID
+		data      CommandData
+		code      string
+	}{
+		{name: "unknown operation", operation: "archive", subjectID: "tnntten-missing", code: CodeInvalid},
+		{name: "missing name", operation: OperationCreate, code: CodeInvalid},
+		{name: "missing subject", operation: OperationUpdate, data: CommandData{Name: ptr("name")}, code: CodeInvalid},
+		{name: "missing parent", operation: OperationCreate, data: CommandData{Name: ptr("name"), ParentID: ptr(gidx.PrefixedID("tnntten-missing"))}, code: CodeInvalid},
+		{name: "update kind", operation: OperationUpdate, subjectID: "tnntten-missing", data: CommandData{Kind: ptr(tenant.KindProject)}, code: CodeInvalid},
+		{name: "update parent", operation: OperationUpdate, subjectID: "tnntten-missing", data: CommandData{ParentID: ptr(gidx.PrefixedID("tnntten-parent"))}, code: CodeInvalid},
+		{name: "update missing tenant", operation: OperationUpdate, subjectID: "tnntten-missing", data: CommandData{Name: ptr("name")}, code: CodeNotFound},
+		{name: "delete missing tenant", operation: OperationDelete, subjectID: "tnntten-missing", code: CodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw.send(t, tt.operation, tt.subjectID, tt.data)
+
+			published, result := tw.result(t)
+			assert.Equal(t, tt.operation, published.event.EventType)
+			assert.Equal(t, StatusFailed, result.Status)
+			assert.Equal(t, tt.code, result.Code, result.Error)
+			assert.NotEmpty(t, result.Error)
+		})
+	}
+
+	t.Run("limit exceeded", func(t *testing.T) {
+		root := tw.client.Tenant.Create().SetName("root").SaveX(context.Background())
+
+		limiter, err := limits.NewLimiter(limits.Config{MaxDepth: 1})
+		require.NoError(t, err)
+
+		tw.client.Use(limiter.Hook)
+
+		tw.send(t, OperationCreate, "", CommandData{Name: ptr("child"), ParentID: &root.ID})
+
+		_, result := tw.result(t)
+		assert.Equal(t, StatusFailed, result.Status)
+		assert.Equal(t, CodeLimitExceeded, result.Code, result.Error)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		msg := message.NewMessage(watermill.NewUUID(), []byte("not json"))
+
+		tw.messages <- msg
+
+		select {
+		case <-msg.Acked():
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "malformed command wasn't acked")
+		}
+
+		assert.Empty(t, tw.results, "there's no result without a command")
+	})
+}
+
+func TestWorkerAuthorization(t *testing.T) {
+	var authorization string
+
+	// stands in for the jwt and permissions middleware
+	middleware := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authorization = c.Request().Header.Get(echo.HeaderAuthorization)
+
+			var checker permissions.Checker
+
+			switch authorization {
+			case "":
+				return echo.ErrUnauthorized
+			case "Bearer allowed":
+				checker = permissions.DefaultAllowChecker
+			default:
+				checker = permissions.DefaultDenyChecker
+			}
+
+			ctx := context.WithValue(c.Request().Context(), permissions.CheckerCtxKey, checker)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+
+	tw := newTestWorker(t, "commands-worker-authorization", WithMiddleware([]echo.MiddlewareFunc{middleware}))
+
+	tests := []struct {
+		authorization string
+		status        string
+		code          string
+	}{
+		{authorization: "", status: StatusFailed, code: CodeUnauthenticated},
+		{authorization: "Bearer denied", status: StatusFailed, code: CodePermissionDenied},
+		{authorization: "Bearer allowed", status: StatusSucceeded},
+	}
+
+	for _, tt := range tests {
+		tw.send(t, OperationCreate, gidx.NullPrefixedID, CommandData{Name: ptr("tenant"), Authorization: tt.authorization})
+
+		_, result := tw.result(t)
+		assert.Equal(t, tt.status, result.Status, tt.authorization)
+		assert.Equal(t, tt.code, result.Code, tt.authorization)
+		assert.Equal(t, tt.authorization, authorization, "the command's authorization is passed to the middleware")
+	}
+
+	assert.Equal(t, 1, tw.client.Tenant.Query().CountX(context.Background()))
+}
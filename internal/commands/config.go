@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+const (
+	defaultTopic       = "tenant-commands"
+	defaultResultTopic = "tenant-command-results"
+)
+
+// Config stores the configuration for the command worker
+type Config struct {
+	// Topic is the events topic commands are consumed from, a command is
+	// published to <prefix>.events.<topic>.<create|update|delete>
+	Topic string `mapstructure:"topic"`
+	// ResultTopic is the events topic results are published to, a result is
+	// published to <prefix>.events.<result topic>.<create|update|delete>
+	ResultTopic string `mapstructure:"result_topic"`
+	// AllowUnauthenticated runs the worker without OIDC authentication, when
+	// every command is executed with the permissions checker's default
+	AllowUnauthenticated bool `mapstructure:"allow_unauthenticated"`
+}
+
+// MustViperFlags returns the cobra flags and viper config for the command worker
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.String("worker-topic", defaultTopic, "events topic tenant commands are consumed from")
+	viperx.MustBindFlag(v, "worker.topic", flags.Lookup("worker-topic"))
+
+	flags.String("worker-result-topic", defaultResultTopic, "events topic tenant command results are published to")
+	viperx.MustBindFlag(v, "worker.result_topic", flags.Lookup("worker-result-topic"))
+
+	flags.Bool("worker-allow-unauthenticated", false, "execute tenant commands without oidc authentication, for development")
+	viperx.MustBindFlag(v, "worker.allow_unauthenticated", flags.Lookup("worker-allow-unauthenticated"))
+}
+
+// withDefaults returns the config with defaults for unset values
+func (c Config) withDefaults() Config {
+	if c.Topic == "" {
+		c.Topic = defaultTopic
+	}
+
+	if c.ResultTopic == "" {
+		c.ResultTopic = defaultResultTopic
+	}
+
+	return c
+}
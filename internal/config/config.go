@@ -10,20 +10,52 @@ import (
 	"go.infratographer.com/x/otelx"
 
 	"go.infratographer.com/permissions-api/pkg/permissions"
+
+	"go.infratographer.com/tenant-api/internal/admission"
+	"go.infratographer.com/tenant-api/internal/checks"
+	"go.infratographer.com/tenant-api/internal/commands"
+	"go.infratographer.com/tenant-api/internal/database"
+	"go.infratographer.com/tenant-api/internal/enrichment"
+	"go.infratographer.com/tenant-api/internal/graphapi"
+	"go.infratographer.com/tenant-api/internal/grpcapi"
+	"go.infratographer.com/tenant-api/internal/kinds"
+	"go.infratographer.com/tenant-api/internal/limits"
+	"go.infratographer.com/tenant-api/internal/metrics"
+	"go.infratographer.com/tenant-api/internal/pubsub"
+	"go.infratographer.com/tenant-api/internal/tenantcache"
+	"go.infratographer.com/tenant-api/internal/validation"
+	"go.infratographer.com/tenant-api/internal/webhooks"
 )
 
 // AppConfig contains the application configuration structure.
 var AppConfig struct {
 	CRDB        crdbx.Config
+	DB          database.Config
 	Logging     loggingx.Config
 	Events      EventsConfig
 	Server      echox.Config
 	OIDC        echojwtx.AuthConfig
 	Tracing     otelx.Config
 	Permissions permissions.Config
+	Checks      checks.Config
+	Metrics     metrics.Config
+	GraphQL     graphapi.HandlerConfig
+	GRPC        grpcapi.Config
+	Cache       tenantcache.Config
+	Webhooks    webhooks.Config
+	Worker      commands.Config
+	Admission   admission.Config
+	Validation  validation.Config
+	Limits      limits.Config
+	Kinds       kinds.Config
 }
 
 // EventsConfig stores the configuration for a tenant-api event publisher
 type EventsConfig struct {
-	Publisher events.PublisherConfig
+	Publisher pubsub.PublisherConfig
+	// Publishers are additional backends changes are fanned out to, along
+	// with Publisher
+	Publishers []pubsub.PublisherConfig
+	Subscriber events.SubscriberConfig
+	Enrichment enrichment.Config
 }
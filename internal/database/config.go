@@ -0,0 +1,48 @@
+package database
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+const defaultSQLitePath = "tenant-api.db"
+
+// Config stores the configuration for the tenant-api database
+type Config struct {
+	// Driver selects the storage backend, either crdb or sqlite
+	Driver string       `mapstructure:"driver"`
+	SQLite SQLiteConfig `mapstructure:"sqlite"`
+	Reads  ReadsConfig  `mapstructure:"reads"`
+}
+
+// SQLiteConfig stores the configuration for the sqlite storage backend
+type SQLiteConfig struct {
+	// Path is the database file, it's created when it doesn't exist
+	Path string `mapstructure:"path"`
+}
+
+// ReadsConfig stores where queries which may be served slightly stale data
+// are read from. GraphQL queries use the read pool unless the request asks
+// for strong consistency, mutations always use the primary.
+type ReadsConfig struct {
+	// Mode is primary, replica or follower
+	Mode string `mapstructure:"mode"`
+	// URI is the connection uri of the read replica used by the replica mode
+	URI string `mapstructure:"uri"`
+}
+
+// MustViperFlags returns the cobra flags and viper config for the database
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.String("db-driver", DriverCRDB, "database driver to use, crdb or sqlite")
+	viperx.MustBindFlag(v, "db.driver", flags.Lookup("db-driver"))
+
+	flags.String("db-sqlite-path", defaultSQLitePath, "path of the sqlite database file")
+	viperx.MustBindFlag(v, "db.sqlite.path", flags.Lookup("db-sqlite-path"))
+
+	flags.String("db-reads-mode", ReadsPrimary, "where queries which may be stale are read from: primary, replica or follower (crdb follower reads)")
+	viperx.MustBindFlag(v, "db.reads.mode", flags.Lookup("db-reads-mode"))
+
+	flags.String("db-reads-uri", "", "connection uri of the read replica used by the replica reads mode")
+	viperx.MustBindFlag(v, "db.reads.uri", flags.Lookup("db-reads-uri"))
+}
@@ -0,0 +1,187 @@
+// Package database opens the tenant-api database for the configured storage
+// backend and runs its migrations.
+//
+// CockroachDB is used in production. SQLite is supported for edge installs and
+// local development, it stores everything in a single file and doesn't
+// require any other services.
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/XSAM/otelsql"
+	_ "github.com/mattn/go-sqlite3" // register the sqlite3 driver
+	"github.com/pressly/goose/v3"
+	"go.infratographer.com/x/crdbx"
+	"go.infratographer.com/x/zapx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.uber.org/zap"
+
+	dbm "go.infratographer.com/tenant-api/db"
+)
+
+const (
+	// DriverCRDB stores tenants in CockroachDB
+	DriverCRDB = "crdb"
+	// DriverSQLite stores tenants in a SQLite database file
+	DriverSQLite = "sqlite"
+
+	sqliteDriverName = "sqlite3"
+	sqliteParams     = "_fk=1&_journal_mode=WAL&_busy_timeout=5000"
+)
+
+// ErrUnknownDriver is returned when the configured driver isn't supported
+var ErrUnknownDriver = errors.New("unknown database driver")
+
+// DB is an open database and the ent dialect used to access it
+type DB struct {
+	*sql.DB
+
+	Driver  string
+	Dialect string
+	// Reads is the pool used for queries which may be stale, it's nil when
+	// all queries use the primary
+	Reads *sql.DB
+}
+
+// Open opens the database for the configured driver, crdb is used to
+// connect when the driver is crdb
+func Open(cfg Config, crdb crdbx.Config, tracing bool) (*DB, error) {
+	switch cfg.Driver {
+	case DriverCRDB, "":
+		readsCfg, separateReads, err := readsConfig(cfg.Reads, crdb)
+		if err != nil {
+			return nil, err
+		}
+
+		db, err := crdbx.NewDB(crdb, tracing)
+		if err != nil {
+			return nil, err
+		}
+
+		out := &DB{DB: db, Driver: DriverCRDB, Dialect: dialect.Postgres}
+
+		if separateReads {
+			out.Reads, err = crdbx.NewDB(readsCfg, tracing)
+			if err != nil {
+				db.Close()
+
+				return nil, fmt.Errorf("opening read pool: %w", err)
+			}
+		}
+
+		return out, nil
+	case DriverSQLite:
+		if mode := cfg.Reads.Mode; mode != "" && mode != ReadsPrimary {
+			return nil, fmt.Errorf("%w: %s with %s", ErrReadsUnsupported, mode, cfg.Driver)
+		}
+
+		db, err := openSQLite(cfg.SQLite, tracing)
+		if err != nil {
+			return nil, err
+		}
+
+		return &DB{DB: db, Driver: DriverSQLite, Dialect: dialect.SQLite}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDriver, cfg.Driver)
+	}
+}
+
+func openSQLite(cfg SQLiteConfig, tracing bool) (*sql.DB, error) {
+	driverName := sqliteDriverName
+
+	if tracing {
+		var err error
+
+		driverName, err = otelsql.Register(driverName, otelsql.WithAttributes(semconv.DBSystemSqlite))
+		if err != nil {
+			return nil, fmt.Errorf("failed creating sql tracer: %w", err)
+		}
+	}
+
+	db, err := sql.Open(driverName, sqliteDSN(cfg.Path))
+	if err != nil {
+		return nil, fmt.Errorf("failed opening database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("failed verifying database connection: %w", err)
+	}
+
+	// sqlite only allows a single writer, sharing one connection avoids
+	// transactions failing when they're upgraded to write locks
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
+// sqliteDSN returns the data source name for the path, paths which are
+// already a DSN are used as they are
+func sqliteDSN(path string) string {
+	if strings.HasPrefix(path, "file:") {
+		return path
+	}
+
+	return "file:" + path + "?" + sqliteParams
+}
+
+// EntDriver returns the ent driver for the database, stale reads are routed
+// to the read pool when there is one
+func (db *DB) EntDriver() dialect.Driver {
+	primary := entsql.OpenDB(db.Dialect, db.DB)
+
+	if db.Reads == nil {
+		return primary
+	}
+
+	return NewReadRouter(primary, entsql.OpenDB(db.Dialect, db.Reads))
+}
+
+// Close closes the primary and read pool
+func (db *DB) Close() error {
+	if db.Reads == nil {
+		return db.DB.Close()
+	}
+
+	return errors.Join(db.DB.Close(), db.Reads.Close())
+}
+
+// Migrate runs the goose migration command against the database using the
+// migrations for its driver
+func (db *DB) Migrate(command string, args ...string) error {
+	fsys, dir, gooseDialect := migrations(db.Driver)
+
+	goose.SetBaseFS(fsys)
+
+	if err := goose.SetDialect(gooseDialect); err != nil {
+		return err
+	}
+
+	return goose.Run(command, db.DB, dir, args...)
+}
+
+// SetMigrationLogger sets the logger used for migration output
+func SetMigrationLogger(logger *zap.SugaredLogger) {
+	goose.SetLogger(zapx.NewGooseLogger(logger.Named("goose")))
+}
+
+// MigrateUp migrates the database to the most recent version
+func (db *DB) MigrateUp() error {
+	return db.Migrate("up")
+}
+
+func migrations(driver string) (fs.FS, string, string) {
+	if driver == DriverSQLite {
+		return dbm.SQLiteMigrations, "migrations-sqlite", "sqlite3"
+	}
+
+	return dbm.Migrations, "migrations", "postgres"
+}
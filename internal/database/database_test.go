@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/crdbx"
+	"go.infratographer.com/x/gidx"
+
+	dbm "go.infratographer.com/tenant-api/db"
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+)
+
+func openSQLiteForTest(t *testing.T) *DB {
+	t.Helper()
+
+	db, err := Open(Config{Driver: DriverSQLite, SQLite: SQLiteConfig{Path: filepath.Join(t.TempDir(), "tenants.db")}}, crdbx.Config{}, false)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// describe returns the columns, indexes and foreign keys of every table.
+// Columns and indexes are compared without their position, since columns
+// added by a migration come after the ones ent would create them before.
+func describe(t *testing.T, db *sql.DB) map[string][]string {
+	t.Helper()
+
+	tables := map[string][]string{}
+
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'goose_db_version'")
+	require.NoError(t, err)
+
+	var names []string
+
+	for rows.Next() {
+		var name string
+
+		require.NoError(t, rows.Scan(&name))
+
+		names = append(names, name)
+	}
+
+	require.NoError(t, rows.Err())
+	rows.Close()
+
+	for _, name := range names {
+		for _, pragma := range []string{"table_info", "index_list", "foreign_key_list"} {
+			rows, err := db.Query(fmt.Sprintf("SELECT * FROM pragma_%s('%s')", pragma, name))
+			require.NoError(t, err)
+
+			cols, err := rows.Columns()
+			require.NoError(t, err)
+
+			for rows.Next() {
+				values := make([]interface{}, len(cols))
+				ptrs := make([]interface{}, len(cols))
+
+				for i := range values {
+					ptrs[i] = &values[i]
+				}
+
+				require.NoError(t, rows.Scan(ptrs...))
+
+				if pragma != "foreign_key_list" {
+					values = values[1:]
+				}
+
+				tables[name] = append(tables[name], fmt.Sprintf("%s %v", pragma, values))
+			}
+
+			require.NoError(t, rows.Err())
+			rows.Close()
+		}
+
+		sort.Strings(tables[name])
+	}
+
+	return tables
+}
+
+func TestSQLiteMigrationsMatchSchema(t *testing.T) {
+	ctx := context.Background()
+
+	migrated := openSQLiteForTest(t)
+	require.NoError(t, migrated.MigrateUp())
+
+	expected, err := sql.Open("sqlite3", "file:database-schema?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+
+	defer expected.Close()
+
+	require.NoError(t, ent.NewClient(ent.Driver(entsql.OpenDB(dialect.SQLite, expected))).Schema.Create(ctx))
+
+	want := describe(t, expected)
+	require.Contains(t, want, "tenants")
+
+	assert.Equal(t, want, describe(t, migrated.DB))
+
+	client := ent.NewClient(ent.Driver(entsql.OpenDB(migrated.Dialect, migrated.DB)))
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+
+	assert.Equal(t, root.ID, client.Tenant.GetX(ctx, child.ID).ParentTenantID)
+}
+
+// testSQLiteUpgradeKeepsParents migrates a database with a parent and child
+// tenant from a version to another, and checks the child still has its
+// parent. Rebuilding the tenants table drops its rows' parents, since goose
+// runs migrations in a transaction where foreign keys can't be disabled.
+func testSQLiteUpgradeKeepsParents(t *testing.T, from, to string) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	db := openSQLiteForTest(t)
+	require.NoError(t, db.Migrate("up-to", from))
+
+	parent, child := gidx.MustNewID("tnntten"), gidx.MustNewID("tnntten")
+
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO tenants (id, created_at, updated_at, name, parent_tenant_id) VALUES ($1, $2, $2, 'parent', NULL), ($3, $2, $2, 'child', $1)",
+		parent, time.Now(), child,
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Migrate("up-to", to))
+
+	var got sql.NullString
+
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT parent_tenant_id FROM tenants WHERE id = $1", child).Scan(&got))
+	assert.Equal(t, parent.String(), got.String)
+}
+
+func TestSQLiteMigrationsKeepParents(t *testing.T) {
+	t.Run("event sequence", func(t *testing.T) {
+		testSQLiteUpgradeKeepsParents(t, "20261019100720", "20261019104424")
+	})
+
+	t.Run("kind", func(t *testing.T) {
+		testSQLiteUpgradeKeepsParents(t, "20261019104424", "20261019121503")
+	})
+}
+
+func TestSQLiteMigrationsMatchVersions(t *testing.T) {
+	versions := func(fsys fs.FS, dir string) []string {
+		files, err := fs.Glob(fsys, dir+"/*.sql")
+		require.NoError(t, err)
+
+		for i, f := range files {
+			files[i] = filepath.Base(f)
+		}
+
+		return files
+	}
+
+	assert.Equal(t, versions(dbm.Migrations, "migrations"), versions(dbm.SQLiteMigrations, "migrations-sqlite"),
+		"every migration needs a sqlite migration with the same version")
+}
+
+func TestOpenUnknownDriver(t *testing.T) {
+	_, err := Open(Config{Driver: "mysql"}, crdbx.Config{}, false)
+	assert.ErrorIs(t, err, ErrUnknownDriver)
+}
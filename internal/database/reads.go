@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"entgo.io/ent/dialect"
+	"go.infratographer.com/x/crdbx"
+)
+
+const (
+	// ReadsPrimary sends every query to the primary database
+	ReadsPrimary = "primary"
+	// ReadsReplica sends queries which may be stale to a read replica
+	ReadsReplica = "replica"
+	// ReadsFollower sends queries which may be stale to the closest
+	// CockroachDB replica using follower reads
+	ReadsFollower = "follower"
+
+	followerReadsOption = "-c default_transaction_use_follower_reads=on"
+)
+
+var (
+	// ErrUnknownReadsMode is returned when the configured reads mode isn't supported
+	ErrUnknownReadsMode = errors.New("unknown reads mode")
+	// ErrReadsUnsupported is returned when the driver can't serve reads from replicas
+	ErrReadsUnsupported = errors.New("reads mode isn't supported by the database driver")
+	// ErrReplicaURIRequired is returned when the replica reads mode is used without a replica
+	ErrReplicaURIRequired = errors.New("replica reads mode requires a replica uri")
+)
+
+type staleReadsCtxKey struct{}
+
+// WithStaleReads marks the context as allowing queries to be served from
+// the read pool, which may lag behind the primary
+func WithStaleReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, staleReadsCtxKey{}, true)
+}
+
+// WithoutStaleReads marks the context as requiring queries to be served from
+// the primary, even when its parent context allows stale reads
+func WithoutStaleReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, staleReadsCtxKey{}, false)
+}
+
+// StaleReadsAllowed reports whether queries made with the context may be
+// served from the read pool
+func StaleReadsAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(staleReadsCtxKey{}).(bool)
+
+	return allowed
+}
+
+// ReadRouter is an ent driver which sends queries made with a context from
+// WithStaleReads to the read pool. Everything else, including all
+// transactions and writes, uses the primary.
+type ReadRouter struct {
+	dialect.Driver
+
+	reads dialect.Driver
+}
+
+// NewReadRouter returns a driver routing stale reads to reads
+func NewReadRouter(primary, reads dialect.Driver) *ReadRouter {
+	return &ReadRouter{Driver: primary, reads: reads}
+}
+
+// Query runs the query against the read pool when the context allows it
+func (r *ReadRouter) Query(ctx context.Context, query string, args, v any) error {
+	if StaleReadsAllowed(ctx) {
+		return r.reads.Query(ctx, query, args, v)
+	}
+
+	return r.Driver.Query(ctx, query, args, v)
+}
+
+// Close closes both the primary and read pool
+func (r *ReadRouter) Close() error {
+	return errors.Join(r.Driver.Close(), r.reads.Close())
+}
+
+// readsConfig returns the crdb config of the read pool, or false when reads
+// use the primary
+func readsConfig(cfg ReadsConfig, crdb crdbx.Config) (crdbx.Config, bool, error) {
+	switch cfg.Mode {
+	case ReadsPrimary, "":
+		return crdbx.Config{}, false, nil
+	case ReadsReplica:
+		if cfg.URI == "" {
+			return crdbx.Config{}, false, ErrReplicaURIRequired
+		}
+
+		reads := crdb
+		reads.URI = cfg.URI
+
+		return reads, true, nil
+	case ReadsFollower:
+		u, err := url.Parse(crdb.GetURI())
+		if err != nil {
+			return crdbx.Config{}, false, fmt.Errorf("parsing crdb uri: %w", err)
+		}
+
+		q := u.Query()
+
+		options := followerReadsOption
+		if existing := q.Get("options"); existing != "" {
+			options = existing + " " + options
+		}
+
+		q.Set("options", options)
+		u.RawQuery = q.Encode()
+
+		reads := crdb
+		reads.URI = u.String()
+
+		return reads, true, nil
+	default:
+		return crdbx.Config{}, false, fmt.Errorf("%w: %q", ErrUnknownReadsMode, cfg.Mode)
+	}
+}
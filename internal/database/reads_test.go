@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/crdbx"
+)
+
+func TestReadRouter(t *testing.T) {
+	ctx := context.Background()
+
+	open := func(name string) dialect.Driver {
+		drv, err := entsql.Open(dialect.SQLite, "file:"+name+"?mode=memory&cache=shared")
+		require.NoError(t, err)
+
+		require.NoError(t, drv.Exec(ctx, "CREATE TABLE pool (name text)", []any{}, nil))
+		require.NoError(t, drv.Exec(ctx, "INSERT INTO pool VALUES (?)", []any{name}, nil))
+
+		return drv
+	}
+
+	router := NewReadRouter(open("router-primary"), open("router-reads"))
+	defer router.Close()
+
+	pool := func(ctx context.Context) string {
+		var rows entsql.Rows
+
+		require.NoError(t, router.Query(ctx, "SELECT name FROM pool", []any{}, &rows))
+		defer rows.Close()
+
+		require.True(t, rows.Next())
+
+		var name string
+
+		require.NoError(t, rows.Scan(&name))
+
+		return name
+	}
+
+	assert.Equal(t, "router-primary", pool(ctx))
+	assert.Equal(t, "router-reads", pool(WithStaleReads(ctx)))
+
+	tx, err := router.Tx(WithStaleReads(ctx))
+	require.NoError(t, err)
+
+	require.NoError(t, tx.Exec(ctx, "INSERT INTO pool VALUES ('tx')", []any{}, nil))
+	require.NoError(t, tx.Commit())
+
+	var rows entsql.Rows
+
+	require.NoError(t, router.Query(ctx, "SELECT count(*) FROM pool", []any{}, &rows))
+	defer rows.Close()
+
+	count, err := entsql.ScanInt(rows)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "transactions should use the primary")
+}
+
+func TestReadsConfig(t *testing.T) {
+	crdb := crdbx.Config{URI: "postgresql://root@localhost:26257/tenants?sslmode=disable"}
+
+	_, separate, err := readsConfig(ReadsConfig{Mode: ReadsPrimary}, crdb)
+	require.NoError(t, err)
+	assert.False(t, separate)
+
+	_, _, err = readsConfig(ReadsConfig{Mode: ReadsReplica}, crdb)
+	assert.ErrorIs(t, err, ErrReplicaURIRequired)
+
+	replica, separate, err := readsConfig(ReadsConfig{Mode: ReadsReplica, URI: "postgresql://replica:26257/tenants"}, crdb)
+	require.NoError(t, err)
+	assert.True(t, separate)
+	assert.Equal(t, "postgresql://replica:26257/tenants", replica.GetURI())
+
+	follower, separate, err := readsConfig(ReadsConfig{Mode: ReadsFollower}, crdb)
+	require.NoError(t, err)
+	assert.True(t, separate)
+	assert.Equal(t, "postgresql://root@localhost:26257/tenants?options=-c+default_transaction_use_follower_reads%3Don&sslmode=disable", follower.GetURI())
+
+	_, _, err = readsConfig(ReadsConfig{Mode: "nearest"}, crdb)
+	assert.ErrorIs(t, err, ErrUnknownReadsMode)
+}
+
+func TestOpenSQLiteReadsUnsupported(t *testing.T) {
+	_, err := Open(Config{Driver: DriverSQLite, Reads: ReadsConfig{Mode: ReadsFollower}}, crdbx.Config{}, false)
+	assert.ErrorIs(t, err, ErrReadsUnsupported)
+}
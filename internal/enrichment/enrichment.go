@@ -0,0 +1,242 @@
+// Package enrichment adds ancestry and correlation metadata to published
+// tenant changes, so consumers can decide whether a change is relevant to
+// them and correlate it with the request which caused it without calling
+// back into tenant-api.
+//
+// The ancestors of a changed tenant are appended to the change's additional
+// subjects, nearest first, and the metadata is added to its subject fields.
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.infratographer.com/x/viperx"
+	"go.opentelemetry.io/otel/trace"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/schema"
+	"go.infratographer.com/tenant-api/internal/pubsub"
+)
+
+const (
+	// RootTenantIDField is the subject field holding the root of the changed
+	// tenant's hierarchy, which is the tenant itself for a root tenant
+	RootTenantIDField = "root_tenant_id"
+	// RequestIDField is the subject field holding the ID of the request which
+	// made the change
+	RequestIDField = "request_id"
+	// TraceIDField is the subject field holding the ID of the trace the change
+	// was made in
+	TraceIDField = "trace_id"
+	// ActorTypeField is the subject field holding the type of the actor which
+	// made the change, the prefix of its ID
+	ActorTypeField = "actor_type"
+
+	// ActorTypeUnknown is the actor type when there's no actor, or the actor
+	// isn't identified by a prefixed ID
+	ActorTypeUnknown = "unknown"
+
+	tenantSubjectType = "tenant"
+)
+
+// ErrMissingClient is returned when a tenant change is published without an
+// ent client in the context to load its ancestors with
+var ErrMissingClient = errors.New("no ent client in context to load ancestors")
+
+// Config stores the configuration for event enrichment
+type Config struct {
+	// Enabled adds ancestry and correlation metadata to published events
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// MustViperFlags returns the cobra flags and viper config for event enrichment
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Bool("events-enrichment-enabled", false, "add the ancestor chain, root tenant, request and trace IDs and actor type to published events")
+	viperx.MustBindFlag(v, "events.enrichment.enabled", flags.Lookup("events-enrichment-enabled"))
+}
+
+type requestIDCtxKey struct{}
+
+// Middleware adds the request's ID, set by echo's request ID middleware, to
+// the request context so it's added to the changes the request makes
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Response().Header().Get(echo.HeaderXRequestID)
+			if id == "" {
+				id = c.Request().Header.Get(echo.HeaderXRequestID)
+			}
+
+			if id != "" {
+				c.SetRequest(c.Request().WithContext(ContextWithRequestID(c.Request().Context(), id)))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// ContextWithRequestID returns a context holding the request ID
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID in the context, or an empty
+// string when there isn't one
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+
+	return id
+}
+
+type publisher struct {
+	pubsub.Publisher
+}
+
+// NewPublisher wraps a publisher to enrich changes before they're published.
+// Ancestors are loaded with the ent client in the context, which the event
+// hooks set to a client reading the state the mutation committed.
+func NewPublisher(p pubsub.Publisher) pubsub.Publisher {
+	return &publisher{Publisher: p}
+}
+
+// PublishChange enriches the change and publishes it
+func (p *publisher) PublishChange(ctx context.Context, subjectType string, change events.ChangeMessage) error {
+	change, err := Enrich(ctx, subjectType, change)
+	if err != nil {
+		return fmt.Errorf("enriching change: %w", err)
+	}
+
+	return p.Publisher.PublishChange(ctx, subjectType, change)
+}
+
+// Enrich returns the change with the ancestors of a changed tenant added to
+// its additional subjects and the correlation metadata in the context added
+// to its subject fields
+func Enrich(ctx context.Context, subjectType string, change events.ChangeMessage) (events.ChangeMessage, error) {
+	fields := make(map[string]string, len(change.SubjectFields)+4)
+
+	for k, v := range change.SubjectFields {
+		fields[k] = v
+	}
+
+	if subjectType == tenantSubjectType {
+		ancestors, err := tenantAncestors(ctx, change)
+		if err != nil {
+			return change, err
+		}
+
+		// copied so the caller's slice isn't appended to
+		additional := make([]gidx.PrefixedID, 0, len(change.AdditionalSubjectIDs)+len(ancestors))
+		additional = append(additional, change.AdditionalSubjectIDs...)
+
+		for _, id := range ancestors {
+			if !contains(additional, id) {
+				additional = append(additional, id)
+			}
+		}
+
+		change.AdditionalSubjectIDs = additional
+
+		root := change.SubjectID
+		if len(ancestors) != 0 {
+			root = ancestors[len(ancestors)-1]
+		}
+
+		fields[RootTenantIDField] = root.String()
+	}
+
+	if id := RequestIDFromContext(ctx); id != "" {
+		fields[RequestIDField] = id
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		fields[TraceIDField] = sc.TraceID().String()
+	}
+
+	fields[ActorTypeField] = actorType(ctx, change)
+
+	change.SubjectFields = fields
+
+	return change, nil
+}
+
+// tenantAncestors returns the ancestors of the changed tenant, nearest first.
+// The parent is taken from the change rather than loaded, a deleted tenant
+// can't be loaded.
+func tenantAncestors(ctx context.Context, change events.ChangeMessage) ([]gidx.PrefixedID, error) {
+	parent := gidx.NullPrefixedID
+
+	for _, id := range change.AdditionalSubjectIDs {
+		if id.Prefix() == schema.TenantPrefix {
+			parent = id
+
+			break
+		}
+	}
+
+	if parent == gidx.NullPrefixedID {
+		return nil, nil
+	}
+
+	client := ent.FromContext(ctx)
+	if client == nil {
+		return nil, ErrMissingClient
+	}
+
+	ancestors := []gidx.PrefixedID{}
+	seen := map[gidx.PrefixedID]bool{change.SubjectID: true}
+
+	// guard against a cycle in corrupt data looping forever
+	for id := parent; id != gidx.NullPrefixedID && !seen[id]; {
+		seen[id] = true
+		ancestors = append(ancestors, id)
+
+		t, err := client.Tenant.Get(ctx, id)
+
+		switch {
+		case err == nil:
+			id = t.ParentTenantID
+		case ent.IsNotFound(err):
+			// the ancestor was deleted concurrently, the chain ends with it
+			id = gidx.NullPrefixedID
+		default:
+			return nil, fmt.Errorf("loading ancestor %s: %w", id, err)
+		}
+	}
+
+	return ancestors, nil
+}
+
+// actorType returns the prefix of the actor's ID
+func actorType(ctx context.Context, change events.ChangeMessage) string {
+	actor := change.ActorID.String()
+	if actor == "" {
+		actor, _ = ctx.Value(echojwtx.ActorCtxKey).(string)
+	}
+
+	id, err := gidx.Parse(actor)
+	if err != nil || id == gidx.NullPrefixedID {
+		return ActorTypeUnknown
+	}
+
+	return id.Prefix()
+}
+
+func contains(ids []gidx.PrefixedID, id gidx.PrefixedID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+
+	return false
+}
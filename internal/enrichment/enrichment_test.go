@@ -0,0 +1,161 @@
+package enrichment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel/trace"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+	"go.infratographer.com/tenant-api/internal/ent/generated/eventhooks"
+	"go.infratographer.com/tenant-api/internal/pubsub"
+)
+
+// recorder records the changes published to it
+type recorder struct {
+	mu      sync.Mutex
+	changes []events.ChangeMessage
+}
+
+func (r *recorder) PublishChange(_ context.Context, _ string, change events.ChangeMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.changes = append(r.changes, change)
+
+	return nil
+}
+
+func (r *recorder) last(t *testing.T) events.ChangeMessage {
+	t.Helper()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	require.NotEmpty(t, r.changes)
+
+	return r.changes[len(r.changes)-1]
+}
+
+func newTestClient(t *testing.T, name string) (*ent.Client, *recorder) {
+	t.Helper()
+
+	rec := &recorder{}
+
+	client := enttest.Open(t, "sqlite3", "file:"+name+"?mode=memory&cache=shared&_fk=1",
+		enttest.WithOptions(ent.EventsPublisher(NewPublisher(rec))),
+	)
+	t.Cleanup(func() { client.Close() })
+
+	eventhooks.EventHooks(client)
+
+	return client, rec
+}
+
+func TestPublisher(t *testing.T) {
+	ctx := context.Background()
+
+	client, rec := newTestClient(t, "enrichment-publisher")
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+
+	change := rec.last(t)
+	assert.Empty(t, change.AdditionalSubjectIDs)
+	assert.Equal(t, root.ID.String(), change.SubjectFields[RootTenantIDField], "a root tenant is its own root")
+
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+
+	reqCtx := ContextWithRequestID(ctx, "request-1")
+	reqCtx = context.WithValue(reqCtx, echojwtx.ActorCtxKey, "idntusr-actor")
+	reqCtx = trace.ContextWithSpanContext(reqCtx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	grandchild := client.Tenant.Create().SetName("grandchild").SetParent(child).SaveX(reqCtx)
+
+	change = rec.last(t)
+	assert.Equal(t, grandchild.ID, change.SubjectID)
+	assert.Equal(t, []gidx.PrefixedID{child.ID, root.ID}, change.AdditionalSubjectIDs)
+	assert.Equal(t, map[string]string{
+		RootTenantIDField:    root.ID.String(),
+		RequestIDField:       "request-1",
+		TraceIDField:         traceID.String(),
+		ActorTypeField:       "idntusr",
+		pubsub.SequenceField: "1",
+		"kind":               "TENANT",
+	}, change.SubjectFields)
+
+	t.Run("delete in a transaction", func(t *testing.T) {
+		// the ancestors are loaded once the transaction is committed
+		err := client.WithTx(ctx, func(tx *ent.Tx) error {
+			return tx.Tenant.DeleteOneID(grandchild.ID).Exec(ctx)
+		})
+		require.NoError(t, err)
+
+		change := rec.last(t)
+		assert.Equal(t, string(events.DeleteChangeType), change.EventType)
+		assert.Equal(t, []gidx.PrefixedID{child.ID, root.ID}, change.AdditionalSubjectIDs)
+		assert.Equal(t, root.ID.String(), change.SubjectFields[RootTenantIDField])
+		assert.Equal(t, ActorTypeUnknown, change.SubjectFields[ActorTypeField])
+	})
+}
+
+func TestEnrich(t *testing.T) {
+	change := events.ChangeMessage{
+		SubjectID:            "tnntten-child",
+		EventType:            string(events.UpdateChangeType),
+		AdditionalSubjectIDs: []gidx.PrefixedID{"tnntten-parent"},
+		SubjectFields:        map[string]string{"name": "child"},
+	}
+
+	t.Run("requires a client", func(t *testing.T) {
+		_, err := Enrich(context.Background(), "tenant", change)
+		assert.ErrorIs(t, err, ErrMissingClient)
+	})
+
+	t.Run("other subjects", func(t *testing.T) {
+		enriched, err := Enrich(context.Background(), "location", change)
+		require.NoError(t, err)
+
+		assert.Equal(t, change.AdditionalSubjectIDs, enriched.AdditionalSubjectIDs)
+		assert.Equal(t, map[string]string{"name": "child", ActorTypeField: ActorTypeUnknown}, enriched.SubjectFields)
+		assert.Len(t, change.SubjectFields, 1, "the change's fields aren't modified")
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+
+	var requestID string
+
+	e.GET("/", func(c echo.Context) error {
+		requestID = RequestIDFromContext(c.Request().Context())
+
+		return c.NoContent(http.StatusNoContent)
+	}, Middleware())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderXRequestID, "request-1")
+
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "request-1", requestID)
+}
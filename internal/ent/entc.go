@@ -22,15 +22,14 @@ import (
 	"entgo.io/contrib/entgql"
 	"entgo.io/ent/entc"
 	"entgo.io/ent/entc/gen"
+	"entgo.io/ent/schema/field"
 	"go.infratographer.com/x/entx"
-	"go.infratographer.com/x/events"
 )
 
 func main() {
 	xExt, err := entx.NewExtension(
 		entx.WithFederation(),
 		entx.WithJSONScalar(),
-		entx.WithEventHooks(),
 	)
 	if err != nil {
 		log.Fatalf("creating entx extension: %v", err)
@@ -55,9 +54,13 @@ func main() {
 			gqlExt,
 		),
 		entc.TemplateDir("./internal/ent/templates"),
-		entc.FeatureNames("intercept"),
+		entc.FeatureNames("intercept", "sql/execquery"),
 		entc.Dependency(
-			entc.DependencyType(&events.Publisher{}),
+			entc.DependencyName("EventsPublisher"),
+			entc.DependencyTypeInfo(&field.TypeInfo{
+				Ident:   "pubsub.Publisher",
+				PkgPath: "go.infratographer.com/tenant-api/internal/pubsub",
+			}),
 		),
 	}
 
@@ -40,6 +40,12 @@ func TenantHooks() []ent.Hook {
 					if !ok {
 						return nil, fmt.Errorf("object doesn't have an id %s", objID)
 					}
+					// every event gets the next sequence number of the object
+					if m.Op().Is(ent.OpCreate) {
+						m.SetEventSequence(1)
+					} else {
+						m.AddEventSequence(1)
+					}
 
 					changeset := []events.FieldChange{}
 					cv_created_at := ""
@@ -162,6 +168,28 @@ func TenantHooks() []ent.Hook {
 						})
 					}
 
+					cv_kind := ""
+					kind, ok := m.Kind()
+
+					if ok {
+						cv_kind = fmt.Sprintf("%s", fmt.Sprint(kind))
+						pv_kind := ""
+						if !m.Op().Is(ent.OpCreate) {
+							ov, err := m.OldKind(ctx)
+							if err != nil {
+								pv_kind = "<unknown>"
+							} else {
+								pv_kind = fmt.Sprintf("%s", fmt.Sprint(ov))
+							}
+						}
+
+						changeset = append(changeset, events.FieldChange{
+							Field:         "kind",
+							PreviousValue: pv_kind,
+							CurrentValue:  cv_kind,
+						})
+					}
+
 					msg := events.ChangeMessage{
 						EventType:            eventType(m.Op()),
 						SubjectID:            objID,
@@ -176,8 +204,28 @@ func TenantHooks() []ent.Hook {
 						return retValue, err
 					}
 
-					if err := m.EventsPublisher.PublishChange(ctx, "tenant", msg); err != nil {
-						return nil, fmt.Errorf("failed to publish change: %w", err)
+					// the sequence is read back once the mutation has incremented it,
+					// along with the subject fields which weren't changed
+					if obj, ok := retValue.(*generated.Tenant); ok {
+						msg.SubjectFields = map[string]string{
+							"event_sequence": fmt.Sprint(obj.EventSequence),
+							"kind":           fmt.Sprint(obj.Kind),
+						}
+					}
+
+					// the change is published once the mutation is committed, so a
+					// change which is rolled back is never published. Publishers can load
+					// related objects with the client in the context, which reads the
+					// committed state.
+					err = m.Client().AfterCommit(func(client *generated.Client) error {
+						if err := m.EventsPublisher.PublishChange(generated.NewContext(ctx, client), "tenant", msg); err != nil {
+							return fmt.Errorf("failed to publish change: %w", err)
+						}
+
+						return nil
+					})
+					if err != nil {
+						return nil, err
 					}
 
 					return retValue, nil
@@ -197,6 +245,8 @@ func TenantHooks() []ent.Hook {
 						return nil, fmt.Errorf("object doesn't have an id %s", objID)
 					}
 
+					// the mutation's client is bound to its transaction, so when the delete
+					// runs in one the snapshot is the object that's deleted
 					dbObj, err := m.Client().Tenant.Get(ctx, objID)
 					if err != nil {
 						return nil, fmt.Errorf("failed to load object to get values for event, err %w", err)
@@ -206,6 +256,51 @@ func TenantHooks() []ent.Hook {
 						additionalSubjects = append(additionalSubjects, dbObj.ParentTenantID)
 					}
 
+					// the final values of the deleted object are sent as the previous
+					// values, so consumers can tell what was deleted
+					changeset := []events.FieldChange{}
+					pv_created_at := dbObj.CreatedAt.Format(time.RFC3339)
+
+					changeset = append(changeset, events.FieldChange{
+						Field:         "created_at",
+						PreviousValue: pv_created_at,
+					})
+
+					pv_updated_at := dbObj.UpdatedAt.Format(time.RFC3339)
+
+					changeset = append(changeset, events.FieldChange{
+						Field:         "updated_at",
+						PreviousValue: pv_updated_at,
+					})
+
+					pv_name := fmt.Sprint(dbObj.Name)
+
+					changeset = append(changeset, events.FieldChange{
+						Field:         "name",
+						PreviousValue: pv_name,
+					})
+
+					pv_description := fmt.Sprint(dbObj.Description)
+
+					changeset = append(changeset, events.FieldChange{
+						Field:         "description",
+						PreviousValue: pv_description,
+					})
+
+					pv_parent_tenant_id := fmt.Sprint(dbObj.ParentTenantID)
+
+					changeset = append(changeset, events.FieldChange{
+						Field:         "parent_tenant_id",
+						PreviousValue: pv_parent_tenant_id,
+					})
+
+					pv_kind := fmt.Sprint(dbObj.Kind)
+
+					changeset = append(changeset, events.FieldChange{
+						Field:         "kind",
+						PreviousValue: pv_kind,
+					})
+
 					// we have all the info we need, now complete the mutation before we process the event
 					retValue, err := next.Mutate(ctx, m)
 					if err != nil {
@@ -217,10 +312,29 @@ func TenantHooks() []ent.Hook {
 						SubjectID:            objID,
 						AdditionalSubjectIDs: additionalSubjects,
 						Timestamp:            time.Now().UTC(),
+						FieldChanges:         changeset,
 					}
 
-					if err := m.EventsPublisher.PublishChange(ctx, "tenant", msg); err != nil {
-						return nil, fmt.Errorf("failed to publish change: %w", err)
+					msg.SubjectFields = map[string]string{
+						// the deleted object's sequence isn't stored, its delete event is the
+						// one after its last
+						"event_sequence": fmt.Sprint(dbObj.EventSequence + 1),
+						"kind":           fmt.Sprint(dbObj.Kind),
+					}
+
+					// the change is published once the mutation is committed, so a
+					// change which is rolled back is never published. Publishers can load
+					// related objects with the client in the context, which reads the
+					// committed state.
+					err = m.Client().AfterCommit(func(client *generated.Client) error {
+						if err := m.EventsPublisher.PublishChange(generated.NewContext(ctx, client), "tenant", msg); err != nil {
+							return fmt.Errorf("failed to publish change: %w", err)
+						}
+
+						return nil
+					})
+					if err != nil {
+						return nil, err
 					}
 
 					return retValue, nil
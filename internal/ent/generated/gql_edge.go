@@ -50,3 +50,32 @@ func (t *Tenant) Children(
 	}
 	return t.QueryChildren().Paginate(ctx, after, first, before, last, opts...)
 }
+
+func (wd *WebhookDelivery) Subscription(ctx context.Context) (*WebhookSubscription, error) {
+	result, err := wd.Edges.SubscriptionOrErr()
+	if IsNotLoaded(err) {
+		result, err = wd.QuerySubscription().Only(ctx)
+	}
+	return result, err
+}
+
+func (ws *WebhookSubscription) Deliveries(
+	ctx context.Context, after *Cursor, first *int, before *Cursor, last *int, orderBy *WebhookDeliveryOrder, where *WebhookDeliveryWhereInput,
+) (*WebhookDeliveryConnection, error) {
+	opts := []WebhookDeliveryPaginateOption{
+		WithWebhookDeliveryOrder(orderBy),
+		WithWebhookDeliveryFilter(where.Filter),
+	}
+	alias := graphql.GetFieldContext(ctx).Field.Alias
+	totalCount, hasTotalCount := ws.Edges.totalCount[0][alias]
+	if nodes, err := ws.NamedDeliveries(alias); err == nil || hasTotalCount {
+		pager, err := newWebhookDeliveryPager(opts, last != nil)
+		if err != nil {
+			return nil, err
+		}
+		conn := &WebhookDeliveryConnection{Edges: []*WebhookDeliveryEdge{}, TotalCount: totalCount}
+		conn.build(nodes, pager, after, first, before, last)
+		return conn, nil
+	}
+	return ws.QueryDeliveries().Paginate(ctx, after, first, before, last, opts...)
+}
@@ -17,6 +17,7 @@
 package generated
 
 import (
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
 	"go.infratographer.com/x/gidx"
 )
 
@@ -24,6 +25,7 @@ import (
 type CreateTenantInput struct {
 	Name        string
 	Description *string
+	Kind        *tenant.Kind
 	ParentID    *gidx.PrefixedID
 }
 
@@ -33,6 +35,9 @@ func (i *CreateTenantInput) Mutate(m *TenantMutation) {
 	if v := i.Description; v != nil {
 		m.SetDescription(*v)
 	}
+	if v := i.Kind; v != nil {
+		m.SetKind(*v)
+	}
 	if v := i.ParentID; v != nil {
 		m.SetParentID(*v)
 	}
@@ -75,3 +80,69 @@ func (c *TenantUpdateOne) SetInput(i UpdateTenantInput) *TenantUpdateOne {
 	i.Mutate(c.Mutation())
 	return c
 }
+
+// CreateWebhookSubscriptionInput represents a mutation input for creating webhooksubscriptions.
+type CreateWebhookSubscriptionInput struct {
+	URL        string
+	Secret     string
+	EventTypes []string
+	TenantID   *gidx.PrefixedID
+}
+
+// Mutate applies the CreateWebhookSubscriptionInput on the WebhookSubscriptionMutation builder.
+func (i *CreateWebhookSubscriptionInput) Mutate(m *WebhookSubscriptionMutation) {
+	m.SetURL(i.URL)
+	m.SetSecret(i.Secret)
+	if v := i.EventTypes; v != nil {
+		m.SetEventTypes(v)
+	}
+	if v := i.TenantID; v != nil {
+		m.SetTenantID(*v)
+	}
+}
+
+// SetInput applies the change-set in the CreateWebhookSubscriptionInput on the WebhookSubscriptionCreate builder.
+func (c *WebhookSubscriptionCreate) SetInput(i CreateWebhookSubscriptionInput) *WebhookSubscriptionCreate {
+	i.Mutate(c.Mutation())
+	return c
+}
+
+// UpdateWebhookSubscriptionInput represents a mutation input for updating webhooksubscriptions.
+type UpdateWebhookSubscriptionInput struct {
+	URL              *string
+	Secret           *string
+	ClearEventTypes  bool
+	EventTypes       []string
+	AppendEventTypes []string
+}
+
+// Mutate applies the UpdateWebhookSubscriptionInput on the WebhookSubscriptionMutation builder.
+func (i *UpdateWebhookSubscriptionInput) Mutate(m *WebhookSubscriptionMutation) {
+	if v := i.URL; v != nil {
+		m.SetURL(*v)
+	}
+	if v := i.Secret; v != nil {
+		m.SetSecret(*v)
+	}
+	if i.ClearEventTypes {
+		m.ClearEventTypes()
+	}
+	if v := i.EventTypes; v != nil {
+		m.SetEventTypes(v)
+	}
+	if i.AppendEventTypes != nil {
+		m.AppendEventTypes(i.EventTypes)
+	}
+}
+
+// SetInput applies the change-set in the UpdateWebhookSubscriptionInput on the WebhookSubscriptionUpdate builder.
+func (c *WebhookSubscriptionUpdate) SetInput(i UpdateWebhookSubscriptionInput) *WebhookSubscriptionUpdate {
+	i.Mutate(c.Mutation())
+	return c
+}
+
+// SetInput applies the change-set in the UpdateWebhookSubscriptionInput on the WebhookSubscriptionUpdateOne builder.
+func (c *WebhookSubscriptionUpdateOne) SetInput(i UpdateWebhookSubscriptionInput) *WebhookSubscriptionUpdateOne {
+	i.Mutate(c.Mutation())
+	return c
+}
@@ -24,6 +24,8 @@ import (
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/hashicorp/go-multierror"
 	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/ent/generated/webhookdelivery"
+	"go.infratographer.com/tenant-api/internal/ent/generated/webhooksubscription"
 	"go.infratographer.com/x/gidx"
 )
 
@@ -35,6 +37,12 @@ type Noder interface {
 // IsNode implements the Node interface check for GQLGen.
 func (n *Tenant) IsNode() {}
 
+// IsNode implements the Node interface check for GQLGen.
+func (n *WebhookDelivery) IsNode() {}
+
+// IsNode implements the Node interface check for GQLGen.
+func (n *WebhookSubscription) IsNode() {}
+
 var errNodeInvalidID = &NotFoundError{"node"}
 
 // NodeOption allows configuring the Noder execution using functional options.
@@ -109,6 +117,38 @@ func (c *Client) noder(ctx context.Context, table string, id gidx.PrefixedID) (N
 			return nil, err
 		}
 		return n, nil
+	case webhookdelivery.Table:
+		var uid gidx.PrefixedID
+		if err := uid.UnmarshalGQL(id); err != nil {
+			return nil, err
+		}
+		query := c.WebhookDelivery.Query().
+			Where(webhookdelivery.ID(uid))
+		query, err := query.CollectFields(ctx, "WebhookDelivery")
+		if err != nil {
+			return nil, err
+		}
+		n, err := query.Only(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	case webhooksubscription.Table:
+		var uid gidx.PrefixedID
+		if err := uid.UnmarshalGQL(id); err != nil {
+			return nil, err
+		}
+		query := c.WebhookSubscription.Query().
+			Where(webhooksubscription.ID(uid))
+		query, err := query.CollectFields(ctx, "WebhookSubscription")
+		if err != nil {
+			return nil, err
+		}
+		n, err := query.Only(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
 	default:
 		return nil, fmt.Errorf("cannot resolve noder from table %q: %w", table, errNodeInvalidID)
 	}
@@ -198,6 +238,38 @@ func (c *Client) noders(ctx context.Context, table string, ids []gidx.PrefixedID
 				*noder = node
 			}
 		}
+	case webhookdelivery.Table:
+		query := c.WebhookDelivery.Query().
+			Where(webhookdelivery.IDIn(ids...))
+		query, err := query.CollectFields(ctx, "WebhookDelivery")
+		if err != nil {
+			return nil, err
+		}
+		nodes, err := query.All(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			for _, noder := range idmap[node.ID] {
+				*noder = node
+			}
+		}
+	case webhooksubscription.Table:
+		query := c.WebhookSubscription.Query().
+			Where(webhooksubscription.IDIn(ids...))
+		query, err := query.CollectFields(ctx, "WebhookSubscription")
+		if err != nil {
+			return nil, err
+		}
+		nodes, err := query.All(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			for _, noder := range idmap[node.ID] {
+				*noder = node
+			}
+		}
 	default:
 		return nil, fmt.Errorf("cannot resolve noders from table %q: %w", table, errNodeInvalidID)
 	}
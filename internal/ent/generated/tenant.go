@@ -43,6 +43,10 @@ type Tenant struct {
 	Description string `json:"description,omitempty"`
 	// The ID of the parent tenant for the tenant.
 	ParentTenantID gidx.PrefixedID `json:"parent_tenant_id,omitempty"`
+	// The kind of the tenant, the layer of the hierarchy it's in. The kinds a tenant may be a child of are configurable.
+	Kind tenant.Kind `json:"kind,omitempty"`
+	// The sequence number of the last event published for the tenant, incremented by the event hooks.
+	EventSequence int64 `json:"event_sequence,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the TenantQuery when eager-loading is set.
 	Edges        TenantEdges `json:"edges"`
@@ -93,7 +97,9 @@ func (*Tenant) scanValues(columns []string) ([]any, error) {
 		switch columns[i] {
 		case tenant.FieldID, tenant.FieldParentTenantID:
 			values[i] = new(gidx.PrefixedID)
-		case tenant.FieldName, tenant.FieldDescription:
+		case tenant.FieldEventSequence:
+			values[i] = new(sql.NullInt64)
+		case tenant.FieldName, tenant.FieldDescription, tenant.FieldKind:
 			values[i] = new(sql.NullString)
 		case tenant.FieldCreatedAt, tenant.FieldUpdatedAt:
 			values[i] = new(sql.NullTime)
@@ -148,6 +154,18 @@ func (t *Tenant) assignValues(columns []string, values []any) error {
 			} else if value != nil {
 				t.ParentTenantID = *value
 			}
+		case tenant.FieldKind:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field kind", values[i])
+			} else if value.Valid {
+				t.Kind = tenant.Kind(value.String)
+			}
+		case tenant.FieldEventSequence:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field event_sequence", values[i])
+			} else if value.Valid {
+				t.EventSequence = value.Int64
+			}
 		default:
 			t.selectValues.Set(columns[i], values[i])
 		}
@@ -208,6 +226,12 @@ func (t *Tenant) String() string {
 	builder.WriteString(", ")
 	builder.WriteString("parent_tenant_id=")
 	builder.WriteString(fmt.Sprintf("%v", t.ParentTenantID))
+	builder.WriteString(", ")
+	builder.WriteString("kind=")
+	builder.WriteString(fmt.Sprintf("%v", t.Kind))
+	builder.WriteString(", ")
+	builder.WriteString("event_sequence=")
+	builder.WriteString(fmt.Sprintf("%v", t.EventSequence))
 	builder.WriteByte(')')
 	return builder.String()
 }
@@ -221,6 +245,13 @@ func (t Tenant) IsMetadataNode() {}
 // IsResourceOwner implements interface for ResourceOwner
 func (t Tenant) IsResourceOwner() {}
 
+// ChildrenTotalCount returns the total count of the children edge collected
+// for the graphql field alias, and whether it was collected.
+func (t *Tenant) ChildrenTotalCount(alias string) (int, bool) {
+	n, ok := t.Edges.totalCount[1][alias]
+	return n, ok
+}
+
 // NamedChildren returns the Children named value or an error if the edge was not
 // loaded in eager-loading with this name.
 func (t *Tenant) NamedChildren(name string) ([]*Tenant, error) {
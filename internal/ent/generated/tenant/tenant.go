@@ -17,6 +17,9 @@
 package tenant
 
 import (
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 
 	"entgo.io/ent/dialect/sql"
@@ -39,6 +42,10 @@ const (
 	FieldDescription = "description"
 	// FieldParentTenantID holds the string denoting the parent_tenant_id field in the database.
 	FieldParentTenantID = "parent_tenant_id"
+	// FieldKind holds the string denoting the kind field in the database.
+	FieldKind = "kind"
+	// FieldEventSequence holds the string denoting the event_sequence field in the database.
+	FieldEventSequence = "event_sequence"
 	// EdgeParent holds the string denoting the parent edge name in mutations.
 	EdgeParent = "parent"
 	// EdgeChildren holds the string denoting the children edge name in mutations.
@@ -63,6 +70,8 @@ var Columns = []string{
 	FieldName,
 	FieldDescription,
 	FieldParentTenantID,
+	FieldKind,
+	FieldEventSequence,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -82,10 +91,45 @@ var (
 	DefaultUpdatedAt func() time.Time
 	// UpdateDefaultUpdatedAt holds the default value on update for the "updated_at" field.
 	UpdateDefaultUpdatedAt func() time.Time
+	// NameValidator is a validator for the "name" field. It is called by the builders before save.
+	NameValidator func(string) error
+	// DescriptionValidator is a validator for the "description" field. It is called by the builders before save.
+	DescriptionValidator func(string) error
+	// DefaultEventSequence holds the default value on creation for the "event_sequence" field.
+	DefaultEventSequence int64
 	// DefaultID holds the default value on creation for the "id" field.
 	DefaultID func() gidx.PrefixedID
 )
 
+// Kind defines the type for the "kind" enum field.
+type Kind string
+
+// KindTenant is the default value of the Kind enum.
+const DefaultKind = KindTenant
+
+// Kind values.
+const (
+	KindTenant       Kind = "TENANT"
+	KindOrganization Kind = "ORGANIZATION"
+	KindBusinessUnit Kind = "BUSINESS_UNIT"
+	KindProject      Kind = "PROJECT"
+	KindEnvironment  Kind = "ENVIRONMENT"
+)
+
+func (k Kind) String() string {
+	return string(k)
+}
+
+// KindValidator is a validator for the "kind" field enum values. It is called by the builders before save.
+func KindValidator(k Kind) error {
+	switch k {
+	case KindTenant, KindOrganization, KindBusinessUnit, KindProject, KindEnvironment:
+		return nil
+	default:
+		return fmt.Errorf("tenant: invalid enum value for kind field: %q", k)
+	}
+}
+
 // OrderOption defines the ordering options for the Tenant queries.
 type OrderOption func(*sql.Selector)
 
@@ -119,6 +163,16 @@ func ByParentTenantID(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldParentTenantID, opts...).ToFunc()
 }
 
+// ByKind orders the results by the kind field.
+func ByKind(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldKind, opts...).ToFunc()
+}
+
+// ByEventSequence orders the results by the event_sequence field.
+func ByEventSequence(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldEventSequence, opts...).ToFunc()
+}
+
 // ByParentField orders the results by parent field.
 func ByParentField(field string, opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
@@ -153,3 +207,21 @@ func newChildrenStep() *sqlgraph.Step {
 		sqlgraph.Edge(sqlgraph.O2M, false, ChildrenTable, ChildrenColumn),
 	)
 }
+
+// MarshalGQL implements graphql.Marshaler interface.
+func (e Kind) MarshalGQL(w io.Writer) {
+	io.WriteString(w, strconv.Quote(e.String()))
+}
+
+// UnmarshalGQL implements graphql.Unmarshaler interface.
+func (e *Kind) UnmarshalGQL(val interface{}) error {
+	str, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("enum %T must be a string", val)
+	}
+	*e = Kind(str)
+	if err := KindValidator(*e); err != nil {
+		return fmt.Errorf("%s is not a valid Kind", str)
+	}
+	return nil
+}
@@ -95,6 +95,11 @@ func ParentTenantID(v gidx.PrefixedID) predicate.Tenant {
 	return predicate.Tenant(sql.FieldEQ(FieldParentTenantID, v))
 }
 
+// EventSequence applies equality check predicate on the "event_sequence" field. It's identical to EventSequenceEQ.
+func EventSequence(v int64) predicate.Tenant {
+	return predicate.Tenant(sql.FieldEQ(FieldEventSequence, v))
+}
+
 // CreatedAtEQ applies the EQ predicate on the "created_at" field.
 func CreatedAtEQ(v time.Time) predicate.Tenant {
 	return predicate.Tenant(sql.FieldEQ(FieldCreatedAt, v))
@@ -395,6 +400,66 @@ func ParentTenantIDContainsFold(v gidx.PrefixedID) predicate.Tenant {
 	return predicate.Tenant(sql.FieldContainsFold(FieldParentTenantID, vc))
 }
 
+// KindEQ applies the EQ predicate on the "kind" field.
+func KindEQ(v Kind) predicate.Tenant {
+	return predicate.Tenant(sql.FieldEQ(FieldKind, v))
+}
+
+// KindNEQ applies the NEQ predicate on the "kind" field.
+func KindNEQ(v Kind) predicate.Tenant {
+	return predicate.Tenant(sql.FieldNEQ(FieldKind, v))
+}
+
+// KindIn applies the In predicate on the "kind" field.
+func KindIn(vs ...Kind) predicate.Tenant {
+	return predicate.Tenant(sql.FieldIn(FieldKind, vs...))
+}
+
+// KindNotIn applies the NotIn predicate on the "kind" field.
+func KindNotIn(vs ...Kind) predicate.Tenant {
+	return predicate.Tenant(sql.FieldNotIn(FieldKind, vs...))
+}
+
+// EventSequenceEQ applies the EQ predicate on the "event_sequence" field.
+func EventSequenceEQ(v int64) predicate.Tenant {
+	return predicate.Tenant(sql.FieldEQ(FieldEventSequence, v))
+}
+
+// EventSequenceNEQ applies the NEQ predicate on the "event_sequence" field.
+func EventSequenceNEQ(v int64) predicate.Tenant {
+	return predicate.Tenant(sql.FieldNEQ(FieldEventSequence, v))
+}
+
+// EventSequenceIn applies the In predicate on the "event_sequence" field.
+func EventSequenceIn(vs ...int64) predicate.Tenant {
+	return predicate.Tenant(sql.FieldIn(FieldEventSequence, vs...))
+}
+
+// EventSequenceNotIn applies the NotIn predicate on the "event_sequence" field.
+func EventSequenceNotIn(vs ...int64) predicate.Tenant {
+	return predicate.Tenant(sql.FieldNotIn(FieldEventSequence, vs...))
+}
+
+// EventSequenceGT applies the GT predicate on the "event_sequence" field.
+func EventSequenceGT(v int64) predicate.Tenant {
+	return predicate.Tenant(sql.FieldGT(FieldEventSequence, v))
+}
+
+// EventSequenceGTE applies the GTE predicate on the "event_sequence" field.
+func EventSequenceGTE(v int64) predicate.Tenant {
+	return predicate.Tenant(sql.FieldGTE(FieldEventSequence, v))
+}
+
+// EventSequenceLT applies the LT predicate on the "event_sequence" field.
+func EventSequenceLT(v int64) predicate.Tenant {
+	return predicate.Tenant(sql.FieldLT(FieldEventSequence, v))
+}
+
+// EventSequenceLTE applies the LTE predicate on the "event_sequence" field.
+func EventSequenceLTE(v int64) predicate.Tenant {
+	return predicate.Tenant(sql.FieldLTE(FieldEventSequence, v))
+}
+
 // HasParent applies the HasEdge predicate on the "parent" edge.
 func HasParent() predicate.Tenant {
 	return predicate.Tenant(func(s *sql.Selector) {
@@ -97,6 +97,34 @@ func (tc *TenantCreate) SetNillableParentTenantID(gi *gidx.PrefixedID) *TenantCr
 	return tc
 }
 
+// SetKind sets the "kind" field.
+func (tc *TenantCreate) SetKind(t tenant.Kind) *TenantCreate {
+	tc.mutation.SetKind(t)
+	return tc
+}
+
+// SetNillableKind sets the "kind" field if the given value is not nil.
+func (tc *TenantCreate) SetNillableKind(t *tenant.Kind) *TenantCreate {
+	if t != nil {
+		tc.SetKind(*t)
+	}
+	return tc
+}
+
+// SetEventSequence sets the "event_sequence" field.
+func (tc *TenantCreate) SetEventSequence(i int64) *TenantCreate {
+	tc.mutation.SetEventSequence(i)
+	return tc
+}
+
+// SetNillableEventSequence sets the "event_sequence" field if the given value is not nil.
+func (tc *TenantCreate) SetNillableEventSequence(i *int64) *TenantCreate {
+	if i != nil {
+		tc.SetEventSequence(*i)
+	}
+	return tc
+}
+
 // SetID sets the "id" field.
 func (tc *TenantCreate) SetID(gi gidx.PrefixedID) *TenantCreate {
 	tc.mutation.SetID(gi)
@@ -188,6 +216,14 @@ func (tc *TenantCreate) defaults() {
 		v := tenant.DefaultUpdatedAt()
 		tc.mutation.SetUpdatedAt(v)
 	}
+	if _, ok := tc.mutation.Kind(); !ok {
+		v := tenant.DefaultKind
+		tc.mutation.SetKind(v)
+	}
+	if _, ok := tc.mutation.EventSequence(); !ok {
+		v := tenant.DefaultEventSequence
+		tc.mutation.SetEventSequence(v)
+	}
 	if _, ok := tc.mutation.ID(); !ok {
 		v := tenant.DefaultID()
 		tc.mutation.SetID(v)
@@ -205,6 +241,27 @@ func (tc *TenantCreate) check() error {
 	if _, ok := tc.mutation.Name(); !ok {
 		return &ValidationError{Name: "name", err: errors.New(`generated: missing required field "Tenant.name"`)}
 	}
+	if v, ok := tc.mutation.Name(); ok {
+		if err := tenant.NameValidator(v); err != nil {
+			return &ValidationError{Name: "name", err: fmt.Errorf(`generated: validator failed for field "Tenant.name": %w`, err)}
+		}
+	}
+	if v, ok := tc.mutation.Description(); ok {
+		if err := tenant.DescriptionValidator(v); err != nil {
+			return &ValidationError{Name: "description", err: fmt.Errorf(`generated: validator failed for field "Tenant.description": %w`, err)}
+		}
+	}
+	if _, ok := tc.mutation.Kind(); !ok {
+		return &ValidationError{Name: "kind", err: errors.New(`generated: missing required field "Tenant.kind"`)}
+	}
+	if v, ok := tc.mutation.Kind(); ok {
+		if err := tenant.KindValidator(v); err != nil {
+			return &ValidationError{Name: "kind", err: fmt.Errorf(`generated: validator failed for field "Tenant.kind": %w`, err)}
+		}
+	}
+	if _, ok := tc.mutation.EventSequence(); !ok {
+		return &ValidationError{Name: "event_sequence", err: errors.New(`generated: missing required field "Tenant.event_sequence"`)}
+	}
 	return nil
 }
 
@@ -256,6 +313,14 @@ func (tc *TenantCreate) createSpec() (*Tenant, *sqlgraph.CreateSpec) {
 		_spec.SetField(tenant.FieldDescription, field.TypeString, value)
 		_node.Description = value
 	}
+	if value, ok := tc.mutation.Kind(); ok {
+		_spec.SetField(tenant.FieldKind, field.TypeEnum, value)
+		_node.Kind = value
+	}
+	if value, ok := tc.mutation.EventSequence(); ok {
+		_spec.SetField(tenant.FieldEventSequence, field.TypeInt64, value)
+		_node.EventSequence = value
+	}
 	if nodes := tc.mutation.ParentIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.M2O,
@@ -68,6 +68,27 @@ func (tu *TenantUpdate) ClearDescription() *TenantUpdate {
 	return tu
 }
 
+// SetEventSequence sets the "event_sequence" field.
+func (tu *TenantUpdate) SetEventSequence(i int64) *TenantUpdate {
+	tu.mutation.ResetEventSequence()
+	tu.mutation.SetEventSequence(i)
+	return tu
+}
+
+// SetNillableEventSequence sets the "event_sequence" field if the given value is not nil.
+func (tu *TenantUpdate) SetNillableEventSequence(i *int64) *TenantUpdate {
+	if i != nil {
+		tu.SetEventSequence(*i)
+	}
+	return tu
+}
+
+// AddEventSequence adds i to the "event_sequence" field.
+func (tu *TenantUpdate) AddEventSequence(i int64) *TenantUpdate {
+	tu.mutation.AddEventSequence(i)
+	return tu
+}
+
 // AddChildIDs adds the "children" edge to the Tenant entity by IDs.
 func (tu *TenantUpdate) AddChildIDs(ids ...gidx.PrefixedID) *TenantUpdate {
 	tu.mutation.AddChildIDs(ids...)
@@ -145,7 +166,25 @@ func (tu *TenantUpdate) defaults() {
 	}
 }
 
+// check runs all checks and user-defined validators on the builder.
+func (tu *TenantUpdate) check() error {
+	if v, ok := tu.mutation.Name(); ok {
+		if err := tenant.NameValidator(v); err != nil {
+			return &ValidationError{Name: "name", err: fmt.Errorf(`generated: validator failed for field "Tenant.name": %w`, err)}
+		}
+	}
+	if v, ok := tu.mutation.Description(); ok {
+		if err := tenant.DescriptionValidator(v); err != nil {
+			return &ValidationError{Name: "description", err: fmt.Errorf(`generated: validator failed for field "Tenant.description": %w`, err)}
+		}
+	}
+	return nil
+}
+
 func (tu *TenantUpdate) sqlSave(ctx context.Context) (n int, err error) {
+	if err := tu.check(); err != nil {
+		return n, err
+	}
 	_spec := sqlgraph.NewUpdateSpec(tenant.Table, tenant.Columns, sqlgraph.NewFieldSpec(tenant.FieldID, field.TypeString))
 	if ps := tu.mutation.predicates; len(ps) > 0 {
 		_spec.Predicate = func(selector *sql.Selector) {
@@ -166,6 +205,12 @@ func (tu *TenantUpdate) sqlSave(ctx context.Context) (n int, err error) {
 	if tu.mutation.DescriptionCleared() {
 		_spec.ClearField(tenant.FieldDescription, field.TypeString)
 	}
+	if value, ok := tu.mutation.EventSequence(); ok {
+		_spec.SetField(tenant.FieldEventSequence, field.TypeInt64, value)
+	}
+	if value, ok := tu.mutation.AddedEventSequence(); ok {
+		_spec.AddField(tenant.FieldEventSequence, field.TypeInt64, value)
+	}
 	if tu.mutation.ChildrenCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -257,6 +302,27 @@ func (tuo *TenantUpdateOne) ClearDescription() *TenantUpdateOne {
 	return tuo
 }
 
+// SetEventSequence sets the "event_sequence" field.
+func (tuo *TenantUpdateOne) SetEventSequence(i int64) *TenantUpdateOne {
+	tuo.mutation.ResetEventSequence()
+	tuo.mutation.SetEventSequence(i)
+	return tuo
+}
+
+// SetNillableEventSequence sets the "event_sequence" field if the given value is not nil.
+func (tuo *TenantUpdateOne) SetNillableEventSequence(i *int64) *TenantUpdateOne {
+	if i != nil {
+		tuo.SetEventSequence(*i)
+	}
+	return tuo
+}
+
+// AddEventSequence adds i to the "event_sequence" field.
+func (tuo *TenantUpdateOne) AddEventSequence(i int64) *TenantUpdateOne {
+	tuo.mutation.AddEventSequence(i)
+	return tuo
+}
+
 // AddChildIDs adds the "children" edge to the Tenant entity by IDs.
 func (tuo *TenantUpdateOne) AddChildIDs(ids ...gidx.PrefixedID) *TenantUpdateOne {
 	tuo.mutation.AddChildIDs(ids...)
@@ -347,7 +413,25 @@ func (tuo *TenantUpdateOne) defaults() {
 	}
 }
 
+// check runs all checks and user-defined validators on the builder.
+func (tuo *TenantUpdateOne) check() error {
+	if v, ok := tuo.mutation.Name(); ok {
+		if err := tenant.NameValidator(v); err != nil {
+			return &ValidationError{Name: "name", err: fmt.Errorf(`generated: validator failed for field "Tenant.name": %w`, err)}
+		}
+	}
+	if v, ok := tuo.mutation.Description(); ok {
+		if err := tenant.DescriptionValidator(v); err != nil {
+			return &ValidationError{Name: "description", err: fmt.Errorf(`generated: validator failed for field "Tenant.description": %w`, err)}
+		}
+	}
+	return nil
+}
+
 func (tuo *TenantUpdateOne) sqlSave(ctx context.Context) (_node *Tenant, err error) {
+	if err := tuo.check(); err != nil {
+		return _node, err
+	}
 	_spec := sqlgraph.NewUpdateSpec(tenant.Table, tenant.Columns, sqlgraph.NewFieldSpec(tenant.FieldID, field.TypeString))
 	id, ok := tuo.mutation.ID()
 	if !ok {
@@ -385,6 +469,12 @@ func (tuo *TenantUpdateOne) sqlSave(ctx context.Context) (_node *Tenant, err err
 	if tuo.mutation.DescriptionCleared() {
 		_spec.ClearField(tenant.FieldDescription, field.TypeString)
 	}
+	if value, ok := tuo.mutation.EventSequence(); ok {
+		_spec.SetField(tenant.FieldEventSequence, field.TypeInt64, value)
+	}
+	if value, ok := tuo.mutation.AddedEventSequence(); ok {
+		_spec.AddField(tenant.FieldEventSequence, field.TypeInt64, value)
+	}
 	if tuo.mutation.ChildrenCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -18,6 +18,9 @@ package generated
 
 import (
 	"context"
+	stdsql "database/sql"
+	"errors"
+	"fmt"
 	"sync"
 
 	"entgo.io/ent/dialect"
@@ -28,6 +31,10 @@ type Tx struct {
 	config
 	// Tenant is the client for interacting with the Tenant builders.
 	Tenant *TenantClient
+	// WebhookDelivery is the client for interacting with the WebhookDelivery builders.
+	WebhookDelivery *WebhookDeliveryClient
+	// WebhookSubscription is the client for interacting with the WebhookSubscription builders.
+	WebhookSubscription *WebhookSubscriptionClient
 
 	// lazily loaded.
 	client     *Client
@@ -160,6 +167,8 @@ func (tx *Tx) Client() *Client {
 
 func (tx *Tx) init() {
 	tx.Tenant = NewTenantClient(tx.config)
+	tx.WebhookDelivery = NewWebhookDeliveryClient(tx.config)
+	tx.WebhookSubscription = NewWebhookSubscriptionClient(tx.config)
 }
 
 // txDriver wraps the given dialect.Tx with a nop dialect.Driver implementation.
@@ -222,3 +231,115 @@ func (tx *txDriver) Query(ctx context.Context, query string, args, v any) error
 }
 
 var _ dialect.Driver = (*txDriver)(nil)
+
+// afterCommitQueues are the functions queued to run once each transaction
+// is committed, keyed by the transaction's driver
+var afterCommitQueues sync.Map
+
+// afterCommitQueue is the functions queued to run once a transaction is
+// committed, in the order they were queued
+type afterCommitQueue struct {
+	mu  sync.Mutex
+	fns []func(*Client) error
+}
+
+func (q *afterCommitQueue) add(fn func(*Client) error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.fns = append(q.fns, fn)
+}
+
+func (q *afterCommitQueue) run(client *Client) error {
+	q.mu.Lock()
+	fns := q.fns
+	q.mu.Unlock()
+
+	var errs []error
+
+	for _, fn := range fns {
+		if err := fn(client); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// AfterCommit runs fn once the changes made with the client are committed,
+// with a client which isn't bound to a transaction. When the client is bound
+// to a transaction, fn is queued until the transaction is committed, queued
+// functions run in the order they were queued and their errors are returned
+// by Commit once the transaction is committed. They don't run when the
+// transaction is rolled back. Otherwise the changes are already committed and
+// fn runs right away.
+func (c *Client) AfterCommit(fn func(*Client) error) error {
+	txDriver, ok := c.driver.(*txDriver)
+	if !ok {
+		return fn(c)
+	}
+
+	v, loaded := afterCommitQueues.LoadOrStore(txDriver, &afterCommitQueue{})
+	queue := v.(*afterCommitQueue)
+
+	queue.add(fn)
+
+	if loaded {
+		return nil
+	}
+
+	tx := &Tx{config: c.config}
+	tx.init()
+
+	tx.OnCommit(func(next Committer) Committer {
+		return CommitFunc(func(ctx context.Context, tx *Tx) error {
+			afterCommitQueues.Delete(txDriver)
+
+			if err := next.Commit(ctx, tx); err != nil {
+				return err
+			}
+
+			cfg := c.config
+			cfg.driver = txDriver.drv
+
+			client := &Client{config: cfg}
+			client.init()
+
+			return queue.run(client)
+		})
+	})
+
+	tx.OnRollback(func(next Rollbacker) Rollbacker {
+		return RollbackFunc(func(ctx context.Context, tx *Tx) error {
+			afterCommitQueues.Delete(txDriver)
+
+			return next.Rollback(ctx, tx)
+		})
+	})
+
+	return nil
+}
+
+// ExecContext allows calling the underlying ExecContext method of the transaction if it is supported by it.
+// See, database/sql#Tx.ExecContext for more information.
+func (tx *txDriver) ExecContext(ctx context.Context, query string, args ...any) (stdsql.Result, error) {
+	ex, ok := tx.tx.(interface {
+		ExecContext(context.Context, string, ...any) (stdsql.Result, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("Tx.ExecContext is not supported")
+	}
+	return ex.ExecContext(ctx, query, args...)
+}
+
+// QueryContext allows calling the underlying QueryContext method of the transaction if it is supported by it.
+// See, database/sql#Tx.QueryContext for more information.
+func (tx *txDriver) QueryContext(ctx context.Context, query string, args ...any) (*stdsql.Rows, error) {
+	q, ok := tx.tx.(interface {
+		QueryContext(context.Context, string, ...any) (*stdsql.Rows, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("Tx.QueryContext is not supported")
+	}
+	return q.QueryContext(ctx, query, args...)
+}
@@ -0,0 +1,32 @@
+// Copyright 2023 The Infratographer Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// SubjectFieldAnnotation publishes the value of a field in the subject fields
+// of every event of its object, so consumers can filter events by it without
+// loading the object. It's read by the event hooks template and only applies
+// to fields which aren't nillable.
+type SubjectFieldAnnotation struct{}
+
+// Name implements the schema.Annotation interface
+func (SubjectFieldAnnotation) Name() string {
+	return "TENANTAPI_SUBJECT_FIELD"
+}
+
+// EventsSubjectField publishes the field's value in the subject fields of
+// every event
+func EventsSubjectField() SubjectFieldAnnotation {
+	return SubjectFieldAnnotation{}
+}
@@ -20,4 +20,8 @@ const (
 	ApplicationPrefix string = "tnnt"
 	// TenantPrefix is the prefix for tenants
 	TenantPrefix string = ApplicationPrefix + "ten"
+	// WebhookSubscriptionPrefix is the prefix for webhook subscriptions
+	WebhookSubscriptionPrefix string = ApplicationPrefix + "whs"
+	// WebhookDeliveryPrefix is the prefix for webhook deliveries
+	WebhookDeliveryPrefix string = ApplicationPrefix + "whd"
 )
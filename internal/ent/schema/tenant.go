@@ -25,6 +25,8 @@ import (
 	"github.com/vektah/gqlparser/v2/ast"
 	"go.infratographer.com/x/entx"
 	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/tenant-api/internal/validation"
 )
 
 // Tenant holds the schema definition for the Tenant entity.
@@ -50,6 +52,7 @@ func (Tenant) Fields() []ent.Field {
 			Immutable(),
 		field.String("name").
 			Comment("The name of a tenant.").
+			Validate(validation.ValidateName).
 			Annotations(
 				entgql.OrderField("NAME"),
 				entgql.Skip(entgql.SkipWhereInput),
@@ -57,6 +60,7 @@ func (Tenant) Fields() []ent.Field {
 		field.String("description").
 			Comment("An optional description of the tenant.").
 			Optional().
+			Validate(validation.ValidateDescription).
 			Annotations(
 				entgql.Skip(entgql.SkipWhereInput),
 			),
@@ -70,6 +74,26 @@ func (Tenant) Fields() []ent.Field {
 				entgql.Skip(entgql.SkipWhereInput, entgql.SkipMutationUpdateInput, entgql.SkipType),
 				entx.EventsHookAdditionalSubject(),
 			),
+		field.Enum("kind").
+			Comment("The kind of the tenant, the layer of the hierarchy it's in. The kinds a tenant may be a child of are configurable.").
+			NamedValues(
+				"Tenant", "TENANT",
+				"Organization", "ORGANIZATION",
+				"BusinessUnit", "BUSINESS_UNIT",
+				"Project", "PROJECT",
+				"Environment", "ENVIRONMENT",
+			).
+			Default("TENANT").
+			Immutable().
+			Annotations(
+				EventsSubjectField(),
+			),
+		field.Int64("event_sequence").
+			Comment("The sequence number of the last event published for the tenant, incremented by the event hooks.").
+			Default(0).
+			Annotations(
+				entgql.Skip(),
+			),
 	}
 }
 
@@ -0,0 +1,152 @@
+// Copyright 2023 The Infratographer Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"time"
+
+	"entgo.io/contrib/entgql"
+	"entgo.io/ent"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"go.infratographer.com/x/entx"
+	"go.infratographer.com/x/gidx"
+)
+
+// WebhookDelivery holds the schema definition for the WebhookDelivery entity.
+type WebhookDelivery struct {
+	ent.Schema
+}
+
+// Mixin of the WebhookDelivery
+func (WebhookDelivery) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		entx.NewTimestampMixin(),
+	}
+}
+
+// Fields of the WebhookDelivery.
+func (WebhookDelivery) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("id").
+			Comment("ID for the webhook delivery.").
+			GoType(gidx.PrefixedID("")).
+			DefaultFunc(func() gidx.PrefixedID { return gidx.MustNewID(WebhookDeliveryPrefix) }).
+			Unique().
+			Immutable(),
+		field.String("subscription_id").
+			Comment("The ID of the webhook subscription the change is delivered to.").
+			GoType(gidx.PrefixedID("")).
+			Immutable().
+			Annotations(
+				entgql.Type("ID"),
+				entgql.Skip(entgql.SkipWhereInput, entgql.SkipType),
+			),
+		field.String("event_type").
+			Comment("The event type of the delivered change.").
+			Immutable(),
+		field.String("subject_id").
+			Comment("The ID of the tenant which changed.").
+			GoType(gidx.PrefixedID("")).
+			Immutable().
+			Annotations(
+				entgql.Type("ID"),
+			),
+		field.Int64("event_sequence").
+			Comment("The event sequence number of the delivered change, a change is delivered once to each subscription.").
+			Optional().
+			Nillable().
+			Immutable().
+			Annotations(
+				entgql.Skip(),
+			),
+		field.Text("payload").
+			Comment("The change message posted to the webhook.").
+			Immutable().
+			Annotations(
+				entgql.Skip(entgql.SkipWhereInput),
+			),
+		field.Enum("status").
+			Comment("The status of the delivery, deliveries are dead once they run out of attempts.").
+			Values("PENDING", "DELIVERED", "DEAD").
+			Default("PENDING"),
+		field.Int("attempts").
+			Comment("The number of times delivery was attempted.").
+			Default(0).
+			Annotations(
+				entgql.Skip(entgql.SkipWhereInput),
+			),
+		field.Time("next_attempt_at").
+			Comment("When delivery is next attempted while the delivery is pending.").
+			Default(time.Now).
+			Annotations(
+				entgql.OrderField("NEXT_ATTEMPT_AT"),
+				entgql.Skip(entgql.SkipWhereInput),
+			),
+		field.Time("last_attempt_at").
+			Comment("When delivery was last attempted.").
+			Optional().
+			Nillable().
+			Annotations(
+				entgql.Skip(entgql.SkipWhereInput),
+			),
+		field.Int("response_status").
+			Comment("The HTTP status of the response to the last attempt.").
+			Optional().
+			Nillable().
+			Annotations(
+				entgql.Skip(entgql.SkipWhereInput),
+			),
+		field.String("last_error").
+			Comment("Why the last attempt failed.").
+			Optional().
+			Annotations(
+				entgql.Skip(entgql.SkipWhereInput),
+			),
+	}
+}
+
+// Indexes of the WebhookDelivery
+func (WebhookDelivery) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("status", "next_attempt_at"),
+		// redelivered change messages don't record duplicate deliveries,
+		// changes without a sequence number can't be told apart
+		index.Fields("subscription_id", "subject_id", "event_sequence").Unique(),
+	}
+}
+
+// Edges of the WebhookDelivery
+func (WebhookDelivery) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("subscription", WebhookSubscription.Type).
+			Ref("deliveries").
+			Field("subscription_id").
+			Unique().
+			Required().
+			Immutable(),
+	}
+}
+
+// Annotations for the WebhookDelivery
+func (WebhookDelivery) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		prefixIDDirective(WebhookDeliveryPrefix),
+		entgql.RelayConnection(),
+		schema.Comment("An attempt to post a tenant change message to a webhook."),
+	}
+}
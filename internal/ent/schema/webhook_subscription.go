@@ -0,0 +1,122 @@
+// Copyright 2023 The Infratographer Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"errors"
+	"net/url"
+
+	"entgo.io/contrib/entgql"
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"go.infratographer.com/x/entx"
+	"go.infratographer.com/x/gidx"
+)
+
+var errInvalidWebhookURL = errors.New("webhook url must be an absolute http or https url")
+
+// WebhookSubscription holds the schema definition for the WebhookSubscription entity.
+type WebhookSubscription struct {
+	ent.Schema
+}
+
+// Mixin of the WebhookSubscription
+func (WebhookSubscription) Mixin() []ent.Mixin {
+	return []ent.Mixin{
+		entx.NewTimestampMixin(),
+	}
+}
+
+// Fields of the WebhookSubscription.
+func (WebhookSubscription) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("id").
+			Comment("ID for the webhook subscription.").
+			GoType(gidx.PrefixedID("")).
+			DefaultFunc(func() gidx.PrefixedID { return gidx.MustNewID(WebhookSubscriptionPrefix) }).
+			Unique().
+			Immutable(),
+		field.String("url").
+			Comment("The URL change messages are posted to.").
+			Validate(validateWebhookURL).
+			Annotations(
+				entgql.Skip(entgql.SkipWhereInput),
+			),
+		field.String("secret").
+			Comment("The secret used to sign the posted change messages, it can't be read back.").
+			NotEmpty().
+			Sensitive().
+			Annotations(
+				entgql.Skip(entgql.SkipType, entgql.SkipWhereInput),
+			),
+		field.Strings("event_types").
+			Comment("The change event types delivered, every event type is delivered when empty.").
+			Optional(),
+		field.String("tenant_id").
+			Comment("The ID of the tenant whose subtree the subscription is scoped to, changes to every tenant are delivered when empty.").
+			Optional().
+			Immutable().
+			GoType(gidx.PrefixedID("")).
+			Annotations(
+				entgql.Type("ID"),
+				entgql.Skip(entgql.SkipWhereInput, entgql.SkipMutationUpdateInput),
+			),
+	}
+}
+
+// Indexes of the WebhookSubscription
+func (WebhookSubscription) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("tenant_id"),
+	}
+}
+
+// Edges of the WebhookSubscription
+func (WebhookSubscription) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("deliveries", WebhookDelivery.Type).
+			Annotations(
+				entgql.RelayConnection(),
+				entgql.Skip(entgql.SkipMutationCreateInput, entgql.SkipMutationUpdateInput),
+				entsql.OnDelete(entsql.Cascade),
+			),
+	}
+}
+
+// Annotations for the WebhookSubscription
+func (WebhookSubscription) Annotations() []schema.Annotation {
+	return []schema.Annotation{
+		prefixIDDirective(WebhookSubscriptionPrefix),
+		entgql.RelayConnection(),
+		schema.Comment("A subscription posting tenant change messages to a webhook."),
+		entgql.Mutations(
+			entgql.MutationCreate().Description("Input information to create a webhook subscription."),
+			entgql.MutationUpdate().Description("Input information to update a webhook subscription."),
+		),
+	}
+}
+
+func validateWebhookURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil || !u.IsAbs() || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return errInvalidWebhookURL
+	}
+
+	return nil
+}
@@ -0,0 +1,718 @@
+// Package fsck checks the tenant hierarchy for integrity problems and repairs them.
+package fsck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.infratographer.com/x/gidx"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/ent/schema"
+	"go.infratographer.com/tenant-api/internal/kinds"
+	"go.infratographer.com/tenant-api/internal/limits"
+)
+
+// IssueKind identifies the type of problem found in the tenant hierarchy.
+type IssueKind string
+
+const (
+	// IssueOrphan is reported when a tenant references a parent that doesn't exist.
+	IssueOrphan IssueKind = "orphan"
+	// IssueUnexpectedRoot is reported when a root tenant isn't one of the expected roots.
+	// This is how children are found whose parent was removed with ON DELETE SET NULL.
+	IssueUnexpectedRoot IssueKind = "unexpected_root"
+	// IssueCycle is reported when following the parents of a tenant leads back to itself.
+	IssueCycle IssueKind = "cycle"
+	// IssueDuplicateName is reported when siblings share the same name.
+	IssueDuplicateName IssueKind = "duplicate_name"
+	// IssueInvalidID is reported when a tenant or parent ID isn't a valid tenant gidx.
+	IssueInvalidID IssueKind = "invalid_id"
+)
+
+// IssueKinds lists all the kinds of issues reported by Check.
+var IssueKinds = []IssueKind{
+	IssueOrphan,
+	IssueUnexpectedRoot,
+	IssueCycle,
+	IssueDuplicateName,
+	IssueInvalidID,
+}
+
+var (
+	// ErrUnknownIssueKind is returned when an issue kind isn't recognized
+	ErrUnknownIssueKind = errors.New("unknown issue kind")
+	// ErrInvalidReparentTarget is returned when the tenant to move tenants under can't be used
+	ErrInvalidReparentTarget = errors.New("invalid reparent target")
+	// ErrInvalidPrefix is returned when an ID doesn't have the tenant prefix
+	ErrInvalidPrefix = errors.New("invalid prefix")
+)
+
+// ParseIssueKind returns the IssueKind matching the given name.
+func ParseIssueKind(s string) (IssueKind, error) {
+	for _, k := range IssueKinds {
+		if string(k) == s {
+			return k, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownIssueKind, s)
+}
+
+// Issue describes a single problem found in the tenant hierarchy.
+type Issue struct {
+	// Kind is the type of problem found
+	Kind IssueKind `json:"kind"`
+	// TenantID is the tenant the issue is reported against
+	TenantID gidx.PrefixedID `json:"tenantID"`
+	// ParentID is the parent tenant ID stored on the tenant, if any
+	ParentID gidx.PrefixedID `json:"parentID,omitempty"`
+	// RelatedIDs are other tenants involved in the issue, such as the other members of a cycle
+	RelatedIDs []gidx.PrefixedID `json:"relatedIDs,omitempty"`
+	// Message is a human readable description of the issue
+	Message string `json:"message"`
+	// Fixed is true when the issue was repaired
+	Fixed bool `json:"fixed"`
+}
+
+// Report is the result of checking the tenant hierarchy.
+type Report struct {
+	// CheckedAt is when the check was run
+	CheckedAt time.Time `json:"checkedAt"`
+	// TenantCount is the number of tenants checked
+	TenantCount int `json:"tenantCount"`
+	// RootCount is the number of tenants without a parent
+	RootCount int `json:"rootCount"`
+	// Issues are all the problems found
+	Issues []*Issue `json:"issues"`
+
+	tenants map[gidx.PrefixedID]*ent.Tenant
+}
+
+// Unresolved returns the number of issues that have not been fixed.
+func (r *Report) Unresolved() int {
+	var count int
+
+	for _, issue := range r.Issues {
+		if !issue.Fixed {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Options configures the checks run by Check.
+type Options struct {
+	// ExpectedRoots is the set of tenants allowed to have no parent. When
+	// empty, roots are not checked.
+	ExpectedRoots []gidx.PrefixedID
+}
+
+// Check inspects the given tenants and reports any issues with the hierarchy.
+func Check(tenants []*ent.Tenant, opts Options) *Report {
+	report := &Report{
+		CheckedAt:   time.Now().UTC(),
+		TenantCount: len(tenants),
+		Issues:      []*Issue{},
+		tenants:     make(map[gidx.PrefixedID]*ent.Tenant, len(tenants)),
+	}
+
+	for _, t := range tenants {
+		report.tenants[t.ID] = t
+	}
+
+	// sort the tenants so the report is stable between runs
+	sorted := make([]*ent.Tenant, len(tenants))
+	copy(sorted, tenants)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	expectedRoots := make(map[gidx.PrefixedID]bool, len(opts.ExpectedRoots))
+	for _, id := range opts.ExpectedRoots {
+		expectedRoots[id] = true
+	}
+
+	for _, t := range sorted {
+		report.checkIDs(t)
+
+		switch {
+		case t.ParentTenantID == gidx.NullPrefixedID:
+			report.RootCount++
+
+			if len(expectedRoots) != 0 && !expectedRoots[t.ID] {
+				report.add(&Issue{
+					Kind:     IssueUnexpectedRoot,
+					TenantID: t.ID,
+					Message:  "tenant has no parent and is not an expected root",
+				})
+			}
+		case report.tenants[t.ParentTenantID] == nil:
+			report.add(&Issue{
+				Kind:     IssueOrphan,
+				TenantID: t.ID,
+				ParentID: t.ParentTenantID,
+				Message:  "parent tenant does not exist",
+			})
+		}
+	}
+
+	report.checkCycles(sorted)
+	report.checkDuplicateNames(sorted)
+
+	return report
+}
+
+func (r *Report) add(issue *Issue) {
+	r.Issues = append(r.Issues, issue)
+}
+
+func (r *Report) checkIDs(t *ent.Tenant) {
+	if err := validID(t.ID); err != nil {
+		r.add(&Issue{
+			Kind:     IssueInvalidID,
+			TenantID: t.ID,
+			ParentID: t.ParentTenantID,
+			Message:  fmt.Sprintf("tenant id is invalid: %s", err),
+		})
+	}
+
+	if t.ParentTenantID == gidx.NullPrefixedID {
+		return
+	}
+
+	if err := validID(t.ParentTenantID); err != nil {
+		r.add(&Issue{
+			Kind:     IssueInvalidID,
+			TenantID: t.ID,
+			ParentID: t.ParentTenantID,
+			Message:  fmt.Sprintf("parent tenant id is invalid: %s", err),
+		})
+	}
+}
+
+func validID(id gidx.PrefixedID) error {
+	if _, err := gidx.Parse(id.String()); err != nil {
+		return err
+	}
+
+	if id.Prefix() != schema.TenantPrefix {
+		return fmt.Errorf("%w: expected %s, got %s", ErrInvalidPrefix, schema.TenantPrefix, id.Prefix())
+	}
+
+	return nil
+}
+
+func (r *Report) checkCycles(tenants []*ent.Tenant) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[gidx.PrefixedID]int, len(tenants))
+
+	for _, t := range tenants {
+		var path []*ent.Tenant
+
+		current := t
+
+		// walk up the parents until we reach a root, a missing parent or a tenant we've seen before
+		for current != nil && state[current.ID] == unvisited {
+			state[current.ID] = visiting
+			path = append(path, current)
+
+			current = r.tenants[current.ParentTenantID]
+		}
+
+		if current != nil && state[current.ID] == visiting {
+			for i, member := range path {
+				if member.ID == current.ID {
+					r.addCycle(path[i:])
+
+					break
+				}
+			}
+		}
+
+		for _, member := range path {
+			state[member.ID] = visited
+		}
+	}
+}
+
+func (r *Report) addCycle(members []*ent.Tenant) {
+	// the oldest tenant in the cycle is the one reported, fixing it detaches it from the cycle
+	sorted := sortByAge(members)
+
+	related := make([]gidx.PrefixedID, 0, len(sorted)-1)
+	for _, member := range sorted[1:] {
+		related = append(related, member.ID)
+	}
+
+	r.add(&Issue{
+		Kind:       IssueCycle,
+		TenantID:   sorted[0].ID,
+		ParentID:   sorted[0].ParentTenantID,
+		RelatedIDs: related,
+		Message:    fmt.Sprintf("tenant is part of a parent cycle with %d tenants", len(sorted)),
+	})
+}
+
+func (r *Report) checkDuplicateNames(tenants []*ent.Tenant) {
+	type siblingKey struct {
+		parent gidx.PrefixedID
+		name   string
+	}
+
+	var keys []siblingKey
+
+	siblings := map[siblingKey][]*ent.Tenant{}
+
+	for _, t := range tenants {
+		key := siblingKey{parent: t.ParentTenantID, name: t.Name}
+
+		if _, ok := siblings[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		siblings[key] = append(siblings[key], t)
+	}
+
+	for _, key := range keys {
+		dupes := siblings[key]
+		if len(dupes) <= 1 {
+			continue
+		}
+
+		// the oldest tenant keeps its name, fixing renames the others
+		sorted := sortByAge(dupes)
+
+		related := make([]gidx.PrefixedID, 0, len(sorted)-1)
+		for _, t := range sorted[1:] {
+			related = append(related, t.ID)
+		}
+
+		r.add(&Issue{
+			Kind:       IssueDuplicateName,
+			TenantID:   sorted[0].ID,
+			ParentID:   key.parent,
+			RelatedIDs: related,
+			Message:    fmt.Sprintf("%d sibling tenants are named %q", len(sorted), key.name),
+		})
+	}
+}
+
+func sortByAge(tenants []*ent.Tenant) []*ent.Tenant {
+	sorted := make([]*ent.Tenant, len(tenants))
+	copy(sorted, tenants)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].ID < sorted[j].ID
+		}
+
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	return sorted
+}
+
+// FixOptions configures how issues are repaired by Fix.
+type FixOptions struct {
+	// Kinds are the kinds of issues to repair
+	Kinds []IssueKind
+	// ReparentTo is the tenant orphans, unexpected roots and cycle members
+	// are moved under. When empty they become root tenants instead.
+	ReparentTo gidx.PrefixedID
+	// Limiter is the tenant hierarchy limits moves are checked against, moves
+	// aren't limited when it's nil
+	Limiter *limits.Limiter
+	// KindGraph is the kind graph moves are checked against, the kinds of
+	// moved tenants aren't restricted when it's nil
+	KindGraph *kinds.Graph
+}
+
+// Change describes a single field updated while fixing an issue.
+type Change struct {
+	// TenantID is the tenant that was updated
+	TenantID gidx.PrefixedID
+	// ParentID is the parent of the tenant after the change
+	ParentID gidx.PrefixedID
+	// Field is the name of the field that was changed
+	Field string
+	// PreviousValue is the value before the change
+	PreviousValue string
+	// CurrentValue is the value after the change
+	CurrentValue string
+	// Published is true when the change was made with the ent client, whose
+	// event hooks publish it
+	Published bool
+	// SubjectFields are the subject fields of the change's event when it
+	// isn't published by the event hooks, with the tenant's event sequence
+	// number after the change
+	SubjectFields map[string]string
+}
+
+// Fix repairs the issues in the report matching the requested kinds and
+// returns the changes that were made. Issues which were repaired are marked
+// as fixed. Invalid IDs can't be repaired since other services reference them.
+//
+// Renames are made with the ent client, so they're validated and published
+// like any other change. A tenant's parent is immutable for the ent client,
+// so moves are made with SQL which increments the tenant's event sequence,
+// and have to be published by the caller. The client should be bound to a
+// transaction. Since moves bypass the ent hooks, they're checked against the
+// kind graph and the limiter before anything is changed, and nothing is
+// changed when any move would break either.
+func Fix(ctx context.Context, client *ent.Client, report *Report, opts FixOptions) ([]Change, error) {
+	fixing := make(map[IssueKind]bool, len(opts.Kinds))
+	for _, k := range opts.Kinds {
+		fixing[k] = true
+	}
+
+	moved := report.moved(fixing, opts.ReparentTo)
+
+	if opts.ReparentTo != gidx.NullPrefixedID {
+		if err := report.validReparentTarget(opts.ReparentTo, moved); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := report.checkKinds(opts.KindGraph, moved, opts.ReparentTo); err != nil {
+		return nil, err
+	}
+
+	if err := report.checkLimits(opts.Limiter, moved, opts.ReparentTo); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	var changes []Change
+
+	for _, issue := range report.Issues {
+		if !fixing[issue.Kind] {
+			continue
+		}
+
+		var (
+			issueChanges []Change
+			err          error
+		)
+
+		switch issue.Kind {
+		case IssueOrphan, IssueCycle:
+			issueChanges, err = setParent(ctx, client, now, issue.TenantID, issue.ParentID, opts.ReparentTo)
+		case IssueUnexpectedRoot:
+			// without somewhere to move the root to there's nothing we can do
+			if opts.ReparentTo == gidx.NullPrefixedID {
+				continue
+			}
+
+			issueChanges, err = setParent(ctx, client, now, issue.TenantID, issue.ParentID, opts.ReparentTo)
+		case IssueDuplicateName:
+			issueChanges, err = report.renameDuplicates(ctx, client, issue)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to fix %s issue for tenant %s: %w", issue.Kind, issue.TenantID, err)
+		}
+
+		issue.Fixed = true
+
+		changes = append(changes, issueChanges...)
+	}
+
+	return changes, nil
+}
+
+// moved returns the tenants Fix moves for the issue kinds being fixed.
+// Orphans and cycle members are moved to the target, or made roots without
+// one, and unexpected roots are only moved to a target.
+func (r *Report) moved(fixing map[IssueKind]bool, target gidx.PrefixedID) map[gidx.PrefixedID]bool {
+	moved := map[gidx.PrefixedID]bool{}
+
+	for _, issue := range r.Issues {
+		if !fixing[issue.Kind] {
+			continue
+		}
+
+		switch issue.Kind {
+		case IssueOrphan, IssueCycle:
+			moved[issue.TenantID] = true
+		case IssueUnexpectedRoot:
+			if target != gidx.NullPrefixedID {
+				moved[issue.TenantID] = true
+			}
+		}
+	}
+
+	return moved
+}
+
+// validReparentTarget returns an error when tenants can't be moved under the
+// tenant, because it has an issue itself or because it's one of the tenants
+// being moved or their descendants, which would make a cycle
+func (r *Report) validReparentTarget(id gidx.PrefixedID, moved map[gidx.PrefixedID]bool) error {
+	if r.tenants[id] == nil {
+		return fmt.Errorf("%w: tenant %s does not exist", ErrInvalidReparentTarget, id)
+	}
+
+	// guard against a cycle looping forever, the target's cycle issue is
+	// reported below
+	seen := map[gidx.PrefixedID]bool{}
+
+	for ancestor := id; ancestor != gidx.NullPrefixedID && !seen[ancestor]; {
+		seen[ancestor] = true
+
+		if moved[ancestor] {
+			if ancestor == id {
+				return fmt.Errorf("%w: tenant %s is being moved", ErrInvalidReparentTarget, id)
+			}
+
+			return fmt.Errorf("%w: tenant %s is a descendant of %s, which is being moved", ErrInvalidReparentTarget, id, ancestor)
+		}
+
+		t := r.tenants[ancestor]
+		if t == nil {
+			break
+		}
+
+		ancestor = t.ParentTenantID
+	}
+
+	for _, issue := range r.Issues {
+		switch issue.Kind {
+		case IssueOrphan, IssueCycle, IssueInvalidID:
+		default:
+			continue
+		}
+
+		if issue.TenantID == id {
+			return fmt.Errorf("%w: tenant %s has a %s issue", ErrInvalidReparentTarget, id, issue.Kind)
+		}
+
+		for _, related := range issue.RelatedIDs {
+			if related == id {
+				return fmt.Errorf("%w: tenant %s has a %s issue", ErrInvalidReparentTarget, id, issue.Kind)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkKinds returns kinds.ErrKindNotAllowed when the kind of any of the
+// tenants can't be a child of the target's kind, or a root without a target
+func (r *Report) checkKinds(graph *kinds.Graph, moved map[gidx.PrefixedID]bool, target gidx.PrefixedID) error {
+	if graph == nil {
+		return nil
+	}
+
+	var parentKind tenant.Kind
+
+	if target != gidx.NullPrefixedID {
+		parentKind = r.tenants[target].Kind
+	}
+
+	for _, id := range sortedIDs(moved) {
+		if err := graph.Allowed(r.tenants[id].Kind, parentKind); err != nil {
+			return fmt.Errorf("moving %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// checkLimits returns limits.ErrMaxDepth or limits.ErrMaxChildren when moving
+// the tenants to the target, or making them roots without one, would exceed
+// the limits of the tree they're moved to. The tenants moved with them are
+// checked too, as they may be moved to a tree with lower limits.
+func (r *Report) checkLimits(limiter *limits.Limiter, moved map[gidx.PrefixedID]bool, target gidx.PrefixedID) error {
+	if limiter == nil || len(moved) == 0 {
+		return nil
+	}
+
+	children := r.children(moved)
+
+	var (
+		targetRoot  gidx.PrefixedID
+		targetDepth int
+	)
+
+	if target != gidx.NullPrefixedID {
+		targetRoot, targetDepth = r.rootOf(target)
+
+		l := limiter.For(targetRoot)
+
+		if count := len(children[target]) + len(moved); l.MaxChildren > 0 && count > l.MaxChildren {
+			return fmt.Errorf("%w: %s would have %d children, the limit under root %s is %d", limits.ErrMaxChildren, target, count, targetRoot, l.MaxChildren)
+		}
+	}
+
+	for _, id := range sortedIDs(moved) {
+		root := targetRoot
+		if target == gidx.NullPrefixedID {
+			root = id
+		}
+
+		l := limiter.For(root)
+
+		height, widest := subtree(children, id)
+
+		if depth := targetDepth + height; l.MaxDepth > 0 && depth > l.MaxDepth {
+			return fmt.Errorf("%w: moving %s would make a tenant with a depth of %d, the limit under root %s is %d", limits.ErrMaxDepth, id, depth, root, l.MaxDepth)
+		}
+
+		if count := len(children[widest]); l.MaxChildren > 0 && count > l.MaxChildren {
+			return fmt.Errorf("%w: moving %s would move %s with %d children under root %s, whose limit is %d", limits.ErrMaxChildren, id, widest, count, root, l.MaxChildren)
+		}
+	}
+
+	return nil
+}
+
+// children returns the children of each tenant, leaving out the tenants
+// which are being moved
+func (r *Report) children(moved map[gidx.PrefixedID]bool) map[gidx.PrefixedID][]gidx.PrefixedID {
+	children := map[gidx.PrefixedID][]gidx.PrefixedID{}
+
+	for _, t := range r.tenants {
+		if t.ParentTenantID != gidx.NullPrefixedID && !moved[t.ID] {
+			children[t.ParentTenantID] = append(children[t.ParentTenantID], t.ID)
+		}
+	}
+
+	return children
+}
+
+// rootOf returns the root of the tenant and the tenant's depth, the same way
+// the limits hook does
+func (r *Report) rootOf(id gidx.PrefixedID) (gidx.PrefixedID, int) {
+	depth := 0
+	seen := map[gidx.PrefixedID]bool{}
+
+	for !seen[id] {
+		seen[id] = true
+		depth++
+
+		t := r.tenants[id]
+		if t == nil || t.ParentTenantID == gidx.NullPrefixedID {
+			return id, depth
+		}
+
+		id = t.ParentTenantID
+	}
+
+	return id, depth
+}
+
+// subtree returns the height of the tree under the tenant, a tenant without
+// children has a height of one, and the tenant in it with the most children
+func subtree(children map[gidx.PrefixedID][]gidx.PrefixedID, id gidx.PrefixedID) (int, gidx.PrefixedID) {
+	height := 0
+	widest := id
+	seen := map[gidx.PrefixedID]bool{id: true}
+
+	for level := []gidx.PrefixedID{id}; len(level) != 0; height++ {
+		var next []gidx.PrefixedID
+
+		for _, t := range level {
+			if len(children[t]) > len(children[widest]) {
+				widest = t
+			}
+
+			// guard against a cycle under the tenant looping forever
+			for _, child := range children[t] {
+				if !seen[child] {
+					seen[child] = true
+					next = append(next, child)
+				}
+			}
+		}
+
+		level = next
+	}
+
+	return height, widest
+}
+
+// sortedIDs returns the ids in the set sorted, so the same error is returned
+// between runs
+func sortedIDs(set map[gidx.PrefixedID]bool) []gidx.PrefixedID {
+	ids := make([]gidx.PrefixedID, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+// setParent moves the tenant under the parent with SQL, incrementing its
+// event sequence like the event hooks do
+func setParent(ctx context.Context, client *ent.Client, now time.Time, id, previous, parent gidx.PrefixedID) ([]Change, error) {
+	var parentValue any
+
+	if parent != gidx.NullPrefixedID {
+		parentValue = parent
+	}
+
+	if _, err := client.ExecContext(ctx,
+		"UPDATE tenants SET parent_tenant_id = $1, updated_at = $2, event_sequence = event_sequence + 1 WHERE id = $3",
+		parentValue, now, id,
+	); err != nil {
+		return nil, err
+	}
+
+	// the fields are loaded with the client so they're read in its transaction
+	t, err := client.Tenant.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Change{{
+		TenantID:      id,
+		ParentID:      parent,
+		Field:         tenant.FieldParentTenantID,
+		PreviousValue: previous.String(),
+		CurrentValue:  parent.String(),
+		SubjectFields: map[string]string{
+			tenant.FieldEventSequence: fmt.Sprint(t.EventSequence),
+			tenant.FieldKind:          t.Kind.String(),
+		},
+	}}, nil
+}
+
+// renameDuplicates renames all but the oldest of the duplicates with the ent
+// client
+func (r *Report) renameDuplicates(ctx context.Context, client *ent.Client, issue *Issue) ([]Change, error) {
+	changes := make([]Change, 0, len(issue.RelatedIDs))
+
+	for _, id := range issue.RelatedIDs {
+		t := r.tenants[id]
+
+		renamed, err := client.Tenant.UpdateOneID(id).SetName(fmt.Sprintf("%s (%s)", t.Name, t.ID)).Save(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, Change{
+			TenantID:      id,
+			ParentID:      t.ParentTenantID,
+			Field:         tenant.FieldName,
+			PreviousValue: t.Name,
+			CurrentValue:  renamed.Name,
+			Published:     true,
+		})
+	}
+
+	return changes, nil
+}
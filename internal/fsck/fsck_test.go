@@ -0,0 +1,396 @@
+package fsck_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/ent/schema"
+	"go.infratographer.com/tenant-api/internal/fsck"
+	"go.infratographer.com/tenant-api/internal/kinds"
+	"go.infratographer.com/tenant-api/internal/limits"
+)
+
+func newTenant(name string, parent *ent.Tenant, age time.Duration) *ent.Tenant {
+	t := &ent.Tenant{
+		ID:        gidx.MustNewID(schema.TenantPrefix),
+		Name:      name,
+		CreatedAt: time.Now().Add(-age),
+	}
+
+	if parent != nil {
+		t.ParentTenantID = parent.ID
+	}
+
+	return t
+}
+
+func issuesOfKind(report *fsck.Report, kind fsck.IssueKind) []*fsck.Issue {
+	var issues []*fsck.Issue
+
+	for _, issue := range report.Issues {
+		if issue.Kind == kind {
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues
+}
+
+func TestCheckHealthyTree(t *testing.T) {
+	root := newTenant("root", nil, time.Hour)
+	child := newTenant("child", root, time.Minute)
+	grandchild := newTenant("child", child, time.Second)
+
+	report := fsck.Check([]*ent.Tenant{root, child, grandchild}, fsck.Options{ExpectedRoots: []gidx.PrefixedID{root.ID}})
+
+	assert.Equal(t, 3, report.TenantCount)
+	assert.Equal(t, 1, report.RootCount)
+	assert.Empty(t, report.Issues)
+	assert.Zero(t, report.Unresolved())
+}
+
+func TestCheckIssues(t *testing.T) {
+	root := newTenant("root", nil, time.Hour)
+	formerChild := newTenant("former-child", nil, time.Minute)
+
+	orphan := newTenant("orphan", nil, time.Minute)
+	orphan.ParentTenantID = gidx.MustNewID(schema.TenantPrefix)
+
+	dupeOld := newTenant("dupe", root, time.Minute)
+	dupeNew := newTenant("dupe", root, time.Second)
+
+	cycleA := newTenant("cycle-a", nil, time.Minute)
+	cycleB := newTenant("cycle-b", cycleA, time.Second)
+	cycleA.ParentTenantID = cycleB.ID
+
+	invalid := newTenant("invalid", root, time.Second)
+	invalid.ID = gidx.MustNewID("testing")
+
+	report := fsck.Check(
+		[]*ent.Tenant{root, formerChild, orphan, dupeOld, dupeNew, cycleA, cycleB, invalid},
+		fsck.Options{ExpectedRoots: []gidx.PrefixedID{root.ID}},
+	)
+
+	assert.Equal(t, 8, report.TenantCount)
+	assert.Equal(t, 2, report.RootCount)
+	assert.Len(t, report.Issues, 5)
+
+	roots := issuesOfKind(report, fsck.IssueUnexpectedRoot)
+	require.Len(t, roots, 1)
+	assert.Equal(t, formerChild.ID, roots[0].TenantID)
+
+	orphans := issuesOfKind(report, fsck.IssueOrphan)
+	require.Len(t, orphans, 1)
+	assert.Equal(t, orphan.ID, orphans[0].TenantID)
+	assert.Equal(t, orphan.ParentTenantID, orphans[0].ParentID)
+
+	dupes := issuesOfKind(report, fsck.IssueDuplicateName)
+	require.Len(t, dupes, 1)
+	assert.Equal(t, dupeOld.ID, dupes[0].TenantID)
+	assert.Equal(t, []gidx.PrefixedID{dupeNew.ID}, dupes[0].RelatedIDs)
+
+	cycles := issuesOfKind(report, fsck.IssueCycle)
+	require.Len(t, cycles, 1)
+	assert.Equal(t, cycleA.ID, cycles[0].TenantID)
+	assert.Equal(t, []gidx.PrefixedID{cycleB.ID}, cycles[0].RelatedIDs)
+
+	invalids := issuesOfKind(report, fsck.IssueInvalidID)
+	require.Len(t, invalids, 1)
+	assert.Equal(t, invalid.ID, invalids[0].TenantID)
+}
+
+func TestCheckWithoutExpectedRoots(t *testing.T) {
+	report := fsck.Check([]*ent.Tenant{newTenant("a", nil, time.Hour), newTenant("b", nil, time.Hour)}, fsck.Options{})
+
+	assert.Equal(t, 2, report.RootCount)
+	assert.Empty(t, report.Issues)
+}
+
+// seedFix creates the tenants for TestFix, returning the client and the
+// tenants the issues are reported for. The client doesn't enforce foreign
+// keys so the orphan can be created.
+func seedFix(t *testing.T) (*ent.Client, []*ent.Tenant) {
+	t.Helper()
+
+	ctx := context.Background()
+	dsn := "file:" + t.Name() + "?mode=memory&cache=shared"
+
+	// the schema is created with foreign keys enforced, as ent requires
+	schemaClient := enttest.Open(t, dialect.SQLite, dsn+"&_fk=1")
+	t.Cleanup(func() { schemaClient.Close() })
+
+	drv, err := entsql.Open(dialect.SQLite, dsn)
+	require.NoError(t, err)
+
+	client := ent.NewClient(ent.Driver(drv))
+	t.Cleanup(func() { client.Close() })
+
+	root := client.Tenant.Create().SetName("root").SetCreatedAt(time.Now().Add(-time.Hour)).SaveX(ctx)
+	formerChild := client.Tenant.Create().SetName("former-child").SaveX(ctx)
+	orphan := client.Tenant.Create().SetName("orphan").SetParentTenantID(gidx.MustNewID(schema.TenantPrefix)).SaveX(ctx)
+	orphanChild := client.Tenant.Create().SetName("orphan-child").SetParent(orphan).SaveX(ctx)
+	dupeOld := client.Tenant.Create().SetName("dupe").SetParent(root).SetCreatedAt(time.Now().Add(-time.Minute)).SaveX(ctx)
+	dupeNew := client.Tenant.Create().SetName("dupe").SetParent(root).SaveX(ctx)
+
+	return client, []*ent.Tenant{root, formerChild, orphan, orphanChild, dupeOld, dupeNew}
+}
+
+func TestFix(t *testing.T) {
+	ctx := context.Background()
+
+	check := func(t *testing.T, client *ent.Client, root *ent.Tenant) *fsck.Report {
+		tenants, err := client.Tenant.Query().All(ctx)
+		require.NoError(t, err)
+
+		return fsck.Check(tenants, fsck.Options{ExpectedRoots: []gidx.PrefixedID{root.ID}})
+	}
+
+	t.Run("detach orphans", func(t *testing.T) {
+		client, tenants := seedFix(t)
+		root, orphan := tenants[0], tenants[2]
+		report := check(t, client, root)
+
+		changes, err := fsck.Fix(ctx, client, report, fsck.FixOptions{Kinds: []fsck.IssueKind{fsck.IssueOrphan, fsck.IssueUnexpectedRoot}})
+		require.NoError(t, err)
+
+		// unexpected roots can't be fixed without somewhere to move them
+		require.Len(t, changes, 1)
+		assert.Equal(t, orphan.ID, changes[0].TenantID)
+		assert.Equal(t, gidx.NullPrefixedID, changes[0].ParentID)
+		assert.False(t, changes[0].Published)
+		assert.Equal(t, map[string]string{"event_sequence": "1", "kind": "TENANT"}, changes[0].SubjectFields)
+
+		detached := client.Tenant.GetX(ctx, orphan.ID)
+		assert.Equal(t, gidx.NullPrefixedID, detached.ParentTenantID)
+		assert.Equal(t, int64(1), detached.EventSequence)
+
+		assert.Equal(t, 2, report.Unresolved())
+	})
+
+	t.Run("reparent", func(t *testing.T) {
+		client, tenants := seedFix(t)
+		root := tenants[0]
+		report := check(t, client, root)
+
+		changes, err := fsck.Fix(ctx, client, report, fsck.FixOptions{
+			Kinds:      []fsck.IssueKind{fsck.IssueOrphan, fsck.IssueUnexpectedRoot},
+			ReparentTo: root.ID,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, changes, 2)
+
+		for _, change := range changes {
+			assert.Equal(t, root.ID, change.ParentID)
+			assert.Equal(t, "parent_tenant_id", change.Field)
+			assert.Equal(t, root.ID, client.Tenant.GetX(ctx, change.TenantID).ParentTenantID)
+		}
+
+		assert.Equal(t, 1, report.Unresolved())
+	})
+
+	t.Run("rename duplicates", func(t *testing.T) {
+		client, tenants := seedFix(t)
+		root, dupeNew := tenants[0], tenants[5]
+		report := check(t, client, root)
+
+		changes, err := fsck.Fix(ctx, client, report, fsck.FixOptions{Kinds: []fsck.IssueKind{fsck.IssueDuplicateName}})
+		require.NoError(t, err)
+
+		require.Len(t, changes, 1)
+		assert.Equal(t, dupeNew.ID, changes[0].TenantID)
+		assert.Equal(t, "name", changes[0].Field)
+		assert.Equal(t, "dupe ("+dupeNew.ID.String()+")", changes[0].CurrentValue)
+		assert.True(t, changes[0].Published)
+
+		assert.Equal(t, changes[0].CurrentValue, client.Tenant.GetX(ctx, dupeNew.ID).Name)
+	})
+
+	t.Run("invalid reparent target", func(t *testing.T) {
+		client, tenants := seedFix(t)
+		root, formerChild, orphan, orphanChild := tenants[0], tenants[1], tenants[2], tenants[3]
+
+		tests := []struct {
+			name   string
+			kinds  []fsck.IssueKind
+			target gidx.PrefixedID
+			err    string
+		}{
+			{
+				name:   "orphan",
+				kinds:  []fsck.IssueKind{fsck.IssueOrphan},
+				target: orphan.ID,
+				err:    "is being moved",
+			},
+			{
+				name:   "moved itself",
+				kinds:  []fsck.IssueKind{fsck.IssueUnexpectedRoot},
+				target: formerChild.ID,
+				err:    "is being moved",
+			},
+			{
+				name:   "descendant of a moved tenant",
+				kinds:  []fsck.IssueKind{fsck.IssueOrphan},
+				target: orphanChild.ID,
+				err:    "is a descendant of " + orphan.ID.String(),
+			},
+			{
+				name:   "missing",
+				kinds:  []fsck.IssueKind{fsck.IssueOrphan},
+				target: gidx.MustNewID(schema.TenantPrefix),
+				err:    "does not exist",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				report := check(t, client, root)
+
+				_, err := fsck.Fix(ctx, client, report, fsck.FixOptions{Kinds: tt.kinds, ReparentTo: tt.target})
+				assert.ErrorIs(t, err, fsck.ErrInvalidReparentTarget)
+				assert.ErrorContains(t, err, tt.err)
+				assert.Equal(t, orphan.ParentTenantID, client.Tenant.GetX(ctx, orphan.ID).ParentTenantID)
+			})
+		}
+	})
+	t.Run("limits", func(t *testing.T) {
+		client, tenants := seedFix(t)
+		root, formerChild, orphan := tenants[0], tenants[1], tenants[2]
+
+		client.Tenant.Create().SetName("orphan-child-2").SetParent(orphan).ExecX(ctx)
+
+		orphanKinds := []fsck.IssueKind{fsck.IssueOrphan}
+		allKinds := []fsck.IssueKind{fsck.IssueOrphan, fsck.IssueUnexpectedRoot}
+
+		tests := []struct {
+			name   string
+			kinds  []fsck.IssueKind
+			target gidx.PrefixedID
+			config limits.Config
+			err    error
+		}{
+			{
+				name:   "too many children",
+				kinds:  allKinds,
+				target: root.ID,
+				config: limits.Config{MaxChildren: 3},
+				err:    limits.ErrMaxChildren,
+			},
+			{
+				name:   "moved children too deep",
+				kinds:  allKinds,
+				target: root.ID,
+				config: limits.Config{MaxDepth: 2},
+				err:    limits.ErrMaxDepth,
+			},
+			{
+				name:   "detached children too deep",
+				kinds:  orphanKinds,
+				config: limits.Config{MaxDepth: 1},
+				err:    limits.ErrMaxDepth,
+			},
+			{
+				name:   "detached tenant has too many children",
+				kinds:  orphanKinds,
+				config: limits.Config{MaxChildren: 1},
+				err:    limits.ErrMaxChildren,
+			},
+			{
+				// last, as the tenants are moved
+				name:   "override allows the move",
+				kinds:  allKinds,
+				target: root.ID,
+				config: limits.Config{MaxChildren: 3, Overrides: []limits.Override{{RootID: root.ID, MaxChildren: 4}}},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				limiter, err := limits.NewLimiter(tt.config)
+				require.NoError(t, err)
+
+				report := check(t, client, root)
+
+				_, err = fsck.Fix(ctx, client, report, fsck.FixOptions{Kinds: tt.kinds, ReparentTo: tt.target, Limiter: limiter})
+				if tt.err == nil {
+					assert.NoError(t, err)
+
+					return
+				}
+
+				assert.ErrorIs(t, err, tt.err)
+
+				// nothing is moved when any move exceeds the limits
+				assert.Equal(t, orphan.ParentTenantID, client.Tenant.GetX(ctx, orphan.ID).ParentTenantID)
+				assert.Equal(t, gidx.NullPrefixedID, client.Tenant.GetX(ctx, formerChild.ID).ParentTenantID)
+			})
+		}
+	})
+
+	t.Run("kinds", func(t *testing.T) {
+		client, tenants := seedFix(t)
+		root := tenants[0]
+
+		orphan := client.Tenant.Create().
+			SetName("project").
+			SetKind(tenant.KindProject).
+			SetParentTenantID(gidx.MustNewID(schema.TenantPrefix)).
+			SaveX(ctx)
+
+		tests := []struct {
+			name   string
+			rules  []kinds.Rule
+			target gidx.PrefixedID
+			err    bool
+		}{
+			{
+				name:   "parent kind not allowed",
+				rules:  []kinds.Rule{{Kind: "project", Parents: []string{"organization"}}},
+				target: root.ID,
+				err:    true,
+			},
+			{
+				name:  "root not allowed",
+				rules: []kinds.Rule{{Kind: "project", Parents: []string{"tenant"}}},
+				err:   true,
+			},
+			{
+				// last, as the tenants are moved
+				name:   "parent kind allowed",
+				rules:  []kinds.Rule{{Kind: "project", Parents: []string{"tenant"}}},
+				target: root.ID,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				graph, err := kinds.NewGraph(kinds.Config{Rules: tt.rules})
+				require.NoError(t, err)
+
+				report := check(t, client, root)
+
+				_, err = fsck.Fix(ctx, client, report, fsck.FixOptions{Kinds: []fsck.IssueKind{fsck.IssueOrphan}, ReparentTo: tt.target, KindGraph: graph})
+				if !tt.err {
+					assert.NoError(t, err)
+					assert.Equal(t, root.ID, client.Tenant.GetX(ctx, orphan.ID).ParentTenantID)
+
+					return
+				}
+
+				assert.ErrorIs(t, err, kinds.ErrKindNotAllowed)
+				assert.Equal(t, orphan.ParentTenantID, client.Tenant.GetX(ctx, orphan.ID).ParentTenantID, "nothing is moved")
+			})
+		}
+	})
+}
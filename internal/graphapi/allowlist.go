@@ -0,0 +1,234 @@
+package graphapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+const (
+	errOperationNotAllowedCode = "OPERATION_NOT_ALLOWED"
+
+	allowListFileExt = ".graphql"
+
+	// allowListCheckCacheSize is the number of query checks remembered so
+	// repeated operations aren't parsed on every request
+	allowListCheckCacheSize = 1000
+)
+
+// ErrEmptyAllowList is returned when an allow list has no operations
+var ErrEmptyAllowList = errors.New("allow list has no operations")
+
+// AllowList is a set of pre-registered operations. When a handler is
+// configured with an allow list any other operation is rejected.
+type AllowList struct {
+	operations map[string]struct{}
+	names      map[string]struct{}
+	checked    graphql.Cache
+}
+
+// LoadAllowList registers all of the operations defined in the .graphql files
+// in dir, subdirectories aren't loaded
+func LoadAllowList(dir string) (*AllowList, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("loading allow list from %s: %w", dir, err)
+	}
+
+	var sources []*ast.Source
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != allowListFileExt {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+
+		input, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("loading allow list from %s: %w", dir, err)
+		}
+
+		sources = append(sources, &ast.Source{Name: path, Input: string(input)})
+	}
+
+	return NewAllowList(sources...)
+}
+
+// NewAllowList registers all of the operations defined in the given sources
+func NewAllowList(sources ...*ast.Source) (*AllowList, error) {
+	l := &AllowList{
+		operations: map[string]struct{}{},
+		names:      map[string]struct{}{},
+		checked:    lru.New(allowListCheckCacheSize),
+	}
+
+	for _, source := range sources {
+		doc, err := parser.ParseQuery(source)
+		if err != nil {
+			return nil, fmt.Errorf("parsing allow list operations in %s: %w", source.Name, err)
+		}
+
+		for _, op := range doc.Operations {
+			l.operations[normalizeOperation(doc, op)] = struct{}{}
+
+			if op.Name != "" {
+				l.names[op.Name] = struct{}{}
+			}
+		}
+	}
+
+	if len(l.operations) == 0 {
+		return nil, ErrEmptyAllowList
+	}
+
+	return l, nil
+}
+
+// Len returns the number of registered operations
+func (l *AllowList) Len() int {
+	return len(l.operations)
+}
+
+// HasName reports whether an operation with the name is registered
+func (l *AllowList) HasName(name string) bool {
+	_, ok := l.names[name]
+
+	return ok
+}
+
+// Allowed reports whether the operation selected by operationName from query
+// is registered. Whitespace and formatting differences are ignored.
+func (l *AllowList) Allowed(ctx context.Context, query, operationName string) bool {
+	sum := sha256.Sum256([]byte(operationName + "\x00" + query))
+	key := hex.EncodeToString(sum[:])
+
+	if allowed, ok := l.checked.Get(ctx, key); ok {
+		return allowed.(bool)
+	}
+
+	allowed := l.allowed(query, operationName)
+
+	l.checked.Add(ctx, key, allowed)
+
+	return allowed
+}
+
+func (l *AllowList) allowed(query, operationName string) bool {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return false
+	}
+
+	var op *ast.OperationDefinition
+
+	switch {
+	case operationName != "":
+		op = doc.Operations.ForName(operationName)
+	case len(doc.Operations) == 1:
+		op = doc.Operations[0]
+	}
+
+	if op == nil {
+		return false
+	}
+
+	_, ok := l.operations[normalizeOperation(doc, op)]
+
+	return ok
+}
+
+// normalizeOperation formats the operation along with the fragments it uses,
+// so the same operation always produces the same text
+func normalizeOperation(doc *ast.QueryDocument, op *ast.OperationDefinition) string {
+	names := map[string]bool{}
+	collectFragments(doc, op.SelectionSet, names)
+
+	single := &ast.QueryDocument{
+		Operations: ast.OperationList{op},
+	}
+
+	for name := range names {
+		single.Fragments = append(single.Fragments, doc.Fragments.ForName(name))
+	}
+
+	sort.Slice(single.Fragments, func(i, j int) bool {
+		return single.Fragments[i].Name < single.Fragments[j].Name
+	})
+
+	var sb strings.Builder
+
+	formatter.NewFormatter(&sb).FormatQueryDocument(single)
+
+	return sb.String()
+}
+
+func collectFragments(doc *ast.QueryDocument, set ast.SelectionSet, names map[string]bool) {
+	for _, selection := range set {
+		switch s := selection.(type) {
+		case *ast.Field:
+			collectFragments(doc, s.SelectionSet, names)
+		case *ast.InlineFragment:
+			collectFragments(doc, s.SelectionSet, names)
+		case *ast.FragmentSpread:
+			if names[s.Name] {
+				continue
+			}
+
+			fragment := doc.Fragments.ForName(s.Name)
+			if fragment == nil {
+				continue
+			}
+
+			names[s.Name] = true
+
+			collectFragments(doc, fragment.SelectionSet, names)
+		}
+	}
+}
+
+// allowListExtension rejects operations which aren't in the allow list
+type allowListExtension struct {
+	list *AllowList
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationParameterMutator
+} = allowListExtension{}
+
+// ExtensionName returns the extension name
+func (allowListExtension) ExtensionName() string {
+	return "AllowList"
+}
+
+// Validate is a noop for the allow list extension
+func (allowListExtension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationParameters returns an error when the operation isn't registered,
+// persisted queries have already been resolved to their query by this point
+func (e allowListExtension) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	if e.list.Allowed(ctx, rawParams.Query, rawParams.OperationName) {
+		return nil
+	}
+
+	err := gqlerror.Errorf("operation is not in the allow list")
+	err.Extensions = map[string]interface{}{"code": errOperationNotAllowedCode}
+
+	return err
+}
@@ -0,0 +1,58 @@
+package graphapi
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+const (
+	defaultMaxDepth         = 15
+	defaultMaxComplexity    = 10000
+	defaultMaxPageSize      = 1000
+	defaultOperationTimeout = 30 * time.Second
+	defaultAPQCacheSize     = 100
+)
+
+// HandlerConfig stores the configuration for the GraphQL handler. A zero
+// value for any of the limits disables that limit.
+type HandlerConfig struct {
+	// MaxDepth is the maximum depth of fields selected by an operation
+	MaxDepth int `mapstructure:"max_depth"`
+	// MaxComplexity is the maximum complexity score of an operation, connection
+	// fields are weighted by the number of nodes requested
+	MaxComplexity int `mapstructure:"max_complexity"`
+	// MaxPageSize is the maximum number of nodes that may be requested from a
+	// connection, it's also used when no page size is requested
+	MaxPageSize int `mapstructure:"max_page_size"`
+	// OperationTimeout is how long an operation may run before it's canceled
+	OperationTimeout time.Duration `mapstructure:"operation_timeout"`
+	// APQCacheSize is the number of automatic persisted queries kept in memory
+	APQCacheSize int `mapstructure:"apq_cache_size"`
+	// AllowListDir is a directory of .graphql files with the only operations
+	// which may be executed, ad-hoc operations are rejected when it's set
+	AllowListDir string `mapstructure:"allow_list_dir"`
+}
+
+// MustViperFlags returns the cobra flags and viper config for the GraphQL handler
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Int("graphql-max-depth", defaultMaxDepth, "maximum depth of a graphql operation, 0 disables the limit")
+	viperx.MustBindFlag(v, "graphql.max_depth", flags.Lookup("graphql-max-depth"))
+
+	flags.Int("graphql-max-complexity", defaultMaxComplexity, "maximum complexity of a graphql operation, 0 disables the limit")
+	viperx.MustBindFlag(v, "graphql.max_complexity", flags.Lookup("graphql-max-complexity"))
+
+	flags.Int("graphql-max-page-size", defaultMaxPageSize, "maximum page size of a graphql connection, 0 disables the limit")
+	viperx.MustBindFlag(v, "graphql.max_page_size", flags.Lookup("graphql-max-page-size"))
+
+	flags.Duration("graphql-operation-timeout", defaultOperationTimeout, "maximum duration of a graphql operation, 0 disables the timeout")
+	viperx.MustBindFlag(v, "graphql.operation_timeout", flags.Lookup("graphql-operation-timeout"))
+
+	flags.Int("graphql-apq-cache-size", defaultAPQCacheSize, "number of automatic persisted queries kept in memory")
+	viperx.MustBindFlag(v, "graphql.apq_cache_size", flags.Lookup("graphql-apq-cache-size"))
+
+	flags.String("graphql-allow-list-dir", "", "directory of .graphql files with the only operations allowed to run")
+	viperx.MustBindFlag(v, "graphql.allow_list_dir", flags.Lookup("graphql-allow-list-dir"))
+}
@@ -4,7 +4,54 @@ package graphapi
 // will be copied through when generating and any unknown code will be moved to the end.
 // Code generated by github.com/99designs/gqlgen version v0.17.34
 
+import (
+	"context"
+
+	"entgo.io/contrib/entgql"
+	"github.com/99designs/gqlgen/graphql"
+	"go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/x/gidx"
+)
+
+// Parent is the resolver for the parent field.
+func (r *tenantResolver) Parent(ctx context.Context, obj *generated.Tenant) (*generated.Tenant, error) {
+	if obj.ParentTenantID == gidx.NullPrefixedID {
+		return nil, nil
+	}
+
+	if parent, err := obj.Edges.ParentOrErr(); !generated.IsNotLoaded(err) {
+		return parent, generated.MaskNotFound(err)
+	}
+
+	parent, err := r.getTenant(ctx, obj.ParentTenantID)
+
+	return parent, generated.MaskNotFound(err)
+}
+
+// Children is the resolver for the children field.
+func (r *tenantResolver) Children(ctx context.Context, obj *generated.Tenant, after *entgql.Cursor[gidx.PrefixedID], first *int, before *entgql.Cursor[gidx.PrefixedID], last *int, orderBy *generated.TenantOrder, where *generated.TenantWhereInput) (*generated.TenantConnection, error) {
+	loaders := loadersFromContext(ctx)
+	alias := graphql.GetFieldContext(ctx).Field.Alias
+
+	if _, collected := obj.ChildrenTotalCount(alias); collected || loaders == nil || where != nil || !onlyTotalCount(ctx) {
+		return obj.Children(ctx, after, first, before, last, orderBy, where)
+	}
+
+	// the total count doesn't depend on the cursors, so it can be batched
+	// with the counts of the other tenants in the request
+	count, _, err := loaders.childrenCount.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &generated.TenantConnection{Edges: []*generated.TenantEdge{}, TotalCount: count}, nil
+}
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
+// Tenant returns TenantResolver implementation.
+func (r *Resolver) Tenant() TenantResolver { return &tenantResolver{r} }
+
 type queryResolver struct{ *Resolver }
+type tenantResolver struct{ *Resolver }
@@ -13,7 +13,7 @@ import (
 
 // FindTenantByID is the resolver for the findTenantByID field.
 func (r *entityResolver) FindTenantByID(ctx context.Context, id gidx.PrefixedID) (*generated.Tenant, error) {
-	return r.client.Tenant.Get(ctx, id)
+	return r.getTenant(ctx, id)
 }
 
 // Entity returns EntityResolver implementation.
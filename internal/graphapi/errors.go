@@ -0,0 +1,67 @@
+package graphapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/kinds"
+	"go.infratographer.com/tenant-api/internal/limits"
+	"go.infratographer.com/tenant-api/internal/validation"
+)
+
+const (
+	errValidationFailedCode = "VALIDATION_FAILED"
+	errLimitExceededCode    = "TENANT_LIMIT_EXCEEDED"
+)
+
+// presentError presents validation errors with the field which failed in
+// their extensions, so clients can show the error next to the field's input,
+// tenant kinds which aren't allowed under their parent as a validation error
+// of the kind field, and tenant limit errors with the limit which was exceeded
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+
+	switch {
+	case errors.Is(err, limits.ErrMaxDepth):
+		return withExtensions(gqlErr, errLimitExceededCode, "limit", limits.LimitDepth)
+	case errors.Is(err, limits.ErrMaxChildren):
+		return withExtensions(gqlErr, errLimitExceededCode, "limit", limits.LimitChildren)
+	}
+
+	var (
+		validationErr *generated.ValidationError
+		fieldErr      *validation.FieldError
+	)
+
+	field := ""
+
+	switch {
+	case errors.As(err, &validationErr):
+		field = validationErr.Name
+	case errors.As(err, &fieldErr):
+		field = fieldErr.Field
+	case errors.Is(err, kinds.ErrKindNotAllowed):
+		field = tenant.FieldKind
+	default:
+		return gqlErr
+	}
+
+	return withExtensions(gqlErr, errValidationFailedCode, "field", field)
+}
+
+// withExtensions sets the error's code and an extension describing it
+func withExtensions(gqlErr *gqlerror.Error, code, key, value string) *gqlerror.Error {
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]interface{}{}
+	}
+
+	gqlErr.Extensions["code"] = code
+	gqlErr.Extensions[key] = value
+
+	return gqlErr
+}
@@ -0,0 +1,42 @@
+package graphapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+
+	"go.infratographer.com/tenant-api/internal/graphapi"
+)
+
+func TestValidationErrorExtensions(t *testing.T) {
+	ctx := context.WithValue(context.Background(), permissions.CheckerCtxKey, permissions.DefaultAllowChecker)
+
+	body := `{"query": "mutation { tenantCreate(input: {name: \"   \"}) { tenant { id } } }"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	limitedTestHandler(graphapi.HandlerConfig{}).ServeHTTP(w, req)
+
+	var resp struct {
+		Errors []struct {
+			Message    string                 `json:"message"`
+			Extensions map[string]interface{} `json:"extensions"`
+		} `json:"errors"`
+	}
+
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	require.Len(t, resp.Errors, 1)
+
+	assert.Contains(t, resp.Errors[0].Message, "too short")
+	assert.Equal(t, "VALIDATION_FAILED", resp.Errors[0].Extensions["code"])
+	assert.Equal(t, "name", resp.Errors[0].Extensions["field"])
+}
@@ -24,3 +24,27 @@ type TenantUpdatePayload struct {
 	// The updated tenant.
 	Tenant *generated.Tenant `json:"tenant"`
 }
+
+// Return response from webhookDeliveryRetry.
+type WebhookDeliveryRetryPayload struct {
+	// The delivery, pending another attempt.
+	WebhookDelivery *generated.WebhookDelivery `json:"webhookDelivery"`
+}
+
+// Return response from webhookSubscriptionCreate.
+type WebhookSubscriptionCreatePayload struct {
+	// The created webhook subscription.
+	WebhookSubscription *generated.WebhookSubscription `json:"webhookSubscription"`
+}
+
+// Return response from webhookSubscriptionDelete.
+type WebhookSubscriptionDeletePayload struct {
+	// The ID of the deleted webhook subscription.
+	DeletedID gidx.PrefixedID `json:"deletedID"`
+}
+
+// Return response from webhookSubscriptionUpdate.
+type WebhookSubscriptionUpdatePayload struct {
+	// The updated webhook subscription.
+	WebhookSubscription *generated.WebhookSubscription `json:"webhookSubscription"`
+}
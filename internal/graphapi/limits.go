@@ -0,0 +1,242 @@
+package graphapi
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"entgo.io/contrib/entgql"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/tenant-api/internal/ent/generated"
+)
+
+const (
+	errDepthLimitCode    = "DEPTH_LIMIT_EXCEEDED"
+	errPageSizeLimitCode = "PAGE_SIZE_LIMIT_EXCEEDED"
+
+	// unboundedConnectionWeight is used to score connections without a page
+	// size when there isn't a max page size to fall back to
+	unboundedConnectionWeight = 100
+)
+
+// complexity returns the complexity functions for the schema, connections are
+// weighted by the number of nodes they may return.
+func complexity(cfg HandlerConfig) ComplexityRoot {
+	var c ComplexityRoot
+
+	c.Tenant.Children = func(childComplexity int, _ *entgql.Cursor[gidx.PrefixedID], first *int, _ *entgql.Cursor[gidx.PrefixedID], last *int, _ *generated.TenantOrder, _ *generated.TenantWhereInput) int {
+		return weigh(cfg.MaxComplexity, childComplexity, connectionWeight(cfg.MaxPageSize, first, last))
+	}
+
+	c.Query.WebhookSubscriptions = func(childComplexity int, _ *gidx.PrefixedID, _ *entgql.Cursor[gidx.PrefixedID], first *int, _ *entgql.Cursor[gidx.PrefixedID], last *int, _ *generated.WebhookSubscriptionOrder) int {
+		return weigh(cfg.MaxComplexity, childComplexity, connectionWeight(cfg.MaxPageSize, first, last))
+	}
+
+	c.WebhookSubscription.Deliveries = func(childComplexity int, _ *entgql.Cursor[gidx.PrefixedID], first *int, _ *entgql.Cursor[gidx.PrefixedID], last *int, _ *generated.WebhookDeliveryOrder, _ *generated.WebhookDeliveryWhereInput) int {
+		return weigh(cfg.MaxComplexity, childComplexity, connectionWeight(cfg.MaxPageSize, first, last))
+	}
+
+	c.Query.__resolve_entities = func(childComplexity int, representations []map[string]interface{}) int {
+		return weigh(cfg.MaxComplexity, childComplexity, len(representations))
+	}
+
+	return c
+}
+
+// weigh returns the complexity of a field whose selections are resolved
+// weight times. It saturates just over the max complexity, so a huge page size
+// can't overflow to a complexity under the limit.
+func weigh(maxComplexity, childComplexity, weight int) int {
+	saturated := math.MaxInt
+	if maxComplexity > 0 && maxComplexity < math.MaxInt {
+		saturated = maxComplexity + 1
+	}
+
+	if childComplexity > 0 && weight > (saturated-1)/childComplexity {
+		return saturated
+	}
+
+	return 1 + childComplexity*weight
+}
+
+// connectionWeight returns the number of nodes a connection may return, which
+// is the larger page size requested. Negative page sizes are rejected when the
+// connection is resolved and weigh nothing, so they can't lower the
+// complexity of the rest of the operation. Page sizes over the max page size
+// are rejected when the connection is resolved, so they weigh the max.
+func connectionWeight(maxPageSize int, first, last *int) int {
+	switch {
+	case first != nil || last != nil:
+		var weight int
+
+		for _, size := range []*int{first, last} {
+			if size != nil && *size > weight {
+				weight = *size
+			}
+		}
+
+		if maxPageSize > 0 && weight > maxPageSize {
+			return maxPageSize
+		}
+
+		return weight
+	case maxPageSize > 0:
+		return maxPageSize
+	default:
+		return unboundedConnectionWeight
+	}
+}
+
+// depthLimit rejects operations which select fields nested deeper than the limit
+type depthLimit struct {
+	max int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = depthLimit{}
+
+// ExtensionName returns the extension name
+func (depthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+// Validate is a noop for the depth limit extension
+func (depthLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationContext returns an error when the operation is too deep
+func (l depthLimit) MutateOperationContext(_ context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	if rc.Operation == nil {
+		return nil
+	}
+
+	if depth := selectionDepth(rc.Operation.SelectionSet); depth > l.max {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, l.max)
+		err.Extensions = map[string]interface{}{"code": errDepthLimitCode}
+
+		return err
+	}
+
+	return nil
+}
+
+// selectionDepth returns the deepest level of fields in the selection set,
+// fragments don't add a level and introspection fields are ignored.
+func selectionDepth(set ast.SelectionSet) int {
+	var deepest int
+
+	for _, selection := range set {
+		var depth int
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+
+			depth = 1 + selectionDepth(s.SelectionSet)
+		case *ast.InlineFragment:
+			depth = selectionDepth(s.SelectionSet)
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				depth = selectionDepth(s.Definition.SelectionSet)
+			}
+		}
+
+		if depth > deepest {
+			deepest = depth
+		}
+	}
+
+	return deepest
+}
+
+// pageSizeLimit rejects connection fields requesting more nodes than the limit
+// and limits connections without a page size to the max page size
+type pageSizeLimit struct {
+	max int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = pageSizeLimit{}
+
+// ExtensionName returns the extension name
+func (pageSizeLimit) ExtensionName() string {
+	return "PageSizeLimit"
+}
+
+// Validate is a noop for the page size limit extension
+func (pageSizeLimit) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptField checks the page size arguments of connection fields
+func (l pageSizeLimit) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Args == nil {
+		return next(ctx)
+	}
+
+	first, hasFirst := fc.Args["first"].(*int)
+	last, hasLast := fc.Args["last"].(*int)
+
+	// only connection fields have page size arguments
+	if !hasFirst || !hasLast {
+		return next(ctx)
+	}
+
+	for name, size := range map[string]*int{"first": first, "last": last} {
+		if size != nil && *size > l.max {
+			err := gqlerror.Errorf("%s of %d exceeds the max page size of %d", name, *size, l.max)
+			err.Path = fc.Path()
+			err.Extensions = map[string]interface{}{"code": errPageSizeLimitCode}
+
+			return nil, err
+		}
+	}
+
+	if first == nil && last == nil {
+		size := l.max
+		fc.Args["first"] = &size
+	}
+
+	return next(ctx)
+}
+
+// operationTimeout cancels operations which run longer than the timeout
+type operationTimeout struct {
+	timeout time.Duration
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = operationTimeout{}
+
+// ExtensionName returns the extension name
+func (operationTimeout) ExtensionName() string {
+	return "OperationTimeout"
+}
+
+// Validate is a noop for the operation timeout extension
+func (operationTimeout) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse executes the operation with a deadline
+func (t operationTimeout) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	return next(ctx)
+}
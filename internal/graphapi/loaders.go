@@ -0,0 +1,216 @@
+package graphapi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/x/gidx"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+)
+
+const (
+	// loaderWait is how long a loader collects keys before fetching them,
+	// sibling fields are resolved concurrently so they join the same batch
+	loaderWait = 2 * time.Millisecond
+	// loaderMaxBatch bounds the number of keys fetched in one statement
+	loaderMaxBatch = 100
+)
+
+type loadersCtxKey struct{}
+
+// loaders batch the lookups made while resolving a request so each level of
+// a query costs one SQL statement instead of one per node
+type loaders struct {
+	tenants       *loader[gidx.PrefixedID, *ent.Tenant]
+	childrenCount *loader[gidx.PrefixedID, int]
+}
+
+func (r *Resolver) newLoaders() *loaders {
+	return &loaders{
+		tenants:       newLoader(r.loadTenants),
+		childrenCount: newLoader(r.loadChildrenCounts),
+	}
+}
+
+// loadersFromContext returns the request's loaders, or nil when the request
+// wasn't served through the dataloader middleware
+func loadersFromContext(ctx context.Context) *loaders {
+	l, _ := ctx.Value(loadersCtxKey{}).(*loaders)
+
+	return l
+}
+
+// dataloaders adds request scoped loaders to the request context
+func (h *Handler) dataloaders(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+
+		c.SetRequest(req.WithContext(context.WithValue(req.Context(), loadersCtxKey{}, h.r.newLoaders())))
+
+		return next(c)
+	}
+}
+
+func (r *Resolver) loadTenants(ctx context.Context, ids []gidx.PrefixedID) (map[gidx.PrefixedID]*ent.Tenant, error) {
+	if r.useCache(ctx) {
+		return r.cache.GetMany(ctx, ids)
+	}
+
+	tenants, err := r.client.Tenant.Query().Where(tenant.IDIn(ids...)).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[gidx.PrefixedID]*ent.Tenant, len(tenants))
+
+	for _, t := range tenants {
+		found[t.ID] = t
+	}
+
+	return found, nil
+}
+
+func (r *Resolver) loadChildrenCounts(ctx context.Context, ids []gidx.PrefixedID) (map[gidx.PrefixedID]int, error) {
+	var counts []struct {
+		ParentTenantID gidx.PrefixedID `sql:"parent_tenant_id"`
+		Count          int             `sql:"count"`
+	}
+
+	err := r.client.Tenant.Query().
+		Where(tenant.ParentTenantIDIn(ids...)).
+		GroupBy(tenant.FieldParentTenantID).
+		Aggregate(ent.Count()).
+		Scan(ctx, &counts)
+	if err != nil {
+		return nil, err
+	}
+
+	// tenants without children aren't returned by the query
+	found := make(map[gidx.PrefixedID]int, len(ids))
+
+	for _, id := range ids {
+		found[id] = 0
+	}
+
+	for _, c := range counts {
+		found[c.ParentTenantID] = c.Count
+	}
+
+	return found, nil
+}
+
+// loader batches the keys loaded concurrently into a single fetch. Results
+// are kept for the life of the loader, which is a single request.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	batch   *loaderBatch[K, V]
+	results map[K]*loaderBatch[K, V]
+}
+
+type loaderBatch[K comparable, V any] struct {
+	keys   []K
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		results: map[K]*loaderBatch[K, V]{},
+	}
+}
+
+// Load returns the value for the key and whether it was found. The batch is
+// fetched with the context of the first key added to it.
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, bool, error) {
+	l.mu.Lock()
+
+	b, ok := l.results[key]
+	if !ok {
+		b = l.add(ctx, key)
+	}
+
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		var zero V
+
+		return zero, false, ctx.Err()
+	}
+
+	if b.err != nil {
+		var zero V
+
+		return zero, false, b.err
+	}
+
+	v, found := b.values[key]
+
+	return v, found, nil
+}
+
+// add adds the key to the pending batch, starting a new batch when there
+// isn't one. l.mu must be held.
+func (l *loader[K, V]) add(ctx context.Context, key K) *loaderBatch[K, V] {
+	b := l.batch
+
+	if b == nil {
+		b = &loaderBatch[K, V]{done: make(chan struct{})}
+		l.batch = b
+
+		time.AfterFunc(loaderWait, func() {
+			l.dispatch(ctx, b)
+		})
+	}
+
+	b.keys = append(b.keys, key)
+	l.results[key] = b
+
+	if len(b.keys) >= loaderMaxBatch {
+		// dispatch is a no-op for a batch that was already fetched, so the
+		// pending timer can be left to fire
+		go l.dispatch(ctx, b)
+	}
+
+	return b
+}
+
+func (l *loader[K, V]) dispatch(ctx context.Context, b *loaderBatch[K, V]) {
+	l.mu.Lock()
+
+	if l.batch != b {
+		l.mu.Unlock()
+
+		return
+	}
+
+	l.batch = nil
+
+	l.mu.Unlock()
+
+	b.values, b.err = l.fetch(ctx, b.keys)
+
+	close(b.done)
+}
+
+// onlyTotalCount reports whether totalCount is the only field selected on
+// the connection being resolved
+func onlyTotalCount(ctx context.Context) bool {
+	for _, f := range graphql.CollectFieldsCtx(ctx, nil) {
+		if f.Name != "totalCount" && f.Name != "__typename" {
+			return false
+		}
+	}
+
+	return true
+}
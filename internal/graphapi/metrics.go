@@ -0,0 +1,77 @@
+package graphapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"go.infratographer.com/tenant-api/internal/metrics"
+)
+
+const (
+	anonymousOperation = "anonymous"
+	otherOperation     = "other"
+)
+
+// metricsExtension records prometheus metrics for each GraphQL operation.
+// Operation names are chosen by clients, so they're only used as labels when
+// they're registered in the allow list, other names are recorded as other.
+type metricsExtension struct {
+	allowList *AllowList
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = metricsExtension{}
+
+// ExtensionName returns the extension name
+func (metricsExtension) ExtensionName() string {
+	return "Metrics"
+}
+
+// Validate is a noop for the metrics extension
+func (metricsExtension) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse records the duration and errors of the operation
+func (e metricsExtension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+
+	if !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+
+	opCtx := graphql.GetOperationContext(ctx)
+
+	name := opCtx.OperationName
+	opType := ""
+
+	if opCtx.Operation != nil {
+		opType = string(opCtx.Operation.Operation)
+
+		if name == "" {
+			name = opCtx.Operation.Name
+		}
+	}
+
+	switch {
+	case name == "":
+		name = anonymousOperation
+	case e.allowList == nil || !e.allowList.HasName(name):
+		name = otherOperation
+	}
+
+	start := opCtx.Stats.OperationStart
+	if start.IsZero() {
+		start = time.Now()
+	}
+
+	failed := resp == nil || len(resp.Errors) != 0
+
+	metrics.ObserveGraphQLOperation(name, opType, time.Since(start), failed)
+
+	return resp
+}
@@ -6,11 +6,13 @@ import (
 	"github.com/brianvoe/gofakeit/v6"
 
 	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
 )
 
 type TenantBuilder struct {
 	Name        string
 	Description string
+	Kind        tenant.Kind
 	Parent      *ent.Tenant
 }
 
@@ -28,6 +30,10 @@ func (b TenantBuilder) MustNew(ctx context.Context) *ent.Tenant {
 		Description: &b.Description,
 	}
 
+	if b.Kind != "" {
+		input.Kind = &b.Kind
+	}
+
 	if b.Parent != nil {
 		input.ParentID = &b.Parent.ID
 	}
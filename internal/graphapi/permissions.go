@@ -1,9 +1,21 @@
 package graphapi
 
+// Permission actions checked before tenants are accessed, these are shared
+// by every API surface so a role grants the same access everywhere.
 const (
-	actionTenantCreate = "tenant_create"
-	actionTenantUpdate = "tenant_update"
-	actionTenantDelete = "tenant_delete"
-	actionTenantList   = "tenant_list"
-	actionTenantGet    = "tenant_get"
+	ActionTenantCreate = "tenant_create"
+	ActionTenantUpdate = "tenant_update"
+	ActionTenantDelete = "tenant_delete"
+	ActionTenantList   = "tenant_list"
+	ActionTenantGet    = "tenant_get"
+)
+
+// Permission actions checked before webhook subscriptions are accessed, they
+// are checked on the tenant a subscription is scoped to.
+const (
+	ActionWebhookSubscriptionCreate = "webhook_subscription_create"
+	ActionWebhookSubscriptionUpdate = "webhook_subscription_update"
+	ActionWebhookSubscriptionDelete = "webhook_subscription_delete"
+	ActionWebhookSubscriptionList   = "webhook_subscription_list"
+	ActionWebhookSubscriptionGet    = "webhook_subscription_get"
 )
@@ -14,6 +14,7 @@ import (
 	"go.infratographer.com/x/events"
 	"go.infratographer.com/x/gidx"
 
+	"go.infratographer.com/tenant-api/internal/pubsub"
 	"go.infratographer.com/tenant-api/internal/testclient"
 )
 
@@ -35,9 +36,12 @@ func TestTenantPubsub(t *testing.T) {
 	require.NoError(t, err)
 
 	// create a root tenant and ensure fields are set
+	kind := testclient.TenantKindOrganization
+
 	rootResp, err := graphC.TenantCreate(ctx, testclient.CreateTenantInput{
 		Name:        name,
 		Description: &description,
+		Kind:        &kind,
 	})
 	require.NoError(t, err)
 
@@ -48,10 +52,12 @@ func TestTenantPubsub(t *testing.T) {
 	assert.Equal(t, "tenant-api-test", msg.Source)
 	assert.Equal(t, rootTenant.ID, msg.SubjectID)
 	assert.Empty(t, msg.AdditionalSubjectIDs)
-	// expect created_at, updated_at, name, and description changeset
-	assert.Len(t, msg.FieldChanges, 4)
+	assert.Equal(t, "1", msg.SubjectFields[pubsub.SequenceField])
+	assert.Equal(t, "ORGANIZATION", msg.SubjectFields["kind"])
+	// expect created_at, updated_at, name, description and kind changeset
+	assert.Len(t, msg.FieldChanges, 5)
 
-	var createdAtVisited, updatedAtVisited, nameVisited, descriptionVisited bool
+	var createdAtVisited, updatedAtVisited, nameVisited, descriptionVisited, kindVisited bool
 
 	for _, change := range msg.FieldChanges {
 		assert.Empty(t, change.PreviousValue)
@@ -75,6 +81,10 @@ func TestTenantPubsub(t *testing.T) {
 			descriptionVisited = true
 
 			assert.EqualValues(t, description, change.CurrentValue)
+		case "kind":
+			kindVisited = true
+
+			assert.EqualValues(t, "ORGANIZATION", change.CurrentValue)
 		default:
 			assert.Fail(t, "unexpected field in changeset %s")
 			t.Fail()
@@ -85,6 +95,7 @@ func TestTenantPubsub(t *testing.T) {
 	assert.True(t, updatedAtVisited)
 	assert.True(t, nameVisited)
 	assert.True(t, descriptionVisited)
+	assert.True(t, kindVisited)
 
 	// Add a child tenant with no description
 	childResp, err := graphC.TenantCreate(ctx, testclient.CreateTenantInput{
@@ -103,12 +114,15 @@ func TestTenantPubsub(t *testing.T) {
 	assert.Equal(t, "tenant-api-test", msg.Source)
 	assert.Equal(t, childTnt.ID, msg.SubjectID)
 	assert.EqualValues(t, []gidx.PrefixedID{rootTenant.ID}, msg.AdditionalSubjectIDs)
-	// expect created_at, updated_at, name, and description changeset
-	assert.Len(t, msg.FieldChanges, 4)
+	assert.Equal(t, "1", msg.SubjectFields[pubsub.SequenceField])
+	assert.Equal(t, "TENANT", msg.SubjectFields["kind"], "tenants have the default kind when it isn't set")
+	// expect created_at, updated_at, name, parent_tenant_id and kind changeset
+	assert.Len(t, msg.FieldChanges, 5)
 
 	createdAtVisited = false
 	updatedAtVisited = false
 	nameVisited = false
+	kindVisited = false
 
 	var parentIDVisited bool
 
@@ -134,6 +148,10 @@ func TestTenantPubsub(t *testing.T) {
 			parentIDVisited = true
 
 			assert.EqualValues(t, rootTenant.ID.String(), change.CurrentValue)
+		case "kind":
+			kindVisited = true
+
+			assert.EqualValues(t, "TENANT", change.CurrentValue)
 		default:
 			assert.Fail(t, fmt.Sprintf("unexpected field in changeset %s", change.Field))
 			t.Fail()
@@ -144,6 +162,7 @@ func TestTenantPubsub(t *testing.T) {
 	assert.True(t, updatedAtVisited)
 	assert.True(t, nameVisited)
 	assert.True(t, parentIDVisited)
+	assert.True(t, kindVisited)
 
 	// Update the tenant
 	newName := gofakeit.DomainName()
@@ -158,6 +177,8 @@ func TestTenantPubsub(t *testing.T) {
 	assert.Equal(t, "tenant-api-test", msg.Source)
 	assert.Equal(t, childTnt.ID, msg.SubjectID)
 	assert.EqualValues(t, []gidx.PrefixedID{rootTenant.ID}, msg.AdditionalSubjectIDs)
+	assert.Equal(t, "2", msg.SubjectFields[pubsub.SequenceField], "every change increments the sequence")
+	assert.Equal(t, "TENANT", msg.SubjectFields["kind"], "the kind is published when it doesn't change")
 	// expect updated_at, and name changeset
 	assert.Len(t, msg.FieldChanges, 2)
 
@@ -197,8 +218,15 @@ func TestTenantPubsub(t *testing.T) {
 	assert.Equal(t, "tenant-api-test", msg.Source)
 	assert.Equal(t, childTnt.ID, msg.SubjectID)
 	assert.EqualValues(t, []gidx.PrefixedID{rootTenant.ID}, msg.AdditionalSubjectIDs)
-	// expect created_at, updated_at, name, and description changeset
-	assert.Len(t, msg.FieldChanges, 0)
+	assert.Equal(t, "3", msg.SubjectFields[pubsub.SequenceField])
+	assert.Equal(t, "TENANT", msg.SubjectFields["kind"])
+	// expect the deleted tenant's final values as previous values
+	assertDeleteSnapshot(t, msg, map[string]string{
+		"name":             newName,
+		"description":      "",
+		"parent_tenant_id": rootTenant.ID.String(),
+		"kind":             "TENANT",
+	})
 
 	// delete the root tenant
 	_, err = graphC.TenantDelete(ctx, rootTenant.ID)
@@ -210,8 +238,39 @@ func TestTenantPubsub(t *testing.T) {
 	assert.Equal(t, "tenant-api-test", msg.Source)
 	assert.Equal(t, rootTenant.ID, msg.SubjectID)
 	assert.Empty(t, msg.AdditionalSubjectIDs)
-	// expect created_at, updated_at, name, and description changeset
-	assert.Len(t, msg.FieldChanges, 0)
+	assert.Equal(t, "2", msg.SubjectFields[pubsub.SequenceField], "a child's changes don't change its parent's sequence")
+	assert.Equal(t, "ORGANIZATION", msg.SubjectFields["kind"])
+	assertDeleteSnapshot(t, msg, map[string]string{
+		"name":             name,
+		"description":      description,
+		"parent_tenant_id": "",
+		"kind":             "ORGANIZATION",
+	})
+}
+
+// assertDeleteSnapshot checks a delete message carries the deleted tenant's
+// fields as previous values, along with its timestamps
+func assertDeleteSnapshot(t *testing.T, msg events.ChangeMessage, expected map[string]string) {
+	t.Helper()
+
+	// created_at, updated_at and the expected fields
+	require.Len(t, msg.FieldChanges, len(expected)+2)
+
+	for _, change := range msg.FieldChanges {
+		assert.Empty(t, change.CurrentValue)
+
+		switch change.Field {
+		case "created_at", "updated_at":
+			ts, err := time.Parse(time.RFC3339, change.PreviousValue)
+			assert.NoError(t, err)
+			assert.WithinDuration(t, time.Now(), ts, 10*time.Second)
+		default:
+			value, ok := expected[change.Field]
+			if assert.True(t, ok, "unexpected field in changeset %s", change.Field) {
+				assert.Equal(t, value, change.PreviousValue, change.Field)
+			}
+		}
+	}
 }
 
 func getChangeMessage(t *testing.T, messages <-chan *message.Message) (msg events.ChangeMessage) {
@@ -220,6 +279,8 @@ func getChangeMessage(t *testing.T, messages <-chan *message.Message) (msg event
 	case message := <-messages:
 		msg, err = events.UnmarshalChangeMessage(message.Payload)
 		require.NoError(t, err)
+		// sequenced changes are published with deterministic IDs
+		assert.Equal(t, pubsub.MessageID("tenant", msg), message.UUID)
 		assert.True(t, message.Ack())
 
 		return msg
@@ -0,0 +1,58 @@
+package graphapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"go.infratographer.com/tenant-api/internal/database"
+)
+
+const (
+	// ConsistencyHeader lets a request opt out of stale reads, queries sent
+	// with the value strong are served by the primary database
+	ConsistencyHeader = "X-Read-Consistency"
+
+	strongConsistency = "strong"
+)
+
+// staleReads allows queries to be served from the read pool. Mutations, and
+// the queries that resolve their results, always use the primary so callers
+// read their own writes.
+type staleReads struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = staleReads{}
+
+// ExtensionName returns the extension name
+func (staleReads) ExtensionName() string {
+	return "StaleReads"
+}
+
+// Validate is a noop for the stale reads extension
+func (staleReads) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse marks query operations as allowing stale reads
+func (staleReads) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+
+	oc := graphql.GetOperationContext(ctx)
+
+	if oc.Operation == nil || oc.Operation.Operation != ast.Query {
+		return next(ctx)
+	}
+
+	if strings.EqualFold(oc.Headers.Get(ConsistencyHeader), strongConsistency) {
+		return next(ctx)
+	}
+
+	return next(database.WithStaleReads(ctx))
+}
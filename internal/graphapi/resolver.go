@@ -1,16 +1,25 @@
 package graphapi
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/labstack/echo/v4"
 	"github.com/wundergraph/graphql-go-tools/pkg/playground"
+	"go.infratographer.com/x/gidx"
 	"go.infratographer.com/x/gqlgenx/oteltracing"
 	"go.uber.org/zap"
 
+	"go.infratographer.com/tenant-api/internal/database"
 	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/tenantcache"
 )
 
 // This file will not be regenerated automatically.
@@ -24,18 +33,68 @@ var (
 	graphFullPath = fmt.Sprintf("/%s", graphPath)
 )
 
+const (
+	queryCacheSize = 1000
+
+	websocketKeepAlivePingInterval = 10 * time.Second
+)
+
 // Resolver provides a graph response resolver
 type Resolver struct {
 	client *ent.Client
 	logger *zap.SugaredLogger
+	cache  *tenantcache.Cache
+}
+
+// ResolverOption configures the resolver
+type ResolverOption func(*Resolver)
+
+// WithCache looks up tenants by ID through the cache when the operation
+// allows stale reads
+func WithCache(cache *tenantcache.Cache) ResolverOption {
+	return func(r *Resolver) {
+		r.cache = cache
+	}
 }
 
 // NewResolver returns a resolver configured with the given ent client
-func NewResolver(client *ent.Client, logger *zap.SugaredLogger) *Resolver {
-	return &Resolver{
+func NewResolver(client *ent.Client, logger *zap.SugaredLogger, opts ...ResolverOption) *Resolver {
+	r := &Resolver{
 		client: client,
 		logger: logger,
 	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// useCache reports whether tenants may be read from the cache, mutations
+// and requests for strong consistency always read the database
+func (r *Resolver) useCache(ctx context.Context) bool {
+	return r.cache != nil && database.StaleReadsAllowed(ctx)
+}
+
+// getTenant returns the tenant, batched with the request's other lookups
+// when it has loaders, and from the cache when it may be used
+func (r *Resolver) getTenant(ctx context.Context, id gidx.PrefixedID) (*ent.Tenant, error) {
+	if loaders := loadersFromContext(ctx); loaders != nil {
+		t, found, err := loaders.tenants.Load(ctx, id)
+		if err != nil || found {
+			return t, err
+		}
+
+		// a missing tenant falls through to be looked up directly, which
+		// returns ent's not found error
+	}
+
+	if r.useCache(ctx) {
+		return r.cache.Get(ctx, id)
+	}
+
+	return r.client.Tenant.Get(ctx, id)
 }
 
 // Handler is an http handler wrapping a Resolver
@@ -46,17 +105,104 @@ type Handler struct {
 	middleware     []echo.MiddlewareFunc
 }
 
+// HandlerOption configures the graph handler
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	config    HandlerConfig
+	apqCache  graphql.Cache
+	allowList *AllowList
+}
+
+// WithHandlerConfig sets the limits applied to graph requests
+func WithHandlerConfig(cfg HandlerConfig) HandlerOption {
+	return func(o *handlerOptions) {
+		o.config = cfg
+	}
+}
+
+// WithAPQCache sets the cache used to store automatic persisted queries, by
+// default an in-memory LRU cache sized by HandlerConfig.APQCacheSize is used
+func WithAPQCache(cache graphql.Cache) HandlerOption {
+	return func(o *handlerOptions) {
+		o.apqCache = cache
+	}
+}
+
+// WithAllowList only executes operations registered in the allow list, any
+// other operation is rejected
+func WithAllowList(list *AllowList) HandlerOption {
+	return func(o *handlerOptions) {
+		o.allowList = list
+	}
+}
+
 // Handler returns an http handler for a graph resolver
-func (r *Resolver) Handler(withPlayground bool, middleware []echo.MiddlewareFunc) *Handler {
-	srv := handler.NewDefaultServer(
+func (r *Resolver) Handler(withPlayground bool, middleware []echo.MiddlewareFunc, opts ...HandlerOption) *Handler {
+	options := handlerOptions{}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	cfg := options.config
+
+	if options.apqCache == nil {
+		size := cfg.APQCacheSize
+		if size <= 0 {
+			size = defaultAPQCacheSize
+		}
+
+		options.apqCache = lru.New(size)
+	}
+
+	srv := handler.New(
 		NewExecutableSchema(
 			Config{
-				Resolvers: r,
+				Resolvers:  r,
+				Complexity: complexity(cfg),
 			},
 		),
 	)
 
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: websocketKeepAlivePingInterval,
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{})
+
+	srv.SetQueryCache(lru.New(queryCacheSize))
+	srv.SetErrorPresenter(presentError)
+
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.AutomaticPersistedQuery{Cache: options.apqCache})
+
+	// the allow list must be checked after persisted queries are resolved
+	if options.allowList != nil {
+		srv.Use(allowListExtension{list: options.allowList})
+	}
+
 	srv.Use(oteltracing.Tracer{})
+	srv.Use(metricsExtension{allowList: options.allowList})
+	srv.Use(staleReads{})
+
+	if cfg.MaxDepth > 0 {
+		srv.Use(depthLimit{max: cfg.MaxDepth})
+	}
+
+	if cfg.MaxComplexity > 0 {
+		srv.Use(extension.FixedComplexityLimit(cfg.MaxComplexity))
+	}
+
+	if cfg.MaxPageSize > 0 {
+		srv.Use(pageSizeLimit{max: cfg.MaxPageSize})
+	}
+
+	if cfg.OperationTimeout > 0 {
+		srv.Use(operationTimeout{timeout: cfg.OperationTimeout})
+	}
 
 	h := &Handler{
 		r:              r,
@@ -83,7 +229,7 @@ func (h *Handler) Handler() http.HandlerFunc {
 // Routes ...
 func (h *Handler) Routes(e *echo.Group) {
 	e.Use(h.middleware...)
-	e.POST(graphFullPath, h.graphRequest)
+	e.POST(graphFullPath, h.graphRequest, h.dataloaders)
 
 	if h.playground != nil {
 		handlers, err := h.playground.Handlers()
@@ -0,0 +1,194 @@
+package graphapi_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/tenant-api/internal/graphapi"
+	"go.infratographer.com/tenant-api/internal/testclient"
+)
+
+type graphResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func postGraph(ctx context.Context, t *testing.T, h http.Handler, body map[string]interface{}) graphResponse {
+	t.Helper()
+
+	b, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(b))).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	var resp graphResponse
+
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+	return resp
+}
+
+func TestHandlerAPQ(t *testing.T) {
+	ctx := context.Background()
+
+	// Permit request
+	ctx = context.WithValue(ctx, permissions.CheckerCtxKey, permissions.DefaultAllowChecker)
+
+	tenant := TenantBuilder{}.MustNew(ctx)
+
+	cache := graphql.MapCache{}
+
+	r := graphapi.NewResolver(testTools.entClient, zap.NewNop().Sugar())
+	h := r.Handler(false, nil, graphapi.WithAPQCache(cache)).Handler()
+
+	query := `query { tenant(id: "` + tenant.ID.String() + `") { name } }`
+	sum := sha256.Sum256([]byte(query))
+	hash := hex.EncodeToString(sum[:])
+
+	persisted := map[string]interface{}{
+		"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": hash},
+	}
+
+	resp := postGraph(ctx, t, h, map[string]interface{}{"extensions": persisted})
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "PersistedQueryNotFound", resp.Errors[0].Message)
+
+	resp = postGraph(ctx, t, h, map[string]interface{}{"query": query, "extensions": persisted})
+	require.Empty(t, resp.Errors)
+	assert.Contains(t, cache, hash)
+
+	resp = postGraph(ctx, t, h, map[string]interface{}{"extensions": persisted})
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"tenant": {"name": "`+tenant.Name+`"}}`, string(resp.Data))
+}
+
+func TestHandlerAllowList(t *testing.T) {
+	ctx := context.Background()
+
+	// Permit request
+	ctx = context.WithValue(ctx, permissions.CheckerCtxKey, permissions.DefaultAllowChecker)
+
+	tenant := TenantBuilder{}.MustNew(ctx)
+
+	allowList, err := graphapi.LoadAllowList("../testclient")
+	require.NoError(t, err)
+
+	r := graphapi.NewResolver(testTools.entClient, zap.NewNop().Sugar())
+	h := r.Handler(false, nil, graphapi.WithAllowList(allowList)).Handler()
+
+	graphC := testclient.NewClient(&http.Client{Transport: localRoundTripper{handler: h}}, "graph")
+
+	t.Run("registered operation", func(t *testing.T) {
+		resp, err := graphC.GetTenant(ctx, tenant.ID)
+		require.NoError(t, err)
+		assert.Equal(t, tenant.Name, resp.Tenant.Name)
+	})
+
+	t.Run("registered operation with different formatting", func(t *testing.T) {
+		query := `query GetTenant($id: ID!) { tenant(id: $id) { id name description kind createdAt updatedAt parent { id name } } }`
+
+		resp := postGraph(ctx, t, h, map[string]interface{}{
+			"query":     query,
+			"variables": map[string]interface{}{"id": tenant.ID.String()},
+		})
+		assert.Empty(t, resp.Errors)
+	})
+
+	t.Run("ad-hoc operation", func(t *testing.T) {
+		query := `query GetTenant($id: ID!) { tenant(id: $id) { id name } }`
+
+		resp := postGraph(ctx, t, h, map[string]interface{}{
+			"query":     query,
+			"variables": map[string]interface{}{"id": tenant.ID.String()},
+		})
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "OPERATION_NOT_ALLOWED", resp.Errors[0].Extensions["code"])
+	})
+}
+
+// operationLabels returns the operation labels of the recorded GraphQL
+// operation durations
+func operationLabels(t *testing.T) map[string]bool {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	labels := map[string]bool{}
+
+	for _, family := range families {
+		if family.GetName() != "tenantapi_graphql_operation_duration_seconds" {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "operation" {
+					labels[label.GetValue()] = true
+				}
+			}
+		}
+	}
+
+	return labels
+}
+
+func TestHandlerMetricsOperationNames(t *testing.T) {
+	ctx := context.Background()
+
+	// Permit request
+	ctx = context.WithValue(ctx, permissions.CheckerCtxKey, permissions.DefaultAllowChecker)
+
+	tenant := TenantBuilder{}.MustNew(ctx)
+
+	query := `query UnregisteredMetricsProbe($id: ID!) { tenant(id: $id) { name } }`
+
+	r := graphapi.NewResolver(testTools.entClient, zap.NewNop().Sugar())
+
+	resp := postGraph(ctx, t, r.Handler(false, nil).Handler(), map[string]interface{}{
+		"query":     query,
+		"variables": map[string]interface{}{"id": tenant.ID.String()},
+	})
+	require.Empty(t, resp.Errors)
+
+	labels := operationLabels(t)
+	assert.True(t, labels["other"], "unregistered operation names are recorded as other")
+	assert.False(t, labels["UnregisteredMetricsProbe"])
+
+	allowList, err := graphapi.LoadAllowList("../testclient")
+	require.NoError(t, err)
+
+	h := r.Handler(false, nil, graphapi.WithAllowList(allowList)).Handler()
+	graphC := testclient.NewClient(&http.Client{Transport: localRoundTripper{handler: h}}, "graph")
+
+	_, err = graphC.GetTenant(ctx, tenant.ID)
+	require.NoError(t, err)
+
+	assert.True(t, operationLabels(t)["GetTenant"], "allow listed operation names are recorded")
+}
+
+func TestNewAllowListEmpty(t *testing.T) {
+	_, err := graphapi.NewAllowList(&ast.Source{Name: "empty.graphql", Input: "fragment f on Tenant { id }"})
+	assert.ErrorIs(t, err, graphapi.ErrEmptyAllowList)
+}
@@ -0,0 +1,90 @@
+package graphapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.uber.org/zap"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/graphapi"
+	"go.infratographer.com/tenant-api/internal/tenantcache"
+)
+
+func TestHandlerCache(t *testing.T) {
+	ctx := context.WithValue(context.Background(), permissions.CheckerCtxKey, permissions.DefaultAllowChecker)
+
+	client, drv := openReadsTestDB(t, "cache-handler")
+
+	backend, err := tenantcache.NewLRU(100)
+	require.NoError(t, err)
+
+	cache := tenantcache.New(client, backend)
+
+	// changes made by another replica don't run this process' hooks
+	other := ent.NewClient(ent.Driver(drv))
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+
+	h := graphapi.NewResolver(client, zap.NewNop().Sugar(), graphapi.WithCache(cache)).Handler(false, nil).Handler()
+
+	post := func(t *testing.T, query string, header http.Header) graphResponse {
+		t.Helper()
+
+		b, err := json.Marshal(map[string]interface{}{"query": query})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(b))).WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		var resp graphResponse
+
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+		return resp
+	}
+
+	query := `query { tenant(id: "` + child.ID.String() + `") { name parent { name } } }`
+
+	resp := post(t, query, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"tenant": {"name": "child", "parent": {"name": "root"}}}`, string(resp.Data))
+
+	other.Tenant.UpdateOneID(root.ID).SetName("renamed").ExecX(ctx)
+	other.Tenant.UpdateOneID(child.ID).SetName("moved").ExecX(ctx)
+
+	t.Run("queries use the cache", func(t *testing.T) {
+		resp := post(t, query, nil)
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"tenant": {"name": "child", "parent": {"name": "root"}}}`, string(resp.Data))
+	})
+
+	t.Run("strong consistency skips the cache", func(t *testing.T) {
+		resp := post(t, query, http.Header{graphapi.ConsistencyHeader: []string{"strong"}})
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"tenant": {"name": "moved", "parent": {"name": "renamed"}}}`, string(resp.Data))
+	})
+
+	t.Run("mutations invalidate the cache", func(t *testing.T) {
+		client.Tenant.UpdateOneID(child.ID).SetDescription("updated").ExecX(ctx)
+
+		resp := post(t, query, nil)
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"tenant": {"name": "moved", "parent": {"name": "root"}}}`, string(resp.Data))
+	})
+}
@@ -0,0 +1,149 @@
+package graphapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/tenant-api/internal/graphapi"
+	"go.infratographer.com/tenant-api/internal/testclient"
+)
+
+func limitedTestHandler(cfg graphapi.HandlerConfig) http.Handler {
+	r := graphapi.NewResolver(testTools.entClient, zap.NewNop().Sugar())
+
+	return r.Handler(false, nil, graphapi.WithHandlerConfig(cfg)).Handler()
+}
+
+func limitedTestClient(cfg graphapi.HandlerConfig) testclient.TestClient {
+	return testclient.NewClient(&http.Client{Transport: localRoundTripper{handler: limitedTestHandler(cfg)}}, "graph")
+}
+
+func TestHandlerLimits(t *testing.T) {
+	ctx := context.Background()
+
+	// Permit request
+	ctx = context.WithValue(ctx, permissions.CheckerCtxKey, permissions.DefaultAllowChecker)
+
+	tenant := TenantBuilder{}.MustNew(ctx)
+	TenantBuilder{Parent: tenant}.MustNew(ctx)
+	TenantBuilder{Parent: tenant}.MustNew(ctx)
+	TenantBuilder{Parent: tenant}.MustNew(ctx)
+
+	t.Run("default page size is capped", func(t *testing.T) {
+		resp, err := limitedTestClient(graphapi.HandlerConfig{MaxPageSize: 2}).GetTenantChildren(ctx, tenant.ID, nil)
+		require.NoError(t, err)
+
+		assert.Len(t, resp.Tenant.Children.Edges, 2)
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		_, err := limitedTestClient(graphapi.HandlerConfig{MaxDepth: 4}).GetTenantChildren(ctx, tenant.ID, nil)
+		assert.ErrorContains(t, err, "exceeds the limit of 4")
+
+		resp, err := limitedTestClient(graphapi.HandlerConfig{MaxDepth: 5}).GetTenantChildren(ctx, tenant.ID, nil)
+		require.NoError(t, err)
+		assert.Len(t, resp.Tenant.Children.Edges, 3)
+	})
+
+	t.Run("max complexity weights connections", func(t *testing.T) {
+		cfg := graphapi.HandlerConfig{MaxComplexity: 100, MaxPageSize: 50}
+
+		_, err := limitedTestClient(cfg).GetTenantChildren(ctx, tenant.ID, nil)
+		assert.ErrorContains(t, err, "operation has complexity")
+
+		_, err = limitedTestClient(cfg).GetTenant(ctx, tenant.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("negative page sizes don't lower the complexity", func(t *testing.T) {
+		// a negative weight would offset the complexity of the second connection
+		body := `{"query": "query { a: tenant(id: \"` + tenant.ID.String() + `\") { children(first: -1) { edges { node { children(first: 50) { edges { node { id } } } } } } } ` +
+			`b: tenant(id: \"` + tenant.ID.String() + `\") { children(first: 50) { edges { node { id } } } } }"}`
+
+		req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body)).WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		limitedTestHandler(graphapi.HandlerConfig{MaxComplexity: 100, MaxPageSize: 50}).ServeHTTP(w, req)
+
+		var resp struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0].Message, "operation has complexity")
+	})
+
+	t.Run("huge page sizes don't overflow the complexity", func(t *testing.T) {
+		// the product of the page sizes would overflow to a negative complexity
+		body := `{"query": "query { tenant(id: \"` + tenant.ID.String() + `\") { children(first: 4611686018427387904) { edges { node { children(first: 4) { edges { node { id } } } } } } } }"}`
+
+		req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body)).WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		limitedTestHandler(graphapi.HandlerConfig{MaxComplexity: 100}).ServeHTTP(w, req)
+
+		var resp struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0].Message, "operation has complexity")
+	})
+
+	t.Run("webhook connections are weighted", func(t *testing.T) {
+		body := `{"query": "query { webhookSubscriptions(first: 20) { edges { node { deliveries(first: 20) { edges { node { id } } } } } } }"}`
+
+		req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body)).WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		limitedTestHandler(graphapi.HandlerConfig{MaxComplexity: 100}).ServeHTTP(w, req)
+
+		var resp struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0].Message, "operation has complexity")
+	})
+
+	t.Run("requested page size over the limit", func(t *testing.T) {
+		body := `{"query": "query { tenant(id: \"` + tenant.ID.String() + `\") { children(first: 5) { edges { node { id } } } } }"}`
+
+		req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(body)).WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		limitedTestHandler(graphapi.HandlerConfig{MaxPageSize: 2}).ServeHTTP(w, req)
+
+		var resp struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "first of 5 exceeds the max page size of 2", resp.Errors[0].Message)
+	})
+}
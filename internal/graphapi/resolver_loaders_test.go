@@ -0,0 +1,127 @@
+package graphapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"entgo.io/ent/dialect"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.uber.org/zap"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/graphapi"
+)
+
+func TestHandlerDataloaders(t *testing.T) {
+	ctx := context.WithValue(context.Background(), permissions.CheckerCtxKey, permissions.DefaultAllowChecker)
+
+	seed, drv := openReadsTestDB(t, "loaders")
+
+	root := seed.Tenant.Create().SetName("root").SaveX(ctx)
+
+	reps := []map[string]interface{}{}
+
+	for i := 0; i < 10; i++ {
+		child := seed.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+		seed.Tenant.Create().SetName("grandchild").SetParent(child).ExecX(ctx)
+
+		reps = append(reps, map[string]interface{}{"__typename": "Tenant", "id": child.ID})
+	}
+
+	var statements atomic.Int32
+
+	client := ent.NewClient(ent.Driver(dialect.DebugWithContext(drv, func(context.Context, ...any) {
+		statements.Add(1)
+	})))
+
+	e := echo.New()
+	graphapi.NewResolver(client, zap.NewNop().Sugar()).Handler(false, nil).Routes(e.Group(""))
+
+	post := func(t *testing.T, query string, variables map[string]interface{}) graphResponse {
+		t.Helper()
+
+		b, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(b))).WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+
+		var resp graphResponse
+
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+		return resp
+	}
+
+	query := `query ($reps: [_Any!]!) {
+		_entities(representations: $reps) {
+			... on Tenant { id parent { name } children { totalCount } }
+		}
+	}`
+
+	t.Run("each level is loaded with one statement", func(t *testing.T) {
+		statements.Store(0)
+
+		resp := post(t, query, map[string]interface{}{"reps": reps})
+		require.Empty(t, resp.Errors)
+
+		var data struct {
+			Entities []struct {
+				ID       string
+				Parent   struct{ Name string }
+				Children struct{ TotalCount int }
+			} `json:"_entities"`
+		}
+
+		require.NoError(t, json.Unmarshal(resp.Data, &data))
+		require.Len(t, data.Entities, len(reps))
+
+		for i, entity := range data.Entities {
+			assert.Equal(t, reps[i]["id"].(fmt.Stringer).String(), entity.ID)
+			assert.Equal(t, "root", entity.Parent.Name)
+			assert.Equal(t, 1, entity.Children.TotalCount)
+		}
+
+		// the entities, their parents and their children counts
+		assert.Equal(t, int32(3), statements.Load())
+	})
+
+	t.Run("missing entities are not found", func(t *testing.T) {
+		missing := []map[string]interface{}{reps[0], {"__typename": "Tenant", "id": "tnntten-missing"}}
+
+		resp := post(t, query, map[string]interface{}{"reps": missing})
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0].Message, "tenant not found")
+	})
+
+	t.Run("children edges aren't batched", func(t *testing.T) {
+		resp := post(t, `query ($id: ID!) { tenant(id: $id) { children { totalCount edges { node { name } } } } }`,
+			map[string]interface{}{"id": root.ID})
+		require.Empty(t, resp.Errors)
+
+		var data struct {
+			Tenant struct {
+				Children struct {
+					TotalCount int
+					Edges      []struct{ Node struct{ Name string } }
+				}
+			}
+		}
+
+		require.NoError(t, json.Unmarshal(resp.Data, &data))
+		assert.Equal(t, 10, data.Tenant.Children.TotalCount)
+		assert.Len(t, data.Tenant.Children.Edges, 10)
+	})
+}
@@ -0,0 +1,106 @@
+package graphapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/tenant-api/internal/database"
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/graphapi"
+)
+
+func openReadsTestDB(t *testing.T, name string) (*ent.Client, dialect.Driver) {
+	t.Helper()
+
+	drv, err := entsql.Open(dialect.SQLite, "file:"+name+"?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+
+	client := ent.NewClient(ent.Driver(drv))
+	require.NoError(t, client.Schema.Create(context.Background()))
+
+	t.Cleanup(func() { client.Close() })
+
+	return client, drv
+}
+
+func TestHandlerStaleReads(t *testing.T) {
+	ctx := context.WithValue(context.Background(), permissions.CheckerCtxKey, permissions.DefaultAllowChecker)
+
+	_, primary := openReadsTestDB(t, "reads-primary")
+	replicaClient, replica := openReadsTestDB(t, "reads-replica")
+
+	// the tenant only exists on the replica, so it's only found when the
+	// query is served by the read pool
+	replicated := replicaClient.Tenant.Create().SetName("replicated").SaveX(ctx)
+
+	client := ent.NewClient(ent.Driver(database.NewReadRouter(primary, replica)))
+
+	h := graphapi.NewResolver(client, zap.NewNop().Sugar()).Handler(false, nil).Handler()
+
+	post := func(t *testing.T, query string, header http.Header) graphResponse {
+		t.Helper()
+
+		b, err := json.Marshal(map[string]interface{}{"query": query})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(string(b))).WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		var resp graphResponse
+
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+		return resp
+	}
+
+	query := `query { tenant(id: "` + replicated.ID.String() + `") { name } }`
+
+	t.Run("queries use the read pool", func(t *testing.T) {
+		resp := post(t, query, nil)
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"tenant": {"name": "replicated"}}`, string(resp.Data))
+	})
+
+	t.Run("strong consistency uses the primary", func(t *testing.T) {
+		resp := post(t, query, http.Header{graphapi.ConsistencyHeader: []string{"strong"}})
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0].Message, "not found")
+	})
+
+	t.Run("mutations use the primary", func(t *testing.T) {
+		resp := post(t, `mutation { tenantCreate(input: {name: "written"}) { tenant { id name } } }`, nil)
+		require.Empty(t, resp.Errors)
+
+		var created struct {
+			TenantCreate struct {
+				Tenant struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"tenant"`
+			} `json:"tenantCreate"`
+		}
+
+		require.NoError(t, json.Unmarshal(resp.Data, &created))
+		assert.Equal(t, "written", created.TenantCreate.Tenant.Name)
+
+		assert.Equal(t, 1, replicaClient.Tenant.Query().CountX(ctx), "the write shouldn't reach the replica")
+	})
+}
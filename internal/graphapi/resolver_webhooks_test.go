@@ -0,0 +1,157 @@
+package graphapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/tenant-api/internal/ent/generated/webhookdelivery"
+	"go.infratographer.com/tenant-api/internal/graphapi"
+	"go.infratographer.com/tenant-api/internal/webhooks"
+)
+
+func TestHandlerWebhooks(t *testing.T) {
+	ctx := context.WithValue(context.Background(), permissions.CheckerCtxKey, permissions.DefaultAllowChecker)
+
+	client, _ := openReadsTestDB(t, "webhooks")
+
+	h := graphapi.NewResolver(client, zap.NewNop().Sugar()).Handler(false, nil).Handler()
+
+	tnt := client.Tenant.Create().SetName("tenant").SaveX(ctx)
+
+	query := func(ctx context.Context, t *testing.T, query string) graphResponse {
+		t.Helper()
+
+		return postGraph(ctx, t, h, map[string]interface{}{"query": query})
+	}
+
+	var subID string
+
+	t.Run("create", func(t *testing.T) {
+		resp := query(ctx, t, `mutation { webhookSubscriptionCreate(input: {
+			url: "https://example.com/hook", secret: "shh", eventTypes: ["create", "delete"], tenantID: "`+tnt.ID.String()+`"
+		}) { webhookSubscription { id url eventTypes tenantID } } }`)
+		require.Empty(t, resp.Errors)
+
+		var created struct {
+			WebhookSubscriptionCreate struct {
+				WebhookSubscription struct {
+					ID         string   `json:"id"`
+					URL        string   `json:"url"`
+					EventTypes []string `json:"eventTypes"`
+					TenantID   string   `json:"tenantID"`
+				} `json:"webhookSubscription"`
+			} `json:"webhookSubscriptionCreate"`
+		}
+
+		require.NoError(t, json.Unmarshal(resp.Data, &created))
+
+		sub := created.WebhookSubscriptionCreate.WebhookSubscription
+		assert.Equal(t, "https://example.com/hook", sub.URL)
+		assert.Equal(t, []string{"create", "delete"}, sub.EventTypes)
+		assert.Equal(t, tnt.ID.String(), sub.TenantID)
+
+		subID = sub.ID
+	})
+
+	t.Run("secret isn't exposed", func(t *testing.T) {
+		resp := query(ctx, t, `query { webhookSubscription(id: "`+subID+`") { secret } }`)
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0].Message, "Cannot query field")
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input string
+			err   string
+		}{
+			{
+				name:  "relative url",
+				input: `url: "/hook", secret: "shh"`,
+				err:   "url",
+			},
+			{
+				name:  "unknown event type",
+				input: `url: "https://example.com/hook", secret: "shh", eventTypes: ["renamed"]`,
+				err:   "renamed",
+			},
+			{
+				name:  "missing tenant",
+				input: `url: "https://example.com/hook", secret: "shh", tenantID: "tnntten-missing"`,
+				err:   "not found",
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+
+			t.Run(tt.name, func(t *testing.T) {
+				resp := query(ctx, t, `mutation { webhookSubscriptionCreate(input: {`+tt.input+`}) { webhookSubscription { id } } }`)
+				require.Len(t, resp.Errors, 1)
+				assert.Contains(t, resp.Errors[0].Message, tt.err)
+			})
+		}
+	})
+
+	t.Run("retry dead delivery", func(t *testing.T) {
+		sub, err := client.WebhookSubscription.Query().Only(ctx)
+		require.NoError(t, err)
+
+		dead := client.WebhookDelivery.Create().
+			SetSubscription(sub).
+			SetEventType("create").
+			SetSubjectID(tnt.ID).
+			SetPayload("{}").
+			SetStatus(webhookdelivery.StatusDEAD).
+			SetAttempts(5).
+			SetNextAttemptAt(time.Now()).
+			SaveX(ctx)
+
+		resp := query(ctx, t, `mutation { webhookDeliveryRetry(id: "`+dead.ID.String()+`") { webhookDelivery { status attempts } } }`)
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"webhookDeliveryRetry": {"webhookDelivery": {"status": "PENDING", "attempts": 0}}}`, string(resp.Data))
+
+		resp = query(ctx, t, `mutation { webhookDeliveryRetry(id: "`+dead.ID.String()+`") { webhookDelivery { id } } }`)
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0].Message, webhooks.ErrDeliveryNotDead.Error())
+
+		resp = query(ctx, t, `query { webhookSubscription(id: "`+subID+`") { deliveries { totalCount edges { node { id status } } } } }`)
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"webhookSubscription": {"deliveries": {"totalCount": 1, "edges": [{"node": {"id": "`+dead.ID.String()+`", "status": "PENDING"}}]}}}`, string(resp.Data))
+	})
+
+	t.Run("list by tenant", func(t *testing.T) {
+		client.WebhookSubscription.Create().SetURL("https://example.com/global").SetSecret("shh").ExecX(ctx)
+
+		resp := query(ctx, t, `query { webhookSubscriptions(tenantID: "`+tnt.ID.String()+`") { totalCount } }`)
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"webhookSubscriptions": {"totalCount": 1}}`, string(resp.Data))
+	})
+
+	t.Run("permission denied", func(t *testing.T) {
+		denied := context.WithValue(context.Background(), permissions.CheckerCtxKey, permissions.DefaultDenyChecker)
+
+		resp := query(denied, t, `query { webhookSubscription(id: "`+subID+`") { id } }`)
+		require.Len(t, resp.Errors, 1)
+
+		resp = query(denied, t, `mutation { webhookSubscriptionDelete(id: "`+subID+`") { deletedID } }`)
+		require.Len(t, resp.Errors, 1)
+
+		assert.True(t, client.WebhookSubscription.Query().ExistX(ctx))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		resp := query(ctx, t, `mutation { webhookSubscriptionDelete(id: "`+subID+`") { deletedID } }`)
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"webhookSubscriptionDelete": {"deletedID": "`+subID+`"}}`, string(resp.Data))
+
+		assert.Zero(t, client.WebhookDelivery.Query().CountX(ctx), "deliveries are deleted with the subscription")
+	})
+}
@@ -6,11 +6,10 @@ package graphapi
 
 import (
 	"context"
-	"fmt"
 
 	"go.infratographer.com/permissions-api/pkg/permissions"
 	"go.infratographer.com/tenant-api/internal/ent/generated"
-	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/tenants"
 	"go.infratographer.com/x/gidx"
 )
 
@@ -22,11 +21,11 @@ func (r *mutationResolver) TenantCreate(ctx context.Context, input generated.Cre
 		resource = *input.ParentID
 	}
 
-	if err := permissions.CheckAccess(ctx, resource, actionTenantCreate); err != nil {
+	if err := permissions.CheckAccess(ctx, resource, ActionTenantCreate); err != nil {
 		return nil, err
 	}
 
-	tnt, err := r.client.Tenant.Create().SetInput(input).Save(ctx)
+	tnt, err := tenants.Create(ctx, r.client, input)
 	if err != nil {
 		return nil, err
 	}
@@ -36,11 +35,11 @@ func (r *mutationResolver) TenantCreate(ctx context.Context, input generated.Cre
 
 // TenantUpdate is the resolver for the tenantUpdate field.
 func (r *mutationResolver) TenantUpdate(ctx context.Context, id gidx.PrefixedID, input generated.UpdateTenantInput) (*TenantUpdatePayload, error) {
-	if err := permissions.CheckAccess(ctx, id, actionTenantUpdate); err != nil {
+	if err := permissions.CheckAccess(ctx, id, ActionTenantUpdate); err != nil {
 		return nil, err
 	}
 
-	tnt, err := r.client.Tenant.UpdateOneID(id).SetInput(input).Save(ctx)
+	tnt, err := tenants.Update(ctx, r.client, id, input)
 	if err != nil {
 		return nil, err
 	}
@@ -49,20 +48,11 @@ func (r *mutationResolver) TenantUpdate(ctx context.Context, id gidx.PrefixedID,
 
 // TenantDelete is the resolver for the tenantDelete field.
 func (r *mutationResolver) TenantDelete(ctx context.Context, id gidx.PrefixedID) (*TenantDeletePayload, error) {
-	if err := permissions.CheckAccess(ctx, id, actionTenantDelete); err != nil {
+	if err := permissions.CheckAccess(ctx, id, ActionTenantDelete); err != nil {
 		return nil, err
 	}
 
-	childrenCount, err := r.client.Tenant.Query().Where(tenant.ParentTenantID(id)).Count(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	if childrenCount != 0 {
-		return nil, fmt.Errorf("tenant has children and can't be deleted")
-	}
-
-	if err := r.client.Tenant.DeleteOneID(id).Exec(ctx); err != nil {
+	if err := tenants.Delete(ctx, r.client, id); err != nil {
 		return nil, err
 	}
 
@@ -71,11 +61,11 @@ func (r *mutationResolver) TenantDelete(ctx context.Context, id gidx.PrefixedID)
 
 // Tenant is the resolver for the tenant field.
 func (r *queryResolver) Tenant(ctx context.Context, id gidx.PrefixedID) (*generated.Tenant, error) {
-	if err := permissions.CheckAccess(ctx, id, actionTenantGet); err != nil {
+	if err := permissions.CheckAccess(ctx, id, ActionTenantGet); err != nil {
 		return nil, err
 	}
 
-	return r.client.Tenant.Get(ctx, id)
+	return r.getTenant(ctx, id)
 }
 
 // Mutation returns MutationResolver implementation.
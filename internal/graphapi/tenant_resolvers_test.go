@@ -11,6 +11,7 @@ import (
 	"go.infratographer.com/x/gidx"
 
 	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
 	"go.infratographer.com/tenant-api/internal/testclient"
 )
 
@@ -229,6 +230,62 @@ func TestTenantChildrenWhereFiltering(t *testing.T) {
 	}
 }
 
+func TestTenantChildrenKindFiltering(t *testing.T) {
+	ctx := context.Background()
+
+	// Permit request
+	ctx = context.WithValue(ctx, permissions.CheckerCtxKey, permissions.DefaultAllowChecker)
+
+	org := TenantBuilder{Kind: tenant.KindOrganization}.MustNew(ctx)
+	unit := TenantBuilder{Kind: tenant.KindBusinessUnit, Parent: org}.MustNew(ctx)
+	project := TenantBuilder{Kind: tenant.KindProject, Parent: org}.MustNew(ctx)
+	plain := TenantBuilder{Parent: org}.MustNew(ctx)
+
+	testCases := []struct {
+		TestName string
+		Kinds    []testclient.TenantKind
+		Expected []gidx.PrefixedID
+	}{
+		{
+			TestName: "One kind",
+			Kinds:    []testclient.TenantKind{testclient.TenantKindProject},
+			Expected: []gidx.PrefixedID{project.ID},
+		},
+		{
+			TestName: "Several kinds",
+			Kinds:    []testclient.TenantKind{testclient.TenantKindBusinessUnit, testclient.TenantKindProject},
+			Expected: []gidx.PrefixedID{unit.ID, project.ID},
+		},
+		{
+			TestName: "Default kind",
+			Kinds:    []testclient.TenantKind{testclient.TenantKindTenant},
+			Expected: []gidx.PrefixedID{plain.ID},
+		},
+		{
+			TestName: "No children of the kind",
+			Kinds:    []testclient.TenantKind{testclient.TenantKindEnvironment},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.TestName, func(t *testing.T) {
+			resp, err := graphTestClient(testTools.entClient).GetTenantChildrenOfKinds(ctx, org.ID, tt.Kinds)
+			require.NoError(t, err)
+			require.NotNil(t, resp.Tenant)
+
+			ids := []gidx.PrefixedID{}
+
+			for _, edge := range resp.Tenant.Children.Edges {
+				assert.Contains(t, tt.Kinds, edge.Node.Kind)
+
+				ids = append(ids, edge.Node.ID)
+			}
+
+			assert.ElementsMatch(t, tt.Expected, ids)
+		})
+	}
+}
+
 func TestFullTenantLifecycle(t *testing.T) {
 	ctx := context.Background()
 
@@ -24,7 +24,9 @@ import (
 	ent "go.infratographer.com/tenant-api/internal/ent/generated"
 	"go.infratographer.com/tenant-api/internal/ent/generated/eventhooks"
 	"go.infratographer.com/tenant-api/internal/graphapi"
+	"go.infratographer.com/tenant-api/internal/pubsub"
 	"go.infratographer.com/tenant-api/internal/testclient"
+	"go.infratographer.com/tenant-api/internal/validation/hooks"
 )
 
 var TestDBURI = os.Getenv("TENANTAPI_TESTDB_URI")
@@ -105,7 +107,7 @@ func setupDB() {
 
 	dia, uri, cntr := parseDBURI(ctx)
 
-	publisher, err := events.NewPublisher(testTools.pubsubPublisherConfig)
+	publisher, err := pubsub.NewPublisher(pubsub.PublisherConfig{PublisherConfig: testTools.pubsubPublisherConfig}, zap.NewNop().Sugar())
 	if err != nil {
 		log.Panicf("error creating pubsubx publisher: %s", err.Error())
 	}
@@ -133,6 +135,7 @@ func setupDB() {
 	testTools.dbContainer = cntr
 	testTools.entClient = c
 	testTools.pubsubEntClient = c
+	testTools.pubsubEntClient.Use(hooks.TenantPolicy)
 	eventhooks.EventHooks(testTools.pubsubEntClient)
 }
 
@@ -0,0 +1,144 @@
+package graphapi
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.34
+
+import (
+	"context"
+	"time"
+
+	"entgo.io/contrib/entgql"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/webhookdelivery"
+	"go.infratographer.com/tenant-api/internal/ent/generated/webhooksubscription"
+	"go.infratographer.com/tenant-api/internal/webhooks"
+	"go.infratographer.com/x/gidx"
+)
+
+// WebhookSubscriptionCreate is the resolver for the webhookSubscriptionCreate field.
+func (r *mutationResolver) WebhookSubscriptionCreate(ctx context.Context, input generated.CreateWebhookSubscriptionInput) (*WebhookSubscriptionCreatePayload, error) {
+	resource := gidx.NullPrefixedID
+
+	if input.TenantID != nil {
+		resource = *input.TenantID
+	}
+
+	if err := permissions.CheckAccess(ctx, resource, ActionWebhookSubscriptionCreate); err != nil {
+		return nil, err
+	}
+
+	if err := webhooks.ValidateEventTypes(input.EventTypes); err != nil {
+		return nil, err
+	}
+
+	if input.TenantID != nil {
+		if _, err := r.client.Tenant.Get(ctx, *input.TenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	sub, err := r.client.WebhookSubscription.Create().SetInput(input).Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookSubscriptionCreatePayload{WebhookSubscription: sub}, nil
+}
+
+// WebhookSubscriptionUpdate is the resolver for the webhookSubscriptionUpdate field.
+func (r *mutationResolver) WebhookSubscriptionUpdate(ctx context.Context, id gidx.PrefixedID, input generated.UpdateWebhookSubscriptionInput) (*WebhookSubscriptionUpdatePayload, error) {
+	if _, err := r.authorizeWebhookSubscription(ctx, id, ActionWebhookSubscriptionUpdate); err != nil {
+		return nil, err
+	}
+
+	if err := webhooks.ValidateEventTypes(input.EventTypes); err != nil {
+		return nil, err
+	}
+
+	if err := webhooks.ValidateEventTypes(input.AppendEventTypes); err != nil {
+		return nil, err
+	}
+
+	sub, err := r.client.WebhookSubscription.UpdateOneID(id).SetInput(input).Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookSubscriptionUpdatePayload{WebhookSubscription: sub}, nil
+}
+
+// WebhookSubscriptionDelete is the resolver for the webhookSubscriptionDelete field.
+func (r *mutationResolver) WebhookSubscriptionDelete(ctx context.Context, id gidx.PrefixedID) (*WebhookSubscriptionDeletePayload, error) {
+	if _, err := r.authorizeWebhookSubscription(ctx, id, ActionWebhookSubscriptionDelete); err != nil {
+		return nil, err
+	}
+
+	if err := r.client.WebhookSubscription.DeleteOneID(id).Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return &WebhookSubscriptionDeletePayload{DeletedID: id}, nil
+}
+
+// WebhookDeliveryRetry is the resolver for the webhookDeliveryRetry field.
+func (r *mutationResolver) WebhookDeliveryRetry(ctx context.Context, id gidx.PrefixedID) (*WebhookDeliveryRetryPayload, error) {
+	delivery, err := r.client.WebhookDelivery.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.authorizeWebhookSubscription(ctx, delivery.SubscriptionID, ActionWebhookSubscriptionUpdate); err != nil {
+		return nil, err
+	}
+
+	// the delivery gets a fresh set of attempts
+	n, err := r.client.WebhookDelivery.Update().
+		Where(
+			webhookdelivery.ID(id),
+			webhookdelivery.StatusEQ(webhookdelivery.StatusDEAD),
+		).
+		SetStatus(webhookdelivery.StatusPENDING).
+		SetAttempts(0).
+		SetNextAttemptAt(time.Now()).
+		Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		return nil, webhooks.ErrDeliveryNotDead
+	}
+
+	delivery, err = r.client.WebhookDelivery.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookDeliveryRetryPayload{WebhookDelivery: delivery}, nil
+}
+
+// WebhookSubscription is the resolver for the webhookSubscription field.
+func (r *queryResolver) WebhookSubscription(ctx context.Context, id gidx.PrefixedID) (*generated.WebhookSubscription, error) {
+	return r.authorizeWebhookSubscription(ctx, id, ActionWebhookSubscriptionGet)
+}
+
+// WebhookSubscriptions is the resolver for the webhookSubscriptions field.
+func (r *queryResolver) WebhookSubscriptions(ctx context.Context, tenantID *gidx.PrefixedID, after *entgql.Cursor[gidx.PrefixedID], first *int, before *entgql.Cursor[gidx.PrefixedID], last *int, orderBy *generated.WebhookSubscriptionOrder) (*generated.WebhookSubscriptionConnection, error) {
+	resource := gidx.NullPrefixedID
+	scope := webhooksubscription.TenantIDIsNil()
+
+	if tenantID != nil {
+		resource = *tenantID
+		scope = webhooksubscription.TenantID(*tenantID)
+	}
+
+	if err := permissions.CheckAccess(ctx, resource, ActionWebhookSubscriptionList); err != nil {
+		return nil, err
+	}
+
+	return r.client.WebhookSubscription.Query().
+		Where(scope).
+		Paginate(ctx, after, first, before, last, generated.WithWebhookSubscriptionOrder(orderBy))
+}
@@ -0,0 +1,25 @@
+package graphapi
+
+import (
+	"context"
+
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/gidx"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+)
+
+// authorizeWebhookSubscription loads the subscription and checks the action
+// is allowed on the tenant it's scoped to
+func (r *Resolver) authorizeWebhookSubscription(ctx context.Context, id gidx.PrefixedID, action string) (*ent.WebhookSubscription, error) {
+	sub, err := r.client.WebhookSubscription.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := permissions.CheckAccess(ctx, sub.TenantID, action); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
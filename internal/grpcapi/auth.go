@@ -0,0 +1,103 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authenticator runs the echo middleware used by the HTTP APIs against the
+// metadata of each call, so gRPC calls are authenticated and get the same
+// permissions checker as the GraphQL resolvers
+type authenticator struct {
+	echo       *echo.Echo
+	middleware []echo.MiddlewareFunc
+}
+
+func (a *authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if len(a.middleware) == 0 {
+		return ctx, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, method, nil)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			req.Header.Add(echo.HeaderAuthorization, v)
+		}
+	}
+
+	c := a.echo.NewContext(req, httptest.NewRecorder())
+
+	var authCtx context.Context
+
+	h := func(c echo.Context) error {
+		authCtx = c.Request().Context()
+
+		return nil
+	}
+
+	for i := len(a.middleware) - 1; i >= 0; i-- {
+		h = a.middleware[i](h)
+	}
+
+	if err := h(c); err != nil {
+		return nil, authError(err)
+	}
+
+	return authCtx, nil
+}
+
+func authError(err error) error {
+	var httpErr *echo.HTTPError
+
+	if !errors.As(err, &httpErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	switch httpErr.Code {
+	case http.StatusUnauthorized:
+		return status.Error(codes.Unauthenticated, "unauthenticated")
+	case http.StatusForbidden:
+		return status.Error(codes.PermissionDenied, "permission denied")
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a *authenticator) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
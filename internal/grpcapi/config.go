@@ -0,0 +1,23 @@
+package grpcapi
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+// DefaultListen is the default listening address for the gRPC server
+const DefaultListen = ":7903"
+
+// Config stores the configuration for the gRPC server
+type Config struct {
+	// Listen is the address the gRPC server listens on, the server is disabled
+	// when it's empty
+	Listen string `mapstructure:"listen"`
+}
+
+// MustViperFlags returns the cobra flags and viper config for the gRPC server
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.String("grpc-listen", DefaultListen, "address for the grpc server to listen on, empty disables the grpc server")
+	viperx.MustBindFlag(v, "grpc.listen", flags.Lookup("grpc-listen"))
+}
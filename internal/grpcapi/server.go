@@ -0,0 +1,540 @@
+// Package grpcapi provides the gRPC TenantService for service to service calls.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/graphapi"
+	"go.infratographer.com/tenant-api/internal/tenantcache"
+	tenantv1 "go.infratographer.com/tenant-api/pkg/api/tenant/v1"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+	maxBatchSize    = 1000
+
+	// watchTopic matches all tenant change messages
+	watchTopic = "*.tenant"
+
+	strongConsistency = "strong"
+)
+
+// ChangeSubscriber subscribes to change messages, it's satisfied by
+// *events.Subscriber
+type ChangeSubscriber interface {
+	SubscribeChanges(ctx context.Context, topic string) (<-chan *message.Message, error)
+}
+
+// Server implements the TenantService
+type Server struct {
+	tenantv1.UnimplementedTenantServiceServer
+
+	client     *ent.Client
+	subscriber ChangeSubscriber
+	cache      *tenantcache.Cache
+	logger     *zap.SugaredLogger
+}
+
+// Option configures the gRPC server
+type Option func(*Server)
+
+// WithSubscriber sets the subscriber change messages are streamed from for
+// Watch calls, Watch is unavailable without one
+func WithSubscriber(sub ChangeSubscriber) Option {
+	return func(s *Server) {
+		s.subscriber = sub
+	}
+}
+
+// WithCache looks up tenants and their ancestry through the cache, unless
+// the call asks for strong consistency
+func WithCache(cache *tenantcache.Cache) Option {
+	return func(s *Server) {
+		s.cache = cache
+	}
+}
+
+// WithLogger sets the logger for the server
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// NewServer returns a TenantService using the given ent client
+func NewServer(client *ent.Client, opts ...Option) *Server {
+	s := &Server{
+		client: client,
+		logger: zap.NewNop().Sugar(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// GRPCServer returns a grpc server with the TenantService registered. The
+// middleware is the same echo middleware used by the HTTP APIs, it's run
+// against the metadata of each call to authenticate it.
+func (s *Server) GRPCServer(middleware []echo.MiddlewareFunc, opts ...grpc.ServerOption) *grpc.Server {
+	auth := &authenticator{
+		echo:       echo.New(),
+		middleware: middleware,
+	}
+
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(auth.unaryInterceptor),
+		grpc.ChainStreamInterceptor(auth.streamInterceptor),
+	)
+
+	srv := grpc.NewServer(opts...)
+
+	tenantv1.RegisterTenantServiceServer(srv, s)
+
+	return srv
+}
+
+// Get returns a single tenant
+func (s *Server) Get(ctx context.Context, req *tenantv1.GetRequest) (*tenantv1.GetResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := permissions.CheckAccess(ctx, id, graphapi.ActionTenantGet); err != nil {
+		return nil, statusError(err)
+	}
+
+	t, err := s.client.Tenant.Get(ctx, id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &tenantv1.GetResponse{Tenant: toProto(t)}, nil
+}
+
+// BatchGet returns the tenants with the given IDs
+func (s *Server) BatchGet(ctx context.Context, req *tenantv1.BatchGetRequest) (*tenantv1.BatchGetResponse, error) {
+	if len(req.GetIds()) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d ids may be requested", maxBatchSize)
+	}
+
+	ids := make([]gidx.PrefixedID, 0, len(req.GetIds()))
+
+	for _, v := range req.GetIds() {
+		id, err := parseID(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := permissions.CheckAccess(ctx, id, graphapi.ActionTenantGet); err != nil {
+			return nil, statusError(err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	tenants, err := s.client.Tenant.Query().Where(tenant.IDIn(ids...)).All(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	found := make(map[gidx.PrefixedID]*ent.Tenant, len(tenants))
+
+	for _, t := range tenants {
+		found[t.ID] = t
+	}
+
+	resp := &tenantv1.BatchGetResponse{}
+
+	// keep the requested order
+	for _, id := range ids {
+		if t, ok := found[id]; ok {
+			resp.Tenants = append(resp.Tenants, toProto(t))
+		} else {
+			resp.MissingIds = append(resp.MissingIds, id.String())
+		}
+	}
+
+	return resp, nil
+}
+
+// ListChildren returns a page of the direct children of a tenant, page
+// tokens are compatible with the GraphQL and REST API cursors
+func (s *Server) ListChildren(ctx context.Context, req *tenantv1.ListChildrenRequest) (*tenantv1.ListChildrenResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	first := int(req.GetPageSize())
+
+	switch {
+	case first == 0:
+		first = defaultPageSize
+	case first < 0 || first > maxPageSize:
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be between 1 and %d", maxPageSize)
+	}
+
+	var after *ent.Cursor
+
+	if token := req.GetPageToken(); token != "" {
+		after = &ent.Cursor{}
+
+		if err := after.UnmarshalGQL(token); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+	}
+
+	if err := permissions.CheckAccess(ctx, id, graphapi.ActionTenantList); err != nil {
+		return nil, statusError(err)
+	}
+
+	exists, err := s.client.Tenant.Query().Where(tenant.ID(id)).Exist(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	if !exists {
+		return nil, status.Error(codes.NotFound, "tenant not found")
+	}
+
+	conn, err := s.client.Tenant.Query().Where(tenant.ParentTenantID(id)).Paginate(ctx, after, &first, nil, nil)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &tenantv1.ListChildrenResponse{}
+
+	for _, edge := range conn.Edges {
+		resp.Tenants = append(resp.Tenants, toProto(edge.Node))
+	}
+
+	if conn.PageInfo.HasNextPage && conn.PageInfo.EndCursor != nil {
+		resp.NextPageToken = encodeCursor(conn.PageInfo.EndCursor)
+	}
+
+	return resp, nil
+}
+
+// GetAncestors returns the ancestors of a tenant, nearest first
+func (s *Server) GetAncestors(ctx context.Context, req *tenantv1.GetAncestorsRequest) (*tenantv1.GetAncestorsResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := permissions.CheckAccess(ctx, id, graphapi.ActionTenantGet); err != nil {
+		return nil, statusError(err)
+	}
+
+	ancestors, err := s.ancestors(ctx, id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &tenantv1.GetAncestorsResponse{}
+
+	for _, t := range ancestors {
+		resp.Ancestors = append(resp.Ancestors, toProto(t))
+	}
+
+	return resp, nil
+}
+
+// IsDescendant reports whether a tenant is a descendant of another tenant
+func (s *Server) IsDescendant(ctx context.Context, req *tenantv1.IsDescendantRequest) (*tenantv1.IsDescendantResponse, error) {
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	ancestorID, err := parseID(req.GetAncestorId())
+	if err != nil {
+		return nil, err
+	}
+
+	// the caller must be able to see both tenants, or it could probe the
+	// ancestry of tenants it can't access
+	for _, resource := range []gidx.PrefixedID{id, ancestorID} {
+		if err := permissions.CheckAccess(ctx, resource, graphapi.ActionTenantGet); err != nil {
+			return nil, statusError(err)
+		}
+	}
+
+	descendant, err := s.isDescendant(ctx, id, ancestorID)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &tenantv1.IsDescendantResponse{Descendant: descendant}, nil
+}
+
+// Watch streams changes to a tenant and optionally its descendants
+func (s *Server) Watch(req *tenantv1.WatchRequest, stream tenantv1.TenantService_WatchServer) error {
+	ctx := stream.Context()
+
+	if s.subscriber == nil {
+		return status.Error(codes.Unavailable, "watch isn't configured")
+	}
+
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return err
+	}
+
+	if err := permissions.CheckAccess(ctx, id, graphapi.ActionTenantGet); err != nil {
+		return statusError(err)
+	}
+
+	messages, err := s.subscriber.SubscribeChanges(ctx, watchTopic)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return status.Error(codes.Unavailable, "change subscription closed")
+			}
+
+			msg.Ack()
+
+			change, err := events.UnmarshalChangeMessage(msg.Payload)
+			if err != nil {
+				s.logger.Warnw("failed to unmarshal change message", "error", err, "message_id", msg.UUID)
+
+				continue
+			}
+
+			matched, err := s.watchMatches(ctx, req, id, change)
+			if err != nil {
+				return statusError(err)
+			}
+
+			if !matched {
+				continue
+			}
+
+			resp, err := s.watchResponse(ctx, change)
+			if err != nil {
+				return statusError(err)
+			}
+
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watchMatches reports whether the change is for the watched tenant, or one
+// of its descendants when they're included. Additional subjects include the
+// parent, so deleted tenants are matched using their parent.
+func (s *Server) watchMatches(ctx context.Context, req *tenantv1.WatchRequest, id gidx.PrefixedID, change events.ChangeMessage) (bool, error) {
+	if change.SubjectID == id {
+		return true, nil
+	}
+
+	if !req.GetIncludeDescendants() {
+		return false, nil
+	}
+
+	subjects := append([]gidx.PrefixedID{change.SubjectID}, change.AdditionalSubjectIDs...)
+
+	for _, subject := range subjects {
+		if subject == id {
+			return true, nil
+		}
+
+		if subject.Prefix() != id.Prefix() {
+			continue
+		}
+
+		descendant, err := s.isDescendant(ctx, subject, id)
+
+		switch {
+		case ent.IsNotFound(err):
+			continue
+		case err != nil:
+			return false, err
+		case descendant:
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (s *Server) watchResponse(ctx context.Context, change events.ChangeMessage) (*tenantv1.WatchResponse, error) {
+	resp := &tenantv1.WatchResponse{
+		EventType: change.EventType,
+		TenantId:  change.SubjectID.String(),
+		ActorId:   change.ActorID.String(),
+		Timestamp: timestamppb.New(change.Timestamp),
+	}
+
+	if change.EventType == string(events.DeleteChangeType) {
+		return resp, nil
+	}
+
+	t, err := s.client.Tenant.Get(ctx, change.SubjectID)
+
+	switch {
+	case err == nil:
+		resp.Tenant = toProto(t)
+	case !ent.IsNotFound(err):
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// useCache reports whether the call may read tenants from the cache
+func (s *Server) useCache(ctx context.Context) bool {
+	if s.cache == nil {
+		return false
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, v := range md.Get(graphapi.ConsistencyHeader) {
+		if strings.EqualFold(v, strongConsistency) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Server) getTenant(ctx context.Context, id gidx.PrefixedID) (*ent.Tenant, error) {
+	if s.useCache(ctx) {
+		return s.cache.Get(ctx, id)
+	}
+
+	return s.client.Tenant.Get(ctx, id)
+}
+
+// ancestors returns the ancestors of the tenant, nearest first. A cycle in
+// the hierarchy ends the walk instead of looping forever.
+func (s *Server) ancestors(ctx context.Context, id gidx.PrefixedID) ([]*ent.Tenant, error) {
+	t, err := s.getTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var ancestors []*ent.Tenant
+
+	seen := map[gidx.PrefixedID]bool{t.ID: true}
+
+	for t.ParentTenantID != "" && !seen[t.ParentTenantID] {
+		seen[t.ParentTenantID] = true
+
+		t, err = s.getTenant(ctx, t.ParentTenantID)
+		if err != nil {
+			return nil, err
+		}
+
+		ancestors = append(ancestors, t)
+	}
+
+	return ancestors, nil
+}
+
+func (s *Server) isDescendant(ctx context.Context, id, ancestorID gidx.PrefixedID) (bool, error) {
+	if s.useCache(ctx) {
+		ancestors, err := s.cache.Ancestors(ctx, id)
+		if err != nil {
+			return false, err
+		}
+
+		for _, ancestor := range ancestors {
+			if ancestor == ancestorID {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	ancestors, err := s.ancestors(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	for _, t := range ancestors {
+		if t.ID == ancestorID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func parseID(v string) (gidx.PrefixedID, error) {
+	id, err := gidx.Parse(v)
+	if err != nil {
+		return gidx.NullPrefixedID, status.Errorf(codes.InvalidArgument, "invalid tenant id %q", v)
+	}
+
+	return id, nil
+}
+
+// statusError converts errors returned by ent and the permissions checker to
+// the matching grpc status
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, permissions.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case ent.IsNotFound(err):
+		return status.Error(codes.NotFound, "tenant not found")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func toProto(t *ent.Tenant) *tenantv1.Tenant {
+	return &tenantv1.Tenant{
+		Id:          t.ID.String(),
+		Name:        t.Name,
+		Description: t.Description,
+		ParentId:    t.ParentTenantID.String(),
+		Kind:        t.Kind.String(),
+		CreatedAt:   timestamppb.New(t.CreatedAt),
+		UpdatedAt:   timestamppb.New(t.UpdatedAt),
+	}
+}
+
+func encodeCursor(cursor *ent.Cursor) string {
+	var sb strings.Builder
+
+	cursor.MarshalGQL(&sb)
+
+	return strings.Trim(sb.String(), `"`)
+}
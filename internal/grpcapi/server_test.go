@@ -0,0 +1,246 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/labstack/echo/v4"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+	tenantv1 "go.infratographer.com/tenant-api/pkg/api/tenant/v1"
+)
+
+const testToken = "Bearer test-token"
+
+// testAuth allows calls with the test token and rejects all others
+func testAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return testAuthWith(permissions.DefaultAllowChecker)(next)
+}
+
+// testAuthWith allows calls with the test token, whose permissions are checked
+// by checker, and rejects all others
+func testAuthWith(checker permissions.Checker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get(echo.HeaderAuthorization) != testToken {
+				return echo.ErrUnauthorized
+			}
+
+			ctx := context.WithValue(c.Request().Context(), permissions.CheckerCtxKey, checker)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
+type fakeSubscriber struct {
+	messages chan *message.Message
+}
+
+func (s fakeSubscriber) SubscribeChanges(_ context.Context, _ string) (<-chan *message.Message, error) {
+	return s.messages, nil
+}
+
+func newTestClient(t *testing.T, client *ent.Client, opts ...Option) tenantv1.TenantServiceClient {
+	t.Helper()
+
+	return newTestClientWithAuth(t, client, testAuth, opts...)
+}
+
+func newTestClientWithAuth(t *testing.T, client *ent.Client, auth echo.MiddlewareFunc, opts ...Option) tenantv1.TenantServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+
+	srv := NewServer(client, opts...).GRPCServer([]echo.MiddlewareFunc{auth})
+
+	go srv.Serve(lis) //nolint:errcheck
+
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { conn.Close() })
+
+	return tenantv1.NewTenantServiceClient(conn)
+}
+
+func authCtx() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", testToken)
+}
+
+func TestTenantService(t *testing.T) {
+	ctx := context.Background()
+
+	client := enttest.Open(t, "sqlite3", "file:grpcapi?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+	grandchild := client.Tenant.Create().SetName("grandchild").SetParent(child).SaveX(ctx)
+	client.Tenant.Create().SetName("child-2").SetParent(root).SaveX(ctx)
+	other := client.Tenant.Create().SetName("other").SaveX(ctx)
+
+	c := newTestClient(t, client)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		_, err := c.Get(ctx, &tenantv1.GetRequest{Id: root.ID.String()})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("get", func(t *testing.T) {
+		resp, err := c.Get(authCtx(), &tenantv1.GetRequest{Id: child.ID.String()})
+		require.NoError(t, err)
+		assert.Equal(t, "child", resp.Tenant.Name)
+		assert.Equal(t, root.ID.String(), resp.Tenant.ParentId)
+		assert.Equal(t, "TENANT", resp.Tenant.Kind)
+
+		_, err = c.Get(authCtx(), &tenantv1.GetRequest{Id: gidx.MustNewID("tnntten").String()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = c.Get(authCtx(), &tenantv1.GetRequest{Id: "bad"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("batch get", func(t *testing.T) {
+		missing := gidx.MustNewID("tnntten").String()
+
+		resp, err := c.BatchGet(authCtx(), &tenantv1.BatchGetRequest{Ids: []string{other.ID.String(), missing, root.ID.String()}})
+		require.NoError(t, err)
+		require.Len(t, resp.Tenants, 2)
+		assert.Equal(t, other.ID.String(), resp.Tenants[0].Id)
+		assert.Equal(t, root.ID.String(), resp.Tenants[1].Id)
+		assert.Equal(t, []string{missing}, resp.MissingIds)
+	})
+
+	t.Run("list children", func(t *testing.T) {
+		page1, err := c.ListChildren(authCtx(), &tenantv1.ListChildrenRequest{Id: root.ID.String(), PageSize: 1})
+		require.NoError(t, err)
+		require.Len(t, page1.Tenants, 1)
+		require.NotEmpty(t, page1.NextPageToken)
+
+		page2, err := c.ListChildren(authCtx(), &tenantv1.ListChildrenRequest{Id: root.ID.String(), PageSize: 1, PageToken: page1.NextPageToken})
+		require.NoError(t, err)
+		require.Len(t, page2.Tenants, 1)
+		assert.Empty(t, page2.NextPageToken)
+		assert.NotEqual(t, page1.Tenants[0].Id, page2.Tenants[0].Id)
+	})
+
+	t.Run("ancestors", func(t *testing.T) {
+		resp, err := c.GetAncestors(authCtx(), &tenantv1.GetAncestorsRequest{Id: grandchild.ID.String()})
+		require.NoError(t, err)
+		require.Len(t, resp.Ancestors, 2)
+		assert.Equal(t, child.ID.String(), resp.Ancestors[0].Id)
+		assert.Equal(t, root.ID.String(), resp.Ancestors[1].Id)
+	})
+
+	t.Run("is descendant", func(t *testing.T) {
+		resp, err := c.IsDescendant(authCtx(), &tenantv1.IsDescendantRequest{Id: grandchild.ID.String(), AncestorId: root.ID.String()})
+		require.NoError(t, err)
+		assert.True(t, resp.Descendant)
+
+		resp, err = c.IsDescendant(authCtx(), &tenantv1.IsDescendantRequest{Id: grandchild.ID.String(), AncestorId: other.ID.String()})
+		require.NoError(t, err)
+		assert.False(t, resp.Descendant)
+	})
+
+	t.Run("is descendant of a denied ancestor", func(t *testing.T) {
+		restricted := newTestClientWithAuth(t, client, testAuthWith(func(ctx context.Context, resource gidx.PrefixedID, action string) error {
+			if resource == root.ID {
+				return permissions.ErrPermissionDenied
+			}
+
+			return permissions.DefaultAllowChecker(ctx, resource, action)
+		}))
+
+		_, err := restricted.IsDescendant(authCtx(), &tenantv1.IsDescendantRequest{Id: grandchild.ID.String(), AncestorId: root.ID.String()})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("watch unavailable", func(t *testing.T) {
+		stream, err := c.Watch(authCtx(), &tenantv1.WatchRequest{Id: root.ID.String()})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
+
+func TestWatch(t *testing.T) {
+	ctx := context.Background()
+
+	client := enttest.Open(t, "sqlite3", "file:grpcapi-watch?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+	grandchild := client.Tenant.Create().SetName("grandchild").SetParent(child).SaveX(ctx)
+	other := client.Tenant.Create().SetName("other").SaveX(ctx)
+
+	sub := fakeSubscriber{messages: make(chan *message.Message, 10)}
+
+	c := newTestClient(t, client, WithSubscriber(sub))
+
+	send := func(eventType events.ChangeType, subject gidx.PrefixedID, additional ...gidx.PrefixedID) {
+		payload, err := json.Marshal(events.ChangeMessage{
+			SubjectID:            subject,
+			AdditionalSubjectIDs: additional,
+			EventType:            string(eventType),
+			Timestamp:            time.Now(),
+		})
+		require.NoError(t, err)
+
+		sub.messages <- message.NewMessage(watermill.NewUUID(), payload)
+	}
+
+	watchCtx, cancel := context.WithCancel(authCtx())
+	defer cancel()
+
+	stream, err := c.Watch(watchCtx, &tenantv1.WatchRequest{Id: child.ID.String(), IncludeDescendants: true})
+	require.NoError(t, err)
+
+	send(events.UpdateChangeType, other.ID)
+	send(events.UpdateChangeType, root.ID)
+	send(events.UpdateChangeType, grandchild.ID, child.ID)
+	send(events.DeleteChangeType, gidx.MustNewID("tnntten"), grandchild.ID)
+	send(events.UpdateChangeType, child.ID, root.ID)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, grandchild.ID.String(), resp.TenantId)
+	assert.Equal(t, "grandchild", resp.Tenant.GetName())
+
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "delete", resp.EventType)
+	assert.Nil(t, resp.Tenant)
+
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, child.ID.String(), resp.TenantId)
+}
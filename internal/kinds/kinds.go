@@ -0,0 +1,172 @@
+// Package kinds enforces the kind graph of the tenant hierarchy, which kinds
+// of tenants may be children of which, so an organization can't be created
+// under a project.
+//
+// The graph is a list of rules in the config file, each listing the kinds a
+// kind may be a child of and whether it may be a root tenant. Kinds without a
+// rule aren't restricted. The rules are enforced by an ent hook when a tenant
+// is created, with the parent loaded by the mutation's client, so they're
+// checked in the transaction the tenant is created in.
+package kinds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"entgo.io/ent"
+	"go.infratographer.com/x/gidx"
+
+	generated "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+)
+
+var (
+	// ErrKindNotAllowed is returned when a tenant's kind can't be a child of
+	// its parent's kind, or can't be a root tenant
+	ErrKindNotAllowed = errors.New("tenant kind not allowed")
+	// ErrInvalidKinds is returned when the kind graph is misconfigured
+	ErrInvalidKinds = errors.New("invalid tenant kinds")
+)
+
+// Config stores the configuration for the kind graph, it can only be set in
+// the config file
+type Config struct {
+	// Rules are the parents allowed for each kind, kinds without a rule can be
+	// created anywhere
+	Rules []Rule `mapstructure:"rules"`
+}
+
+// Enabled returns whether any rule is set
+func (cfg Config) Enabled() bool {
+	return len(cfg.Rules) > 0
+}
+
+// Rule restricts where tenants of a kind can be created
+type Rule struct {
+	// Kind is the kind the rule applies to, such as PROJECT
+	Kind string `mapstructure:"kind"`
+	// Parents are the kinds tenants of the kind may be children of
+	Parents []string `mapstructure:"parents"`
+	// Root allows tenants of the kind to be root tenants
+	Root bool `mapstructure:"root"`
+}
+
+// rule is a compiled Rule
+type rule struct {
+	parents map[tenant.Kind]bool
+	root    bool
+}
+
+// Graph enforces the kind graph of the tenant hierarchy
+type Graph struct {
+	rules map[tenant.Kind]rule
+}
+
+// NewGraph returns a graph enforcing the configured rules
+func NewGraph(cfg Config) (*Graph, error) {
+	g := &Graph{rules: make(map[tenant.Kind]rule, len(cfg.Rules))}
+
+	for _, r := range cfg.Rules {
+		kind, err := parseKind(r.Kind)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := g.rules[kind]; ok {
+			return nil, fmt.Errorf("%w: %s: duplicate rule", ErrInvalidKinds, kind)
+		}
+
+		compiled := rule{parents: make(map[tenant.Kind]bool, len(r.Parents)), root: r.Root}
+
+		for _, p := range r.Parents {
+			parent, err := parseKind(p)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", kind, err)
+			}
+
+			compiled.parents[parent] = true
+		}
+
+		if !compiled.root && len(compiled.parents) == 0 {
+			return nil, fmt.Errorf("%w: %s: the kind can't be a root or a child", ErrInvalidKinds, kind)
+		}
+
+		g.rules[kind] = compiled
+	}
+
+	return g, nil
+}
+
+// parseKind returns the kind named by s, which is case-insensitive
+func parseKind(s string) (tenant.Kind, error) {
+	kind := tenant.Kind(strings.ToUpper(strings.TrimSpace(s)))
+
+	if err := tenant.KindValidator(kind); err != nil {
+		return "", fmt.Errorf("%w: unknown kind %q", ErrInvalidKinds, s)
+	}
+
+	return kind, nil
+}
+
+// Allowed returns an error when a tenant of the kind can't be a child of the
+// parent kind, an empty parent kind is a root tenant
+func (g *Graph) Allowed(kind, parent tenant.Kind) error {
+	r, ok := g.rules[kind]
+	if !ok {
+		return nil
+	}
+
+	if parent == "" {
+		if !r.root {
+			return fmt.Errorf("%w: %s tenants can't be root tenants", ErrKindNotAllowed, kind)
+		}
+
+		return nil
+	}
+
+	if !r.parents[parent] {
+		return fmt.Errorf("%w: %s tenants can't be children of %s tenants", ErrKindNotAllowed, kind, parent)
+	}
+
+	return nil
+}
+
+// Hook is an ent hook which rejects the creation of tenants whose kind isn't
+// allowed under their parent with ErrKindNotAllowed
+func (g *Graph) Hook(next ent.Mutator) ent.Mutator {
+	return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+		tm, ok := m.(*generated.TenantMutation)
+		if !ok || !m.Op().Is(ent.OpCreate) {
+			return next.Mutate(ctx, m)
+		}
+
+		kind, ok := tm.Kind()
+		if !ok {
+			kind = tenant.DefaultKind
+		}
+
+		var parentKind tenant.Kind
+
+		if parent, ok := tm.ParentTenantID(); ok && parent != gidx.NullPrefixedID {
+			p, err := tm.Client().Tenant.Get(ctx, parent)
+
+			switch {
+			case generated.IsNotFound(err):
+				// the mutation fails with a missing parent
+				return next.Mutate(ctx, m)
+			case err != nil:
+				return nil, fmt.Errorf("loading parent %s: %w", parent, err)
+			}
+
+			parentKind = p.Kind
+		}
+
+		if err := g.Allowed(kind, parentKind); err != nil {
+			return nil, err
+		}
+
+		return next.Mutate(ctx, m)
+	})
+}
@@ -0,0 +1,121 @@
+package kinds
+
+import (
+	"context"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+)
+
+var hierarchy = Config{Rules: []Rule{
+	{Kind: "ORGANIZATION", Root: true},
+	{Kind: "business_unit", Parents: []string{"ORGANIZATION"}},
+	{Kind: "PROJECT", Parents: []string{"ORGANIZATION", "BUSINESS_UNIT"}},
+	{Kind: "ENVIRONMENT", Parents: []string{"PROJECT"}},
+}}
+
+func TestNewGraph(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "empty"},
+		{name: "hierarchy", cfg: hierarchy},
+		{
+			name: "unknown kind",
+			cfg:  Config{Rules: []Rule{{Kind: "TEAM", Root: true}}},
+			err:  `unknown kind "TEAM"`,
+		},
+		{
+			name: "unknown parent",
+			cfg:  Config{Rules: []Rule{{Kind: "PROJECT", Parents: []string{"TEAM"}}}},
+			err:  `PROJECT: invalid tenant kinds: unknown kind "TEAM"`,
+		},
+		{
+			name: "duplicate",
+			cfg:  Config{Rules: []Rule{{Kind: "PROJECT", Root: true}, {Kind: "project", Root: true}}},
+			err:  "PROJECT: duplicate rule",
+		},
+		{
+			name: "unusable",
+			cfg:  Config{Rules: []Rule{{Kind: "PROJECT"}}},
+			err:  "PROJECT: the kind can't be a root or a child",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGraph(tt.cfg)
+
+			if tt.err == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrInvalidKinds)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestGraphAllowed(t *testing.T) {
+	g, err := NewGraph(hierarchy)
+	require.NoError(t, err)
+
+	assert.NoError(t, g.Allowed(tenant.KindOrganization, ""))
+	assert.NoError(t, g.Allowed(tenant.KindProject, tenant.KindBusinessUnit))
+	assert.NoError(t, g.Allowed(tenant.KindEnvironment, tenant.KindProject))
+
+	// kinds without a rule aren't restricted
+	assert.NoError(t, g.Allowed(tenant.KindTenant, ""))
+	assert.NoError(t, g.Allowed(tenant.KindTenant, tenant.KindEnvironment))
+
+	err = g.Allowed(tenant.KindProject, "")
+	assert.ErrorIs(t, err, ErrKindNotAllowed)
+	assert.ErrorContains(t, err, "PROJECT tenants can't be root tenants")
+
+	err = g.Allowed(tenant.KindOrganization, tenant.KindProject)
+	assert.ErrorIs(t, err, ErrKindNotAllowed)
+	assert.ErrorContains(t, err, "ORGANIZATION tenants can't be children of PROJECT tenants")
+}
+
+func TestHook(t *testing.T) {
+	ctx := context.Background()
+
+	client := enttest.Open(t, "sqlite3", "file:kinds-hook?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	g, err := NewGraph(hierarchy)
+	require.NoError(t, err)
+
+	client.Use(g.Hook)
+
+	org := client.Tenant.Create().SetName("org").SetKind(tenant.KindOrganization).SaveX(ctx)
+	project := client.Tenant.Create().SetName("project").SetKind(tenant.KindProject).SetParent(org).SaveX(ctx)
+	client.Tenant.Create().SetName("env").SetKind(tenant.KindEnvironment).SetParent(project).ExecX(ctx)
+
+	// the default kind isn't restricted
+	client.Tenant.Create().SetName("plain").SetParent(project).ExecX(ctx)
+
+	_, err = client.Tenant.Create().SetName("orphan").SetKind(tenant.KindProject).Save(ctx)
+	assert.ErrorIs(t, err, ErrKindNotAllowed)
+
+	// the parent is loaded in the caller's transaction
+	err = client.WithTx(ctx, func(tx *ent.Tx) error {
+		bu := tx.Tenant.Create().SetName("bu").SetKind(tenant.KindBusinessUnit).SetParent(org).SaveX(ctx)
+
+		return tx.Tenant.Create().SetName("nested-env").SetKind(tenant.KindEnvironment).SetParent(bu).Exec(ctx)
+	})
+	assert.ErrorIs(t, err, ErrKindNotAllowed)
+
+	assert.Equal(t, 4, client.Tenant.Query().CountX(ctx))
+	assert.Equal(t, 1, client.Tenant.Query().Where(tenant.KindEQ(tenant.KindProject)).CountX(ctx))
+}
@@ -0,0 +1,223 @@
+// Package limits bounds the shape of the tenant hierarchy, so a script can't
+// create a chain of tenants or a number of children under one parent which
+// breaks every recursive consumer of the hierarchy.
+//
+// The depth of the tree and the number of children per parent are limited
+// globally, and the limits can be overridden for the tree under a root tenant.
+// They're enforced by an ent hook when a tenant is created, with the queries
+// made by the mutation's client, so they're checked in the transaction the
+// tenant is created in.
+package limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"entgo.io/ent"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/gidx"
+	"go.infratographer.com/x/viperx"
+
+	generated "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/metrics"
+)
+
+const (
+	// LimitDepth is the limit on the depth of the tree, root tenants have a
+	// depth of one
+	LimitDepth = "depth"
+	// LimitChildren is the limit on the number of children of a tenant
+	LimitChildren = "children"
+)
+
+var (
+	// ErrMaxDepth is returned when a tenant would be deeper than its root's
+	// depth limit
+	ErrMaxDepth = errors.New("tenant hierarchy depth limit exceeded")
+	// ErrMaxChildren is returned when a tenant would have more children than
+	// its root's children limit
+	ErrMaxChildren = errors.New("tenant children limit exceeded")
+	// ErrInvalidLimits is returned when the limits are misconfigured
+	ErrInvalidLimits = errors.New("invalid tenant limits")
+)
+
+// Config stores the configuration for the tenant hierarchy limits
+type Config struct {
+	// MaxDepth is the deepest a tenant can be, 0 doesn't limit it
+	MaxDepth int `mapstructure:"max_depth"`
+	// MaxChildren is the most children a tenant can have, 0 doesn't limit it
+	MaxChildren int `mapstructure:"max_children"`
+	// Overrides are the limits of the trees under specific root tenants, they
+	// can only be set in the config file
+	Overrides []Override `mapstructure:"overrides"`
+}
+
+// Enabled returns whether any limit is set
+func (cfg Config) Enabled() bool {
+	return cfg.MaxDepth > 0 || cfg.MaxChildren > 0 || len(cfg.Overrides) > 0
+}
+
+// Override overrides the limits for the tree under a root tenant, a limit
+// which isn't set keeps its global value
+type Override struct {
+	RootID      gidx.PrefixedID `mapstructure:"root_id"`
+	MaxDepth    int             `mapstructure:"max_depth"`
+	MaxChildren int             `mapstructure:"max_children"`
+}
+
+// MustViperFlags returns the cobra flags and viper config for the tenant hierarchy limits
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Int("max-tenant-depth", 0, "deepest a tenant can be in the hierarchy, root tenants have a depth of one, 0 doesn't limit it")
+	viperx.MustBindFlag(v, "limits.max_depth", flags.Lookup("max-tenant-depth"))
+
+	flags.Int("max-tenant-children", 0, "most children a tenant can have, 0 doesn't limit it")
+	viperx.MustBindFlag(v, "limits.max_children", flags.Lookup("max-tenant-children"))
+}
+
+// Limits are the limits of a tree, 0 doesn't limit it
+type Limits struct {
+	MaxDepth    int
+	MaxChildren int
+}
+
+// Limiter enforces the limits of the tenant hierarchy
+type Limiter struct {
+	global    Limits
+	overrides map[gidx.PrefixedID]Limits
+}
+
+// NewLimiter returns a limiter enforcing the configured limits
+func NewLimiter(cfg Config) (*Limiter, error) {
+	if cfg.MaxDepth < 0 || cfg.MaxChildren < 0 {
+		return nil, fmt.Errorf("%w: limits can't be negative", ErrInvalidLimits)
+	}
+
+	l := &Limiter{
+		global:    Limits{MaxDepth: cfg.MaxDepth, MaxChildren: cfg.MaxChildren},
+		overrides: make(map[gidx.PrefixedID]Limits, len(cfg.Overrides)),
+	}
+
+	for _, o := range cfg.Overrides {
+		if o.RootID == gidx.NullPrefixedID {
+			return nil, fmt.Errorf("%w: override is missing its root_id", ErrInvalidLimits)
+		}
+
+		if o.MaxDepth < 0 || o.MaxChildren < 0 {
+			return nil, fmt.Errorf("%w: %s: limits can't be negative", ErrInvalidLimits, o.RootID)
+		}
+
+		if _, ok := l.overrides[o.RootID]; ok {
+			return nil, fmt.Errorf("%w: %s: duplicate override", ErrInvalidLimits, o.RootID)
+		}
+
+		limits := l.global
+
+		if o.MaxDepth > 0 {
+			limits.MaxDepth = o.MaxDepth
+		}
+
+		if o.MaxChildren > 0 {
+			limits.MaxChildren = o.MaxChildren
+		}
+
+		l.overrides[o.RootID] = limits
+	}
+
+	return l, nil
+}
+
+// For returns the limits of the tree under the root tenant
+func (l *Limiter) For(root gidx.PrefixedID) Limits {
+	if limits, ok := l.overrides[root]; ok {
+		return limits
+	}
+
+	return l.global
+}
+
+// Hook is an ent hook which rejects the creation of tenants exceeding the
+// limits of their root with ErrMaxDepth or ErrMaxChildren
+func (l *Limiter) Hook(next ent.Mutator) ent.Mutator {
+	return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+		tm, ok := m.(*generated.TenantMutation)
+		if !ok || !m.Op().Is(ent.OpCreate) {
+			return next.Mutate(ctx, m)
+		}
+
+		// root tenants are only limited by the depth limit, which is at least one
+		parent, ok := tm.ParentTenantID()
+		if !ok || parent == gidx.NullPrefixedID {
+			return next.Mutate(ctx, m)
+		}
+
+		if err := l.check(ctx, tm.Client(), parent); err != nil {
+			return nil, err
+		}
+
+		return next.Mutate(ctx, m)
+	})
+}
+
+// check returns an error when a child can't be created under the parent
+func (l *Limiter) check(ctx context.Context, client *generated.Client, parent gidx.PrefixedID) error {
+	root, parentDepth, err := rootOf(ctx, client, parent)
+	if err != nil {
+		return err
+	}
+
+	limits := l.For(root)
+
+	if depth := parentDepth + 1; limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		metrics.RecordLimitRejection(LimitDepth)
+
+		return fmt.Errorf("%w: the tenant would have a depth of %d, the limit under root %s is %d", ErrMaxDepth, depth, root, limits.MaxDepth)
+	}
+
+	if limits.MaxChildren > 0 {
+		children, err := client.Tenant.Query().Where(tenant.ParentTenantID(parent)).Count(ctx)
+		if err != nil {
+			return fmt.Errorf("counting children of %s: %w", parent, err)
+		}
+
+		if children >= limits.MaxChildren {
+			metrics.RecordLimitRejection(LimitChildren)
+
+			return fmt.Errorf("%w: %s already has %d children, the limit under root %s is %d", ErrMaxChildren, parent, children, root, limits.MaxChildren)
+		}
+	}
+
+	return nil
+}
+
+// rootOf returns the root of the tenant and the tenant's depth
+func rootOf(ctx context.Context, client *generated.Client, id gidx.PrefixedID) (gidx.PrefixedID, int, error) {
+	depth := 0
+	seen := map[gidx.PrefixedID]bool{}
+
+	// guard against a cycle in corrupt data looping forever
+	for !seen[id] {
+		seen[id] = true
+		depth++
+
+		t, err := client.Tenant.Get(ctx, id)
+
+		switch {
+		case generated.IsNotFound(err):
+			// a missing parent fails the mutation, the chain ends before it
+			return id, depth, nil
+		case err != nil:
+			return gidx.NullPrefixedID, 0, fmt.Errorf("loading ancestor %s: %w", id, err)
+		}
+
+		if t.ParentTenantID == gidx.NullPrefixedID {
+			return id, depth, nil
+		}
+
+		id = t.ParentTenantID
+	}
+
+	return id, depth, nil
+}
@@ -0,0 +1,119 @@
+package limits
+
+import (
+	"context"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/gidx"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+)
+
+func newTestClient(t *testing.T, name string, cfg Config) *ent.Client {
+	t.Helper()
+
+	client := enttest.Open(t, "sqlite3", "file:"+name+"?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { client.Close() })
+
+	limiter, err := NewLimiter(cfg)
+	require.NoError(t, err)
+
+	client.Use(limiter.Hook)
+
+	return client
+}
+
+func TestHookMaxDepth(t *testing.T) {
+	ctx := context.Background()
+
+	client := newTestClient(t, "limits-depth", Config{MaxDepth: 3})
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+	grandchild := client.Tenant.Create().SetName("grandchild").SetParent(child).SaveX(ctx)
+
+	_, err := client.Tenant.Create().SetName("too-deep").SetParent(grandchild).Save(ctx)
+	require.ErrorIs(t, err, ErrMaxDepth)
+	assert.ErrorContains(t, err, "would have a depth of 4, the limit under root "+root.ID.String()+" is 3")
+
+	client.Tenant.Create().SetName("sibling").SetParent(child).ExecX(ctx)
+	client.Tenant.Create().SetName("other-root").ExecX(ctx)
+}
+
+func TestHookMaxChildren(t *testing.T) {
+	ctx := context.Background()
+
+	client := newTestClient(t, "limits-children", Config{MaxChildren: 2})
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	client.Tenant.Create().SetName("first").SetParent(root).ExecX(ctx)
+	second := client.Tenant.Create().SetName("second").SetParent(root).SaveX(ctx)
+
+	_, err := client.Tenant.Create().SetName("third").SetParent(root).Save(ctx)
+	require.ErrorIs(t, err, ErrMaxChildren)
+	assert.ErrorContains(t, err, "already has 2 children")
+
+	client.Tenant.Create().SetName("grandchild").SetParent(second).ExecX(ctx)
+
+	// the check runs in the caller's transaction, so a rejected create rolls
+	// back everything before it
+	err = client.WithTx(ctx, func(tx *ent.Tx) error {
+		tx.Tenant.Create().SetName("other-grandchild").SetParent(second).ExecX(ctx)
+
+		return tx.Tenant.Create().SetName("third-grandchild").SetParent(second).Exec(ctx)
+	})
+	require.ErrorIs(t, err, ErrMaxChildren)
+
+	assert.Equal(t, 4, client.Tenant.Query().CountX(ctx))
+}
+
+func TestHookOverrides(t *testing.T) {
+	ctx := context.Background()
+
+	big := gidx.MustNewID("tnntten")
+
+	client := newTestClient(t, "limits-overrides", Config{
+		MaxDepth:    2,
+		MaxChildren: 1,
+		Overrides:   []Override{{RootID: big, MaxDepth: 3}},
+	})
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+
+	_, err := client.Tenant.Create().SetName("grandchild").SetParent(child).Save(ctx)
+	assert.ErrorIs(t, err, ErrMaxDepth)
+
+	bigRoot := client.Tenant.Create().SetID(big).SetName("big").SaveX(ctx)
+	bigChild := client.Tenant.Create().SetName("child").SetParent(bigRoot).SaveX(ctx)
+	client.Tenant.Create().SetName("grandchild").SetParent(bigChild).ExecX(ctx)
+
+	_, err = client.Tenant.Create().SetName("second-child").SetParent(bigRoot).Save(ctx)
+	assert.ErrorIs(t, err, ErrMaxChildren, "limits which aren't overridden keep their global value")
+}
+
+func TestNewLimiterErrors(t *testing.T) {
+	root := gidx.MustNewID("tnntten")
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{name: "negative depth", cfg: Config{MaxDepth: -1}},
+		{name: "negative children", cfg: Config{MaxChildren: -1}},
+		{name: "override without root", cfg: Config{Overrides: []Override{{MaxDepth: 1}}}},
+		{name: "negative override", cfg: Config{Overrides: []Override{{RootID: root, MaxChildren: -1}}}},
+		{name: "duplicate override", cfg: Config{Overrides: []Override{{RootID: root, MaxDepth: 1}, {RootID: root, MaxDepth: 2}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLimiter(tt.cfg)
+			assert.ErrorIs(t, err, ErrInvalidLimits)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+const defaultTenantStatsInterval = time.Minute
+
+// Config stores the configuration for the tenant-api metrics
+type Config struct {
+	// TenantStatsInterval is how often the tenant count and depth gauges are refreshed, zero disables them
+	TenantStatsInterval time.Duration `mapstructure:"tenant_stats_interval"`
+}
+
+// MustViperFlags returns the cobra flags and viper config for metrics
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Duration("metrics-tenant-stats-interval", defaultTenantStatsInterval, "how often to refresh the tenant count and depth metrics, 0 disables them")
+	viperx.MustBindFlag(v, "metrics.tenant_stats_interval", flags.Lookup("metrics-tenant-stats-interval"))
+}
@@ -0,0 +1,306 @@
+// Package metrics provides the prometheus metrics exposed by tenant-api.
+//
+// Collectors are registered with the default prometheus registry, which is
+// served on /metrics by the echox server.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"entgo.io/ent"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/tenant-api/internal/pubsub"
+)
+
+const namespace = "tenantapi"
+
+const (
+	outcomeSuccess = "success"
+	outcomeError   = "error"
+	outcomeAllowed = "allowed"
+	outcomeDenied  = "denied"
+)
+
+var (
+	graphqlOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "graphql",
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of GraphQL operations by operation name and type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "type"})
+
+	graphqlOperationErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "graphql",
+		Name:      "operation_errors_total",
+		Help:      "Number of GraphQL operations which returned errors by operation name and type.",
+	}, []string{"operation", "type"})
+
+	mutations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mutations_total",
+		Help:      "Number of database mutations by entity type, operation and outcome.",
+	}, []string{"type", "op", "outcome"})
+
+	permissionCheckDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "permissions",
+		Name:      "check_duration_seconds",
+		Help:      "Duration of permission checks by action and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"action", "outcome"})
+
+	permissionDenials = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "permissions",
+		Name:      "denied_total",
+		Help:      "Number of permission checks which were denied by action.",
+	}, []string{"action"})
+
+	eventPublishDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "events",
+		Name:      "publish_duration_seconds",
+		Help:      "Duration of publishing change events by subject type and event type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"subject_type", "event_type"})
+
+	eventPublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "events",
+		Name:      "publish_failures_total",
+		Help:      "Number of change events which failed to publish by subject type and event type.",
+	}, []string{"subject_type", "event_type"})
+
+	eventBackendPublishDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "events",
+		Name:      "backend_publish_duration_seconds",
+		Help:      "Duration of publishing change events to each backend by backend and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"backend", "outcome"})
+
+	eventBackendPublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "events",
+		Name:      "backend_publish_failures_total",
+		Help:      "Number of change events which failed to publish to each backend by backend and policy.",
+	}, []string{"backend", "policy"})
+
+	admissionReviews = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "admission",
+		Name:      "review_duration_seconds",
+		Help:      "Duration of admission hook reviews by hook and result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"hook", "result"})
+
+	cacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "lookups_total",
+		Help:      "Number of tenant cache lookups by kind and result.",
+	}, []string{"kind", "result"})
+
+	cacheInvalidations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "invalidations_total",
+		Help:      "Number of tenants invalidated in the tenant cache by source.",
+	}, []string{"source"})
+
+	webhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhooks",
+		Name:      "delivery_attempts_total",
+		Help:      "Number of webhook delivery attempts by result.",
+	}, []string{"result"})
+
+	limitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "limits",
+		Name:      "rejections_total",
+		Help:      "Number of tenant creations rejected by the tenant hierarchy limits by limit.",
+	}, []string{"limit"})
+)
+
+// ObserveGraphQLOperation records the duration of a GraphQL operation and
+// whether it returned any errors.
+func ObserveGraphQLOperation(operation, opType string, duration time.Duration, failed bool) {
+	graphqlOperationDuration.WithLabelValues(operation, opType).Observe(duration.Seconds())
+
+	if failed {
+		graphqlOperationErrors.WithLabelValues(operation, opType).Inc()
+	}
+}
+
+// MutationHook is an ent hook which counts mutations by type, operation and outcome.
+func MutationHook(next ent.Mutator) ent.Mutator {
+	return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+		v, err := next.Mutate(ctx, m)
+
+		outcome := outcomeSuccess
+		if err != nil {
+			outcome = outcomeError
+		}
+
+		mutations.WithLabelValues(m.Type(), m.Op().String(), outcome).Inc()
+
+		return v, err
+	})
+}
+
+// PermissionsMiddleware wraps the permissions checker set on the request by
+// the permissions middleware to record check durations and denials. It must
+// be added after the permissions middleware.
+func PermissionsMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+
+			if checker, ok := ctx.Value(permissions.CheckerCtxKey).(permissions.Checker); ok {
+				ctx = context.WithValue(ctx, permissions.CheckerCtxKey, InstrumentChecker(checker))
+
+				c.SetRequest(c.Request().WithContext(ctx))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// InstrumentChecker wraps a permissions checker to record check durations and denials.
+func InstrumentChecker(checker permissions.Checker) permissions.Checker {
+	return func(ctx context.Context, resource gidx.PrefixedID, action string) error {
+		start := time.Now()
+
+		err := checker(ctx, resource, action)
+
+		outcome := outcomeAllowed
+
+		switch {
+		case errors.Is(err, permissions.ErrPermissionDenied):
+			outcome = outcomeDenied
+
+			permissionDenials.WithLabelValues(action).Inc()
+		case err != nil:
+			outcome = outcomeError
+		}
+
+		permissionCheckDuration.WithLabelValues(action, outcome).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+type publisher struct {
+	pubsub.Publisher
+}
+
+// InstrumentPublisher wraps a publisher to record publish durations and failures.
+func InstrumentPublisher(p pubsub.Publisher) pubsub.Publisher {
+	return &publisher{Publisher: p}
+}
+
+// PublishChange publishes the change, recording how long it took and whether it failed.
+func (p *publisher) PublishChange(ctx context.Context, subjectType string, change events.ChangeMessage) error {
+	start := time.Now()
+
+	err := p.Publisher.PublishChange(ctx, subjectType, change)
+
+	eventPublishDuration.WithLabelValues(subjectType, change.EventType).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		eventPublishFailures.WithLabelValues(subjectType, change.EventType).Inc()
+	}
+
+	return err
+}
+
+// RecordCacheLookup counts a tenant cache lookup of the kind of entry and
+// whether it was found
+func RecordCacheLookup(kind string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+
+	cacheLookups.WithLabelValues(kind, result).Inc()
+}
+
+// RecordCacheInvalidation counts a tenant invalidated in the tenant cache
+func RecordCacheInvalidation(source string) {
+	cacheInvalidations.WithLabelValues(source).Inc()
+}
+
+// RegisterDBStats registers a collector for the connection pool stats of the database.
+func RegisterDBStats(db *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RecordWebhookDelivery counts a webhook delivery attempt by its result,
+// delivered, retrying or dead
+func RecordWebhookDelivery(result string) {
+	webhookDeliveries.WithLabelValues(result).Inc()
+}
+
+// RecordLimitRejection records a tenant creation rejected by a tenant
+// hierarchy limit.
+func RecordLimitRejection(limit string) {
+	limitRejections.WithLabelValues(limit).Inc()
+}
+
+// ObserveAdmissionReview records the duration of an admission hook review by
+// its result, allowed, denied, failed_open or failed_closed
+func ObserveAdmissionReview(hook, result string, duration time.Duration) {
+	admissionReviews.WithLabelValues(hook, result).Observe(duration.Seconds())
+}
+
+type backendPublisher struct {
+	pubsub.Publisher
+	name   string
+	policy pubsub.Policy
+}
+
+// InstrumentBackend wraps the publisher of a fan-out backend to record publish
+// durations and failures by backend, including best effort failures which
+// aren't returned by the fan-out publisher.
+func InstrumentBackend(b pubsub.Backend) pubsub.Backend {
+	policy := b.Policy
+	if policy == "" {
+		policy = pubsub.PolicyRequired
+	}
+
+	b.Publisher = &backendPublisher{Publisher: b.Publisher, name: b.Name, policy: policy}
+
+	return b
+}
+
+// PublishChange publishes the change to the backend, recording how long it took and whether it failed.
+func (p *backendPublisher) PublishChange(ctx context.Context, subjectType string, change events.ChangeMessage) error {
+	start := time.Now()
+
+	err := p.Publisher.PublishChange(ctx, subjectType, change)
+
+	outcome := outcomeSuccess
+	if err != nil {
+		outcome = outcomeError
+
+		eventBackendPublishFailures.WithLabelValues(p.name, string(p.policy)).Inc()
+	}
+
+	eventBackendPublishDuration.WithLabelValues(p.name, outcome).Observe(time.Since(start).Seconds())
+
+	return err
+}
@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+	"go.infratographer.com/tenant-api/internal/pubsub"
+)
+
+var errTest = errors.New("test error")
+
+func TestInstrumentChecker(t *testing.T) {
+	ctx := context.Background()
+
+	allowed := InstrumentChecker(permissions.DefaultAllowChecker)
+	denied := InstrumentChecker(permissions.DefaultDenyChecker)
+
+	assert.NoError(t, allowed(ctx, gidx.NullPrefixedID, "metrics_test"))
+	assert.ErrorIs(t, denied(ctx, gidx.NullPrefixedID, "metrics_test"), permissions.ErrPermissionDenied)
+	assert.ErrorIs(t, denied(ctx, gidx.NullPrefixedID, "metrics_test"), permissions.ErrPermissionDenied)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(permissionDenials.WithLabelValues("metrics_test")))
+}
+
+type publisherFunc func(ctx context.Context, subjectType string, change events.ChangeMessage) error
+
+func (f publisherFunc) PublishChange(ctx context.Context, subjectType string, change events.ChangeMessage) error {
+	return f(ctx, subjectType, change)
+}
+
+func TestInstrumentPublisher(t *testing.T) {
+	ctx := context.Background()
+
+	var published int
+
+	p := InstrumentPublisher(publisherFunc(func(_ context.Context, _ string, change events.ChangeMessage) error {
+		published++
+
+		if change.EventType == "fail" {
+			return errTest
+		}
+
+		return nil
+	}))
+
+	assert.NoError(t, p.PublishChange(ctx, "metrics-test", events.ChangeMessage{EventType: "create"}))
+	assert.ErrorIs(t, p.PublishChange(ctx, "metrics-test", events.ChangeMessage{EventType: "fail"}), errTest)
+
+	assert.Equal(t, 2, published)
+	assert.Equal(t, float64(0), testutil.ToFloat64(eventPublishFailures.WithLabelValues("metrics-test", "create")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(eventPublishFailures.WithLabelValues("metrics-test", "fail")))
+}
+
+func TestInstrumentBackend(t *testing.T) {
+	ctx := context.Background()
+
+	failing := publisherFunc(func(context.Context, string, events.ChangeMessage) error { return errTest })
+
+	required := InstrumentBackend(pubsub.Backend{Name: "metrics-required", Publisher: failing})
+	bestEffort := InstrumentBackend(pubsub.Backend{Name: "metrics-best-effort", Policy: pubsub.PolicyBestEffort, Publisher: failing})
+
+	assert.Equal(t, pubsub.Policy(""), required.Policy, "the backend's policy isn't changed")
+
+	assert.ErrorIs(t, required.Publisher.PublishChange(ctx, "metrics-test", events.ChangeMessage{}), errTest)
+	assert.ErrorIs(t, bestEffort.Publisher.PublishChange(ctx, "metrics-test", events.ChangeMessage{}), errTest)
+	assert.ErrorIs(t, bestEffort.Publisher.PublishChange(ctx, "metrics-test", events.ChangeMessage{}), errTest)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(eventBackendPublishFailures.WithLabelValues("metrics-required", "required")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(eventBackendPublishFailures.WithLabelValues("metrics-best-effort", "best_effort")))
+}
+
+func TestMutationHookAndTenantStats(t *testing.T) {
+	ctx := context.Background()
+
+	client := enttest.Open(t, "sqlite3", "file:metrics?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	client.Use(MutationHook)
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+	client.Tenant.Create().SetName("grandchild").SetParent(child).SaveX(ctx)
+	client.Tenant.Create().SetName("other-root").SaveX(ctx)
+
+	_, err := client.Tenant.UpdateOneID(gidx.MustNewID("testing")).SetName("missing").Save(ctx)
+	require.Error(t, err)
+
+	assert.Equal(t, float64(4), testutil.ToFloat64(mutations.WithLabelValues("Tenant", "OpCreate", outcomeSuccess)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(mutations.WithLabelValues("Tenant", "OpUpdateOne", outcomeError)))
+
+	stats := NewTenantStats(client, time.Minute, zap.NewNop().Sugar())
+	require.NoError(t, stats.Refresh(ctx))
+
+	assert.Equal(t, float64(4), testutil.ToFloat64(tenantCount))
+	assert.Equal(t, float64(3), testutil.ToFloat64(tenantMaxDepth))
+}
+
+func TestTenantStatsRootLimits(t *testing.T) {
+	ctx := context.Background()
+
+	client := enttest.Open(t, "sqlite3", "file:metrics-limits?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+	client.Tenant.Create().SetName("grandchild").SetParent(child).ExecX(ctx)
+	client.Tenant.Create().SetName("second-child").SetParent(root).ExecX(ctx)
+	client.Tenant.Create().SetName("third-child").SetParent(root).ExecX(ctx)
+	other := client.Tenant.Create().SetName("other-root").SaveX(ctx)
+
+	stats := NewTenantStats(client, time.Minute, zap.NewNop().Sugar(), WithRootLimits(func(id gidx.PrefixedID) (int, int) {
+		if id == root.ID {
+			return 4, 10
+		}
+
+		return 0, 0
+	}))
+	require.NoError(t, stats.Refresh(ctx))
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(rootDepth.WithLabelValues(root.ID.String())))
+	assert.Equal(t, float64(3), testutil.ToFloat64(rootMaxChildren.WithLabelValues(root.ID.String())))
+	assert.Equal(t, 0.75, testutil.ToFloat64(rootLimitUsage.WithLabelValues(root.ID.String(), "depth")))
+	assert.Equal(t, 0.3, testutil.ToFloat64(rootLimitUsage.WithLabelValues(root.ID.String(), "children")))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(rootDepth.WithLabelValues(other.ID.String())))
+	assert.Equal(t, float64(0), testutil.ToFloat64(rootMaxChildren.WithLabelValues(other.ID.String())))
+	assert.Equal(t, 2, testutil.CollectAndCount(rootLimitUsage), "usage isn't recorded for roots without limits")
+}
@@ -0,0 +1,200 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.infratographer.com/x/gidx"
+	"go.uber.org/zap"
+)
+
+// tenantStatsQuery counts the tenants and finds the deepest tenant reachable from a root,
+// where a root tenant has a depth of one.
+const tenantStatsQuery = `WITH RECURSIVE tree (id, depth) AS (
+	SELECT id, 1 FROM tenants WHERE parent_tenant_id IS NULL
+	UNION ALL
+	SELECT t.id, tree.depth + 1 FROM tenants t JOIN tree ON t.parent_tenant_id = tree.id
+)
+SELECT (SELECT COUNT(*) FROM tenants), COALESCE(MAX(depth), 0) FROM tree`
+
+// rootStatsQuery finds the deepest tenant and the most children of a tenant
+// in the tree under each root tenant.
+const rootStatsQuery = `WITH RECURSIVE tree (id, root_id, depth) AS (
+	SELECT id, id, 1 FROM tenants WHERE parent_tenant_id IS NULL
+	UNION ALL
+	SELECT t.id, tree.root_id, tree.depth + 1 FROM tenants t JOIN tree ON t.parent_tenant_id = tree.id
+),
+fanout (root_id, children) AS (
+	SELECT tree.root_id, COUNT(*) FROM tenants t JOIN tree ON t.parent_tenant_id = tree.id GROUP BY tree.root_id, tree.id
+)
+SELECT tree.root_id, MAX(tree.depth), COALESCE((SELECT MAX(children) FROM fanout WHERE fanout.root_id = tree.root_id), 0)
+FROM tree GROUP BY tree.root_id`
+
+var (
+	tenantCount = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tenants",
+		Help:      "Total number of tenants.",
+	})
+
+	tenantMaxDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tenant_max_depth",
+		Help:      "Depth of the deepest tenant in the hierarchy, root tenants have a depth of one.",
+	})
+
+	rootDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "root_tenant_depth",
+		Help:      "Depth of the deepest tenant under each root tenant, only recorded when tenant limits are set.",
+	}, []string{"root_id"})
+
+	rootMaxChildren = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "root_tenant_max_children",
+		Help:      "Most children of a tenant under each root tenant, only recorded when tenant limits are set.",
+	}, []string{"root_id"})
+
+	rootLimitUsage = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "root_tenant_limit_usage_ratio",
+		Help:      "Ratio of each limit of the tenant hierarchy used under each root tenant by limit, depth or children.",
+	}, []string{"root_id", "limit"})
+)
+
+// Querier runs a SQL query, it's implemented by the ent client.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// RootLimits returns the depth and children limits of the tree under a root
+// tenant, 0 doesn't limit it.
+type RootLimits func(root gidx.PrefixedID) (maxDepth, maxChildren int)
+
+// TenantStatsOption configures a TenantStats.
+type TenantStatsOption func(s *TenantStats)
+
+// WithRootLimits records how close the tree under each root tenant is to its
+// limits.
+func WithRootLimits(limits RootLimits) TenantStatsOption {
+	return func(s *TenantStats) {
+		s.limits = limits
+	}
+}
+
+// TenantStats periodically records the total number of tenants and the
+// maximum depth of the tenant hierarchy.
+type TenantStats struct {
+	db       Querier
+	interval time.Duration
+	logger   *zap.SugaredLogger
+	limits   RootLimits
+}
+
+// NewTenantStats returns a TenantStats which refreshes at the given interval.
+func NewTenantStats(db Querier, interval time.Duration, logger *zap.SugaredLogger, opts ...TenantStatsOption) *TenantStats {
+	s := &TenantStats{
+		db:       db,
+		interval: interval,
+		logger:   logger,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Run refreshes the tenant gauges until the context is canceled.
+func (s *TenantStats) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(ctx); err != nil {
+			s.logger.Warnw("failed to refresh tenant metrics", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh queries the database and updates the tenant gauges.
+func (s *TenantStats) Refresh(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, tenantStatsQuery)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	var count, depth int64
+
+	for rows.Next() {
+		if err := rows.Scan(&count, &depth); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tenantCount.Set(float64(count))
+	tenantMaxDepth.Set(float64(depth))
+
+	if s.limits == nil {
+		return nil
+	}
+
+	return s.refreshRoots(ctx)
+}
+
+// refreshRoots queries the database and updates the gauges of each root
+// tenant, the gauges of deleted roots are removed.
+func (s *TenantStats) refreshRoots(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, rootStatsQuery)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	rootDepth.Reset()
+	rootMaxChildren.Reset()
+	rootLimitUsage.Reset()
+
+	for rows.Next() {
+		var (
+			root            gidx.PrefixedID
+			depth, children int64
+		)
+
+		if err := rows.Scan(&root, &depth, &children); err != nil {
+			return err
+		}
+
+		rootDepth.WithLabelValues(root.String()).Set(float64(depth))
+		rootMaxChildren.WithLabelValues(root.String()).Set(float64(children))
+
+		maxDepth, maxChildren := s.limits(root)
+
+		if maxDepth > 0 {
+			rootLimitUsage.WithLabelValues(root.String(), "depth").Set(float64(depth) / float64(maxDepth))
+		}
+
+		if maxChildren > 0 {
+			rootLimitUsage.WithLabelValues(root.String(), "children").Set(float64(children) / float64(maxChildren))
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,100 @@
+package pubsub
+
+import (
+	"time"
+
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+)
+
+const (
+	// CloudEventsSpecVersion is the version of the CloudEvents spec published
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventsContentType is the content type of a structured CloudEvent,
+	// it's set in the Content-Type header of published messages
+	CloudEventsContentType = "application/cloudevents+json"
+	// CloudEventsTypePrefix prefixes the type of published CloudEvents, which
+	// is followed by the subject type and the change, com.infratographer.tenant.updated
+	CloudEventsTypePrefix = "com.infratographer."
+	// CloudEventsDataSchema identifies the version of CloudEventData, it
+	// changes when the data changes in a way that isn't backwards compatible
+	CloudEventsDataSchema = "urn:infratographer:tenant-api:change:v1"
+)
+
+// cloudEventsChangeTypes maps change types to the verb used in CloudEvent types
+var cloudEventsChangeTypes = map[string]string{
+	string(events.CreateChangeType): "created",
+	string(events.UpdateChangeType): "updated",
+	string(events.DeleteChangeType): "deleted",
+}
+
+// CloudEvent is a change encoded as a structured CloudEvent
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	DataSchema      string    `json:"dataschema"`
+	// TraceParent and TraceState are the distributed tracing extension
+	TraceParent string `json:"traceparent,omitempty"`
+	TraceState  string `json:"tracestate,omitempty"`
+	// Sequence is the sequence extension, the subject's event sequence number
+	Sequence string `json:"sequence,omitempty"`
+
+	Data CloudEventData `json:"data"`
+}
+
+// CloudEventData is the data of a CloudEvent, the parts of the change which
+// don't map to CloudEvent attributes
+type CloudEventData struct {
+	ActorID              gidx.PrefixedID      `json:"actorID"`
+	AdditionalSubjectIDs []gidx.PrefixedID    `json:"additionalSubjectIDs"`
+	SubjectFields        map[string]string    `json:"subjectFields,omitempty"`
+	FieldChanges         []events.FieldChange `json:"fieldChanges"`
+}
+
+// NewCloudEvent returns the change as a CloudEvent from the source
+func NewCloudEvent(id, source, subjectType string, change events.ChangeMessage) CloudEvent {
+	verb, ok := cloudEventsChangeTypes[change.EventType]
+	if !ok {
+		verb = change.EventType
+	}
+
+	ts := change.Timestamp
+	if ts.IsZero() {
+		ts = time.Now().UTC()
+	}
+
+	additional := change.AdditionalSubjectIDs
+	if additional == nil {
+		additional = []gidx.PrefixedID{}
+	}
+
+	fieldChanges := change.FieldChanges
+	if fieldChanges == nil {
+		fieldChanges = []events.FieldChange{}
+	}
+
+	return CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              id,
+		Source:          source,
+		Type:            CloudEventsTypePrefix + subjectType + "." + verb,
+		Subject:         change.SubjectID.String(),
+		Time:            ts,
+		DataContentType: "application/json",
+		DataSchema:      CloudEventsDataSchema,
+		TraceParent:     change.TraceContext["traceparent"],
+		TraceState:      change.TraceContext["tracestate"],
+		Sequence:        change.SubjectFields[SequenceField],
+		Data: CloudEventData{
+			ActorID:              change.ActorID,
+			AdditionalSubjectIDs: additional,
+			SubjectFields:        change.SubjectFields,
+			FieldChanges:         fieldChanges,
+		},
+	}
+}
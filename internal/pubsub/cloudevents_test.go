@@ -0,0 +1,152 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/events"
+	"go.uber.org/zap"
+)
+
+func TestCloudEventsPublisher(t *testing.T) {
+	ctx := context.Background()
+
+	ps := gochannel.NewGoChannel(gochannel.Config{Persistent: true}, watermill.NopLogger{})
+
+	p := newNATSPublisher(ps, "com.example", "tenant-api", EncodingCloudEvents)
+
+	defer p.Close()
+
+	msgs, err := ps.Subscribe(ctx, "com.example.changes.update.tenant")
+	require.NoError(t, err)
+
+	ts := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	err = p.PublishChange(context.WithValue(ctx, echojwtx.ActorCtxKey, "idntusr-actor"), "tenant", events.ChangeMessage{
+		SubjectID: "tnntten-child",
+		EventType: string(events.UpdateChangeType),
+		Timestamp: ts,
+		SubjectFields: map[string]string{
+			SequenceField: "2",
+		},
+		FieldChanges: []events.FieldChange{
+			{Field: "name", PreviousValue: "old", CurrentValue: "new"},
+		},
+	})
+	require.NoError(t, err)
+
+	msg := <-msgs
+	msg.Ack()
+
+	assert.Equal(t, CloudEventsContentType, msg.Metadata.Get("Content-Type"))
+
+	var ce map[string]interface{}
+
+	require.NoError(t, json.Unmarshal(msg.Payload, &ce))
+
+	assert.Equal(t, map[string]interface{}{
+		"specversion":     "1.0",
+		"id":              msg.UUID,
+		"source":          "tenant-api",
+		"type":            "com.infratographer.tenant.updated",
+		"subject":         "tnntten-child",
+		"time":            "2023-06-01T12:00:00Z",
+		"datacontenttype": "application/json",
+		"dataschema":      CloudEventsDataSchema,
+		"sequence":        "2",
+		"data": map[string]interface{}{
+			"actorID":              "idntusr-actor",
+			"additionalSubjectIDs": []interface{}{},
+			"subjectFields":        map[string]interface{}{SequenceField: "2"},
+			"fieldChanges": []interface{}{
+				map[string]interface{}{"field": "name", "previousValue": "old", "currentValue": "new"},
+			},
+		},
+	}, ce)
+}
+
+func TestCloudEventsPublisherMissingEventType(t *testing.T) {
+	ps := gochannel.NewGoChannel(gochannel.Config{}, watermill.NopLogger{})
+
+	p := newNATSPublisher(ps, "com.example", "tenant-api", EncodingCloudEvents)
+
+	defer p.Close()
+
+	err := p.PublishChange(context.Background(), "tenant", events.ChangeMessage{SubjectID: "tnntten-child"})
+	assert.ErrorIs(t, err, events.ErrMissingEventType)
+}
+
+func TestNewCloudEventTypes(t *testing.T) {
+	tests := []struct {
+		eventType string
+		want      string
+	}{
+		{eventType: "create", want: "com.infratographer.tenant.created"},
+		{eventType: "update", want: "com.infratographer.tenant.updated"},
+		{eventType: "delete", want: "com.infratographer.tenant.deleted"},
+		{eventType: "moved", want: "com.infratographer.tenant.moved"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.eventType, func(t *testing.T) {
+			ce := NewCloudEvent("id", "tenant-api", "tenant", events.ChangeMessage{SubjectID: "tnntten-a", EventType: tt.eventType})
+			assert.Equal(t, tt.want, ce.Type)
+		})
+	}
+}
+
+func TestPublisherConfig(t *testing.T) {
+	v := viper.New()
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	events.MustViperFlagsForPublisher(v, flags, "tenant-api")
+	MustViperFlags(v, flags)
+
+	require.NoError(t, flags.Parse([]string{"--events-publisher-url", "nats://example:4222", "--events-publisher-encoding", "cloudevents"}))
+
+	var cfg struct {
+		Events struct {
+			Publisher PublisherConfig
+		}
+	}
+
+	require.NoError(t, v.Unmarshal(&cfg))
+
+	assert.Equal(t, "nats://example:4222", cfg.Events.Publisher.URL)
+	assert.Equal(t, "tenant-api", cfg.Events.Publisher.Source)
+	assert.Equal(t, EncodingCloudEvents, cfg.Events.Publisher.Encoding)
+}
+
+func TestValidatePrimary(t *testing.T) {
+	cloudEvents := PublisherConfig{Encoding: EncodingCloudEvents}
+
+	assert.NoError(t, ValidatePrimary(cloudEvents, false))
+	assert.NoError(t, ValidatePrimary(PublisherConfig{}, true))
+	assert.NoError(t, ValidatePrimary(PublisherConfig{Encoding: EncodingChangeMessage}, true))
+
+	err := ValidatePrimary(cloudEvents, true)
+	assert.ErrorIs(t, err, ErrUnreadableEncoding)
+	assert.ErrorContains(t, err, "additional publisher")
+}
+
+func TestPublishesChangeMessages(t *testing.T) {
+	assert.True(t, PublisherConfig{}.PublishesChangeMessages())
+	assert.True(t, PublisherConfig{Encoding: EncodingChangeMessage}.PublishesChangeMessages())
+	assert.False(t, PublisherConfig{Encoding: EncodingCloudEvents}.PublishesChangeMessages())
+}
+
+func TestNewPublisherUnknownEncoding(t *testing.T) {
+	_, err := NewPublisher(PublisherConfig{Encoding: "avro"}, zap.NewNop().Sugar())
+	assert.ErrorIs(t, err, ErrUnknownEncoding)
+}
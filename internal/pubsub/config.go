@@ -0,0 +1,90 @@
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/viperx"
+	"go.uber.org/zap"
+)
+
+// Encoding is how a publisher encodes the changes it publishes
+type Encoding string
+
+const (
+	// EncodingChangeMessage publishes events.ChangeMessage, which is what
+	// infratographer services, including tenant-api's own subscribers, expect
+	EncodingChangeMessage Encoding = "change"
+	// EncodingCloudEvents publishes structured CloudEvents 1.0, for consumers
+	// outside of the infratographer ecosystem. The primary publisher can only
+	// use it when tenant-api doesn't subscribe to its changes.
+	EncodingCloudEvents Encoding = "cloudevents"
+)
+
+var (
+	// ErrUnknownEncoding is returned when a publisher is configured with an
+	// encoding that isn't supported
+	ErrUnknownEncoding = errors.New("unknown event encoding")
+	// ErrUnreadableEncoding is returned when the primary publisher's changes
+	// can't be read by tenant-api's own subscribers
+	ErrUnreadableEncoding = errors.New("tenant-api's subscribers can't read the events publisher's encoding")
+)
+
+// PublisherConfig stores the configuration for a change publisher
+type PublisherConfig struct {
+	events.PublisherConfig `mapstructure:",squash"`
+
+	// Encoding is how changes are encoded, defaults to EncodingChangeMessage
+	Encoding Encoding `mapstructure:"encoding"`
+	// Name identifies the publisher's backend in logs and metrics when changes
+	// are fanned out to several backends
+	Name string `mapstructure:"name"`
+	// Policy is whether changes have to be published to the backend, defaults
+	// to PolicyRequired
+	Policy Policy `mapstructure:"policy"`
+}
+
+// PublishesChangeMessages returns whether the publisher's changes are encoded
+// as change messages, which tenant-api's own subscribers can read
+func (c PublisherConfig) PublishesChangeMessages() bool {
+	return c.Encoding == "" || c.Encoding == EncodingChangeMessage
+}
+
+// ValidatePrimary returns an error when the primary publisher's changes are
+// subscribed to by tenant-api itself, for cache invalidation or webhooks, and
+// aren't encoded as change messages. CloudEvents can be published with an
+// additional publisher instead.
+func ValidatePrimary(primary PublisherConfig, subscribed bool) error {
+	if subscribed && !primary.PublishesChangeMessages() {
+		return fmt.Errorf("%w: %q, the events subscriber expects %q, publish %q events with an additional publisher",
+			ErrUnreadableEncoding, primary.Encoding, EncodingChangeMessage, primary.Encoding)
+	}
+
+	return nil
+}
+
+// MustViperFlags returns the cobra flags and viper config for the change
+// publisher, in addition to events.MustViperFlagsForPublisher
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.String("events-publisher-encoding", string(EncodingChangeMessage), "encoding of published events: change or cloudevents")
+	viperx.MustBindFlag(v, "events.publisher.encoding", flags.Lookup("events-publisher-encoding"))
+}
+
+// NewPublisher returns a publisher which publishes changes with the
+// configured encoding, a file publisher for stdout:// and file:// URLs and a
+// NATS publisher otherwise
+func NewPublisher(cfg PublisherConfig, logger *zap.SugaredLogger) (Publisher, error) {
+	if IsFileURL(cfg.URL) {
+		return NewFilePublisher(cfg)
+	}
+
+	p, err := NewNATSPublisher(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
@@ -0,0 +1,153 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.infratographer.com/x/events"
+	"go.uber.org/zap"
+)
+
+// Policy is whether a change has to be published to a backend
+type Policy string
+
+const (
+	// PolicyRequired returns an error to the caller which made the change
+	// when it can't be published to the backend. Changes are published once
+	// they're committed, so the change isn't undone.
+	PolicyRequired Policy = "required"
+	// PolicyBestEffort logs and counts failures to publish to the backend,
+	// without failing the change
+	PolicyBestEffort Policy = "best_effort"
+)
+
+var (
+	// ErrUnknownPolicy is returned when a backend is configured with a
+	// policy that isn't supported
+	ErrUnknownPolicy = errors.New("unknown publisher policy")
+	// ErrNoBackends is returned when a fan-out publisher has no backends
+	ErrNoBackends = errors.New("no publisher backends")
+	// ErrDuplicateBackend is returned when two backends have the same name
+	ErrDuplicateBackend = errors.New("duplicate publisher backend name")
+)
+
+// Backend is a publisher changes are fanned out to
+type Backend struct {
+	// Name identifies the backend in errors, logs and metrics
+	Name      string
+	Policy    Policy
+	Publisher Publisher
+}
+
+// FanoutPublisher publishes each change to every backend, so changes can be
+// published to several message buses at once, such as while migrating from
+// one to another
+type FanoutPublisher struct {
+	backends []Backend
+	logger   *zap.SugaredLogger
+}
+
+var _ Publisher = (*FanoutPublisher)(nil)
+
+// NewFanoutPublisher returns a publisher fanning changes out to the backends,
+// a backend without a policy is required
+func NewFanoutPublisher(backends []Backend, logger *zap.SugaredLogger) (*FanoutPublisher, error) {
+	if len(backends) == 0 {
+		return nil, ErrNoBackends
+	}
+
+	names := make(map[string]bool, len(backends))
+
+	// copied so the caller's backends aren't modified
+	backends = append([]Backend(nil), backends...)
+
+	for i, b := range backends {
+		switch b.Policy {
+		case "":
+			backends[i].Policy = PolicyRequired
+		case PolicyRequired, PolicyBestEffort:
+		default:
+			return nil, fmt.Errorf("%w: %q for backend %s", ErrUnknownPolicy, b.Policy, b.Name)
+		}
+
+		if names[b.Name] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateBackend, b.Name)
+		}
+
+		names[b.Name] = true
+	}
+
+	return &FanoutPublisher{
+		backends: backends,
+		logger:   logger,
+	}, nil
+}
+
+// PublishChange publishes the change to every backend at once, and returns
+// the errors of the required backends which failed
+func (p *FanoutPublisher) PublishChange(ctx context.Context, subjectType string, change events.ChangeMessage) error {
+	errs := make([]error, len(p.backends))
+
+	var wg sync.WaitGroup
+
+	for i, b := range p.backends {
+		wg.Add(1)
+
+		go func(i int, b Backend) {
+			defer wg.Done()
+
+			errs[i] = b.Publisher.PublishChange(ctx, subjectType, change)
+		}(i, b)
+	}
+
+	wg.Wait()
+
+	var required []error
+
+	for i, b := range p.backends {
+		err := errs[i]
+		if err == nil {
+			continue
+		}
+
+		if b.Policy == PolicyBestEffort {
+			p.logger.Warnw("failed to publish change to best effort backend",
+				"error", err,
+				"backend", b.Name,
+				"subject_type", subjectType,
+				"subject_id", change.SubjectID,
+				"event_type", change.EventType,
+			)
+
+			continue
+		}
+
+		required = append(required, fmt.Errorf("publishing to %s: %w", b.Name, err))
+	}
+
+	return errors.Join(required...)
+}
+
+// PublisherConfigs returns the configs of every backend, the primary publisher
+// followed by the additional ones. Backends are named by their position when
+// they don't have a name, and use the primary's source when they don't have
+// one.
+func PublisherConfigs(primary PublisherConfig, additional []PublisherConfig) []PublisherConfig {
+	cfgs := make([]PublisherConfig, 0, len(additional)+1)
+	cfgs = append(cfgs, primary)
+	cfgs = append(cfgs, additional...)
+
+	for i := range cfgs {
+		if cfgs[i].Name == "" {
+			cfgs[i].Name = fmt.Sprintf("publisher-%d", i)
+		}
+
+		if cfgs[i].Source == "" {
+			cfgs[i].Source = primary.Source
+		}
+	}
+
+	return cfgs
+}
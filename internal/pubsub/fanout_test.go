@@ -0,0 +1,125 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.uber.org/zap"
+)
+
+var errTest = errors.New("test error")
+
+type fakePublisher struct {
+	published atomic.Int32
+	err       error
+}
+
+func (p *fakePublisher) PublishChange(_ context.Context, _ string, _ events.ChangeMessage) error {
+	p.published.Add(1)
+
+	return p.err
+}
+
+func TestFanoutPublisher(t *testing.T) {
+	ctx := context.Background()
+	change := events.ChangeMessage{SubjectID: "tnntten-fanout", EventType: string(events.CreateChangeType)}
+
+	tests := []struct {
+		name     string
+		backends map[string]*fakePublisher
+		policies map[string]Policy
+		errs     []string
+	}{
+		{
+			name:     "all published",
+			backends: map[string]*fakePublisher{"old": {}, "new": {}},
+		},
+		{
+			name:     "required failure",
+			backends: map[string]*fakePublisher{"old": {err: errTest}, "new": {}},
+			errs:     []string{"publishing to old: test error"},
+		},
+		{
+			name:     "best effort failure",
+			backends: map[string]*fakePublisher{"old": {}, "new": {err: errTest}},
+			policies: map[string]Policy{"new": PolicyBestEffort},
+		},
+		{
+			name:     "required and best effort failures",
+			backends: map[string]*fakePublisher{"old": {err: errTest}, "new": {err: errTest}},
+			policies: map[string]Policy{"old": PolicyRequired, "new": PolicyBestEffort},
+			errs:     []string{"publishing to old: test error"},
+		},
+		{
+			name:     "every required failure",
+			backends: map[string]*fakePublisher{"old": {err: errTest}, "new": {err: errTest}},
+			errs:     []string{"publishing to old: test error", "publishing to new: test error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var backends []Backend
+
+			for _, name := range []string{"old", "new"} {
+				backends = append(backends, Backend{Name: name, Policy: tt.policies[name], Publisher: tt.backends[name]})
+			}
+
+			p, err := NewFanoutPublisher(backends, zap.NewNop().Sugar())
+			require.NoError(t, err)
+
+			err = p.PublishChange(ctx, "tenant", change)
+
+			if len(tt.errs) == 0 {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, errTest)
+
+				for _, msg := range tt.errs {
+					assert.ErrorContains(t, err, msg)
+				}
+			}
+
+			for name, b := range tt.backends {
+				assert.Equal(t, int32(1), b.published.Load(), "change is published to %s", name)
+			}
+		})
+	}
+}
+
+func TestNewFanoutPublisherErrors(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+
+	_, err := NewFanoutPublisher(nil, logger)
+	assert.ErrorIs(t, err, ErrNoBackends)
+
+	_, err = NewFanoutPublisher([]Backend{{Name: "old", Policy: "sometimes", Publisher: &fakePublisher{}}}, logger)
+	assert.ErrorIs(t, err, ErrUnknownPolicy)
+
+	_, err = NewFanoutPublisher([]Backend{{Name: "old", Publisher: &fakePublisher{}}, {Name: "old", Publisher: &fakePublisher{}}}, logger)
+	assert.ErrorIs(t, err, ErrDuplicateBackend)
+}
+
+func TestPublisherConfigs(t *testing.T) {
+	primary := PublisherConfig{}
+	primary.Source = "tenant-api"
+
+	named := PublisherConfig{Name: "new", Policy: PolicyBestEffort}
+	named.Source = "tenant-api-new"
+
+	cfgs := PublisherConfigs(primary, []PublisherConfig{named, {}})
+	require.Len(t, cfgs, 3)
+
+	assert.Equal(t, "publisher-0", cfgs[0].Name)
+	assert.Equal(t, "new", cfgs[1].Name)
+	assert.Equal(t, "publisher-2", cfgs[2].Name)
+
+	assert.Equal(t, "tenant-api", cfgs[0].Source)
+	assert.Equal(t, "tenant-api-new", cfgs[1].Source)
+	assert.Equal(t, "tenant-api", cfgs[2].Source, "source defaults to the primary's")
+}
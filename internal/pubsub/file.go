@@ -0,0 +1,176 @@
+package pubsub
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.infratographer.com/x/events"
+)
+
+const (
+	// StdoutURL is the publisher URL which writes changes to stdout
+	StdoutURL = "stdout://"
+	// FileScheme is the scheme of publisher URLs which write changes to a
+	// file, file://changes.jsonl or file:///var/log/changes.jsonl
+	FileScheme = "file://"
+)
+
+// fileMode is the mode of files created by file publishers, they're readable
+// by others so changes can be tailed by another user
+const fileMode os.FileMode = 0o644
+
+// tailInterval is how often ReadChanges checks for changes appended to the
+// file it's following
+var tailInterval = 250 * time.Millisecond
+
+// IsFileURL reports whether the publisher URL is for a file publisher
+func IsFileURL(url string) bool {
+	return url == StdoutURL || strings.HasPrefix(url, FileScheme)
+}
+
+// FilePublisher writes each change as a line of JSON to stdout or a file, so
+// tenant-api can run without a message bus in development
+type FilePublisher struct {
+	source string
+
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+var _ Publisher = (*FilePublisher)(nil)
+
+// NewFilePublisher returns a publisher writing changes to stdout for
+// StdoutURL, or appending them to the file for file:// URLs
+func NewFilePublisher(cfg PublisherConfig) (*FilePublisher, error) {
+	// changes are written as is, which is what tenant events tail reads
+	if cfg.Encoding != "" && cfg.Encoding != EncodingChangeMessage {
+		return nil, fmt.Errorf("%w: %q isn't supported by file publishers", ErrUnknownEncoding, cfg.Encoding)
+	}
+
+	if cfg.URL == StdoutURL {
+		return newFilePublisher(os.Stdout, nil, cfg.Source), nil
+	}
+
+	path := strings.TrimPrefix(cfg.URL, FileScheme)
+	if path == cfg.URL || path == "" {
+		return nil, fmt.Errorf("%w: %q", events.ErrUnsupportedPubsub, cfg.URL)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, fileMode)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFilePublisher(f, f, cfg.Source), nil
+}
+
+func newFilePublisher(w io.Writer, closer io.Closer, source string) *FilePublisher {
+	return &FilePublisher{
+		source: source,
+		w:      w,
+		closer: closer,
+	}
+}
+
+// PublishChange writes the change as a line of JSON
+func (p *FilePublisher) PublishChange(ctx context.Context, _ string, change events.ChangeMessage) error {
+	if change.EventType == "" {
+		return events.ErrMissingEventType
+	}
+
+	change = prepareChange(ctx, p.source, change)
+
+	if change.Timestamp.IsZero() {
+		change.Timestamp = time.Now().UTC()
+	}
+
+	v, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err = p.w.Write(append(v, '\n'))
+
+	return err
+}
+
+// Close closes the file changes are written to
+func (p *FilePublisher) Close() error {
+	if p.closer == nil {
+		return nil
+	}
+
+	return p.closer.Close()
+}
+
+// ReadChanges reads the changes written by a file publisher from r, calling fn
+// with each change and the line it was read from. When follow is set, it keeps
+// reading changes appended to r until the context is done.
+func ReadChanges(ctx context.Context, r io.Reader, follow bool, fn func(line []byte, change events.ChangeMessage) error) error {
+	br := bufio.NewReader(r)
+
+	var partial []byte
+
+	for {
+		line, err := br.ReadBytes('\n')
+
+		switch {
+		case err == io.EOF:
+			// the rest of the line may not have been written yet
+			partial = append(partial, line...)
+
+			if !follow {
+				if len(bytes.TrimSpace(partial)) == 0 {
+					return nil
+				}
+
+				return readChange(partial, fn)
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(tailInterval):
+			}
+
+			continue
+		case err != nil:
+			return err
+		}
+
+		line = append(partial, line...)
+		partial = nil
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		if err := readChange(line, fn); err != nil {
+			return err
+		}
+	}
+}
+
+func readChange(line []byte, fn func(line []byte, change events.ChangeMessage) error) error {
+	line = bytes.TrimSpace(line)
+
+	var change events.ChangeMessage
+
+	if err := json.Unmarshal(line, &change); err != nil {
+		return fmt.Errorf("reading change: %w", err)
+	}
+
+	return fn(line, change)
+}
@@ -0,0 +1,171 @@
+package pubsub
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+)
+
+func readAll(t *testing.T, r *bytes.Reader) []events.ChangeMessage {
+	t.Helper()
+
+	var changes []events.ChangeMessage
+
+	err := ReadChanges(context.Background(), r, false, func(_ []byte, change events.ChangeMessage) error {
+		changes = append(changes, change)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	return changes
+}
+
+func TestFilePublisher(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "changes.jsonl")
+
+	cfg := PublisherConfig{}
+	cfg.URL = FileScheme + path
+	cfg.Source = "tenant-api"
+
+	p, err := NewFilePublisher(cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, p.PublishChange(ctx, "tenant", events.ChangeMessage{SubjectID: "tnntten-one", EventType: string(events.CreateChangeType)}))
+	require.NoError(t, p.PublishChange(ctx, "tenant", events.ChangeMessage{SubjectID: "tnntten-two", EventType: string(events.UpdateChangeType)}))
+	assert.ErrorIs(t, p.PublishChange(ctx, "tenant", events.ChangeMessage{SubjectID: "tnntten-three"}), events.ErrMissingEventType)
+	require.NoError(t, p.Close())
+
+	// changes are appended to the file
+	p, err = NewFilePublisher(cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, p.PublishChange(ctx, "tenant", events.ChangeMessage{SubjectID: "tnntten-one", EventType: string(events.DeleteChangeType)}))
+	require.NoError(t, p.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, strings.Count(string(b), "\n"), "each change is a line")
+
+	changes := readAll(t, bytes.NewReader(b))
+	require.Len(t, changes, 3)
+
+	assert.Equal(t, gidx.PrefixedID("tnntten-one"), changes[0].SubjectID)
+	assert.Equal(t, string(events.CreateChangeType), changes[0].EventType)
+	assert.Equal(t, "tenant-api", changes[0].Source)
+	assert.Equal(t, gidx.PrefixedID("unknown-actor"), changes[0].ActorID)
+	assert.False(t, changes[0].Timestamp.IsZero())
+	assert.Equal(t, gidx.PrefixedID("tnntten-two"), changes[1].SubjectID)
+	assert.Equal(t, string(events.DeleteChangeType), changes[2].EventType)
+}
+
+func TestNewFilePublisherErrors(t *testing.T) {
+	cfg := PublisherConfig{Encoding: EncodingCloudEvents}
+	cfg.URL = StdoutURL
+
+	_, err := NewFilePublisher(cfg)
+	assert.ErrorIs(t, err, ErrUnknownEncoding)
+
+	cfg = PublisherConfig{}
+	cfg.URL = FileScheme
+
+	_, err = NewFilePublisher(cfg)
+	assert.ErrorIs(t, err, events.ErrUnsupportedPubsub)
+
+	cfg.URL = FileScheme + filepath.Join(t.TempDir(), "missing", "changes.jsonl")
+
+	_, err = NewFilePublisher(cfg)
+	assert.Error(t, err)
+}
+
+func TestNewPublisherFileURL(t *testing.T) {
+	cfg := PublisherConfig{}
+	cfg.URL = StdoutURL
+
+	p, err := NewPublisher(cfg, nil)
+	require.NoError(t, err)
+
+	assert.IsType(t, &FilePublisher{}, p)
+	assert.True(t, IsFileURL("file://changes.jsonl"))
+	assert.False(t, IsFileURL("nats://nats:4222"))
+}
+
+func TestReadChanges(t *testing.T) {
+	lines := `{"subjectID":"tnntten-one","eventType":"create"}
+
+{"subjectID":"tnntten-two","eventType":"update"}`
+
+	changes := readAll(t, bytes.NewReader([]byte(lines)))
+	require.Len(t, changes, 2, "blank lines are skipped and the last line needn't end in a newline")
+
+	assert.Equal(t, gidx.PrefixedID("tnntten-two"), changes[1].SubjectID)
+
+	err := ReadChanges(context.Background(), strings.NewReader("not json\n"), false, func([]byte, events.ChangeMessage) error { return nil })
+	assert.Error(t, err)
+}
+
+func TestReadChangesFollow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(t.TempDir(), "changes.jsonl")
+
+	cfg := PublisherConfig{}
+	cfg.URL = FileScheme + path
+
+	p, err := NewFilePublisher(cfg)
+	require.NoError(t, err)
+
+	defer p.Close()
+
+	require.NoError(t, p.PublishChange(ctx, "tenant", events.ChangeMessage{SubjectID: "tnntten-one", EventType: string(events.CreateChangeType)}))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	read := make(chan events.ChangeMessage)
+	done := make(chan error)
+
+	go func() {
+		done <- ReadChanges(ctx, f, true, func(_ []byte, change events.ChangeMessage) error {
+			read <- change
+
+			return nil
+		})
+	}()
+
+	next := func() events.ChangeMessage {
+		select {
+		case change := <-read:
+			return change
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for change")
+		}
+
+		return events.ChangeMessage{}
+	}
+
+	assert.Equal(t, gidx.PrefixedID("tnntten-one"), next().SubjectID)
+
+	// changes written after reading starts are followed
+	require.NoError(t, p.PublishChange(ctx, "tenant", events.ChangeMessage{SubjectID: "tnntten-two", EventType: string(events.CreateChangeType)}))
+
+	assert.Equal(t, gidx.PrefixedID("tnntten-two"), next().SubjectID)
+
+	cancel()
+
+	assert.NoError(t, <-done)
+}
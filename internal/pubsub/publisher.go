@@ -0,0 +1,248 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill-nats/v2/pkg/nats"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/garsue/watermillzap"
+	"github.com/google/uuid"
+	nc "github.com/nats-io/nats.go"
+	"go.infratographer.com/x/echojwtx"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	instrumentationName = "go.infratographer.com/tenant-api/internal/pubsub"
+
+	// SequenceField is the subject field holding the subject's event sequence
+	// number, which starts at 1 and is incremented by every change
+	SequenceField = "event_sequence"
+)
+
+// messageIDNamespace is the namespace of the name based UUIDs used as the IDs
+// of sequenced messages
+var messageIDNamespace = uuid.MustParse("5c1a0f4e-6a52-4c3b-9d0e-2f7d8b1e4a63")
+
+// MessageID returns the ID of the message the change is published in. A change
+// with a sequence number always gets the same ID, so JetStream drops it when
+// it's published again within the stream's duplicate window. Other changes get
+// a random ID.
+func MessageID(subjectType string, change events.ChangeMessage) string {
+	seq, ok := change.SubjectFields[SequenceField]
+	if !ok {
+		return watermill.NewUUID()
+	}
+
+	name := strings.Join([]string{subjectType, change.SubjectID.String(), change.EventType, seq}, "/")
+
+	return uuid.NewSHA1(messageIDNamespace, []byte(name)).String()
+}
+
+// NATSPublisher publishes changes to NATS with the configured encoding, to the
+// same topics as events.Publisher. Unlike events.Publisher, a message's ID is
+// also sent as its NATS message ID, which JetStream deduplicates by.
+type NATSPublisher struct {
+	prefix    string
+	source    string
+	encoding  Encoding
+	publisher message.Publisher
+	tracer    trace.Tracer
+}
+
+var _ Publisher = (*NATSPublisher)(nil)
+
+// NewNATSPublisher returns a NATS publisher for the config
+func NewNATSPublisher(cfg PublisherConfig, logger *zap.SugaredLogger) (*NATSPublisher, error) {
+	var marshaler nats.Marshaler
+
+	switch cfg.Encoding {
+	case "", EncodingChangeMessage:
+		// the JSON marshaler wraps the change in a watermill message, which
+		// is what events.Subscriber expects
+		marshaler = nats.JSONMarshaler{}
+	case EncodingCloudEvents:
+		// the NATS marshaler sends the payload as is, so consumers receive the
+		// CloudEvent without knowing about watermill
+		marshaler = &nats.NATSMarshaler{}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEncoding, cfg.Encoding)
+	}
+
+	if !strings.HasPrefix(cfg.URL, "nats://") {
+		return nil, events.ErrUnsupportedPubsub
+	}
+
+	options := []nc.Option{
+		nc.Timeout(cfg.Timeout),
+	}
+
+	switch {
+	case cfg.NATSConfig.CredsFile != "":
+		options = append(options, nc.UserCredentials(cfg.NATSConfig.CredsFile))
+	case cfg.NATSConfig.Token != "":
+		options = append(options, nc.Token(cfg.NATSConfig.Token))
+	}
+
+	np, err := nats.NewPublisher(
+		nats.PublisherConfig{
+			URL:         cfg.URL,
+			NatsOptions: options,
+			Marshaler:   msgIDMarshaler{Marshaler: marshaler},
+		},
+		watermillzap.NewLogger(logger.Desugar()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newNATSPublisher(np, cfg.Prefix, cfg.Source, cfg.Encoding), nil
+}
+
+func newNATSPublisher(publisher message.Publisher, prefix, source string, encoding Encoding) *NATSPublisher {
+	if encoding == "" {
+		encoding = EncodingChangeMessage
+	}
+
+	return &NATSPublisher{
+		prefix:    prefix,
+		source:    source,
+		encoding:  encoding,
+		publisher: publisher,
+		tracer:    otel.GetTracerProvider().Tracer(instrumentationName),
+	}
+}
+
+// PublishChange publishes the change to the topic for the change
+func (p *NATSPublisher) PublishChange(ctx context.Context, subjectType string, change events.ChangeMessage) error {
+	ctx, span := p.tracer.Start(
+		ctx,
+		"events.publishChange",
+		trace.WithAttributes(
+			attribute.String("events.subject_type", subjectType),
+			attribute.String("events.subject_id", change.SubjectID.String()),
+			attribute.String("events.event_type", change.EventType),
+			attribute.String("events.source", p.source),
+			attribute.String("events.encoding", string(p.encoding)),
+		),
+	)
+
+	defer span.End()
+
+	if change.EventType == "" {
+		span.RecordError(events.ErrMissingEventType)
+		span.SetStatus(codes.Error, events.ErrMissingEventType.Error())
+
+		return events.ErrMissingEventType
+	}
+
+	change = prepareChange(ctx, p.source, change)
+
+	topic := strings.Join([]string{p.prefix, "changes", change.EventType, subjectType}, ".")
+	id := MessageID(subjectType, change)
+
+	span.SetAttributes(
+		attribute.String("events.topic", topic),
+		attribute.String("events.actor_id", change.ActorID.String()),
+		attribute.String("events.message_id", id),
+	)
+
+	msg, err := p.message(id, subjectType, change)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	if err := p.publisher.Publish(topic, msg); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	return nil
+}
+
+// prepareChange returns the change with the trace context, which propagates
+// the trace to subscribers, and its source and actor set
+func prepareChange(ctx context.Context, source string, change events.ChangeMessage) events.ChangeMessage {
+	var carrier propagation.MapCarrier = make(map[string]string)
+
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	change.TraceContext = carrier
+	change.Source = source
+
+	if change.ActorID == gidx.NullPrefixedID {
+		id, ok := ctx.Value(echojwtx.ActorCtxKey).(string)
+		if ok {
+			change.ActorID = gidx.PrefixedID(id)
+		} else {
+			change.ActorID = "unknown-actor"
+		}
+	}
+
+	return change
+}
+
+// message returns the change encoded in a message with the ID
+func (p *NATSPublisher) message(id, subjectType string, change events.ChangeMessage) (*message.Message, error) {
+	if p.encoding == EncodingCloudEvents {
+		v, err := json.Marshal(NewCloudEvent(id, p.source, subjectType, change))
+		if err != nil {
+			return nil, err
+		}
+
+		msg := message.NewMessage(id, v)
+		msg.Metadata.Set("Content-Type", CloudEventsContentType)
+
+		return msg, nil
+	}
+
+	v, err := json.Marshal(change)
+	if err != nil {
+		return nil, err
+	}
+
+	return message.NewMessage(id, v), nil
+}
+
+// Close closes the publisher
+func (p *NATSPublisher) Close() error {
+	return p.publisher.Close()
+}
+
+// msgIDMarshaler sets the NATS message ID of marshaled messages to their
+// watermill ID
+type msgIDMarshaler struct {
+	nats.Marshaler
+}
+
+// Marshal marshals the message and sets its NATS message ID
+func (m msgIDMarshaler) Marshal(topic string, msg *message.Message) (*nc.Msg, error) {
+	natsMsg, err := m.Marshaler.Marshal(topic, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if natsMsg.Header == nil {
+		natsMsg.Header = make(nc.Header)
+	}
+
+	natsMsg.Header.Set(nc.MsgIdHdr, msg.UUID)
+
+	return natsMsg, nil
+}
@@ -0,0 +1,99 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill-nats/v2/pkg/nats"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	nc "github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+)
+
+func TestNATSPublisherChangeMessage(t *testing.T) {
+	ctx := context.Background()
+
+	ps := gochannel.NewGoChannel(gochannel.Config{Persistent: true}, watermill.NopLogger{})
+
+	p := newNATSPublisher(ps, "com.example", "tenant-api", "")
+
+	defer p.Close()
+
+	msgs, err := ps.Subscribe(ctx, "com.example.changes.create.tenant")
+	require.NoError(t, err)
+
+	change := events.ChangeMessage{
+		SubjectID:     "tnntten-child",
+		EventType:     string(events.CreateChangeType),
+		SubjectFields: map[string]string{SequenceField: "1"},
+	}
+
+	require.NoError(t, p.PublishChange(ctx, "tenant", change))
+
+	msg := <-msgs
+	msg.Ack()
+
+	assert.Equal(t, MessageID("tenant", change), msg.UUID)
+
+	var published events.ChangeMessage
+
+	require.NoError(t, json.Unmarshal(msg.Payload, &published))
+
+	assert.Equal(t, change.SubjectID, published.SubjectID)
+	assert.Equal(t, "tenant-api", published.Source)
+	assert.Equal(t, gidx.PrefixedID("unknown-actor"), published.ActorID)
+	assert.Equal(t, change.SubjectFields, published.SubjectFields)
+}
+
+func TestMessageID(t *testing.T) {
+	change := events.ChangeMessage{
+		SubjectID:     "tnntten-child",
+		EventType:     string(events.UpdateChangeType),
+		SubjectFields: map[string]string{SequenceField: "2"},
+	}
+
+	id := MessageID("tenant", change)
+
+	assert.Equal(t, id, MessageID("tenant", change), "a republished change has the same ID")
+
+	next := change
+	next.SubjectFields = map[string]string{SequenceField: "3"}
+
+	assert.NotEqual(t, id, MessageID("tenant", next), "the next change has a different ID")
+
+	other := change
+	other.SubjectID = "tnntten-other"
+
+	assert.NotEqual(t, id, MessageID("tenant", other), "another subject's change has a different ID")
+
+	unsequenced := events.ChangeMessage{SubjectID: "tnntten-child", EventType: string(events.UpdateChangeType)}
+
+	assert.NotEqual(t, MessageID("tenant", unsequenced), MessageID("tenant", unsequenced), "unsequenced changes have random IDs")
+}
+
+func TestMsgIDMarshaler(t *testing.T) {
+	tests := []struct {
+		name      string
+		marshaler nats.Marshaler
+	}{
+		{name: "json", marshaler: nats.JSONMarshaler{}},
+		{name: "nats", marshaler: &nats.NATSMarshaler{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			natsMsg, err := msgIDMarshaler{Marshaler: tt.marshaler}.Marshal("topic", message.NewMessage("message-id", []byte("{}")))
+			require.NoError(t, err)
+
+			assert.Equal(t, "message-id", natsMsg.Header.Get(nc.MsgIdHdr))
+		})
+	}
+}
@@ -0,0 +1,18 @@
+// Package pubsub defines how tenant-api publishes change events.
+package pubsub
+
+import (
+	"context"
+
+	"go.infratographer.com/x/events"
+)
+
+// Publisher publishes change messages for tenant-api resources. It's
+// implemented by *NATSPublisher, *FilePublisher and *events.Publisher and
+// injected into the ent client, which allows the publisher to be wrapped with
+// additional behavior.
+type Publisher interface {
+	PublishChange(ctx context.Context, subjectType string, change events.ChangeMessage) error
+}
+
+var _ Publisher = (*events.Publisher)(nil)
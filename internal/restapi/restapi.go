@@ -0,0 +1,138 @@
+// Package restapi provides a versioned REST/JSON API for tenants alongside the GraphQL API.
+package restapi
+
+import (
+	_ "embed"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/tenant-api/internal/admission"
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/kinds"
+	"go.infratographer.com/tenant-api/internal/limits"
+	"go.infratographer.com/tenant-api/internal/tenants"
+	"go.infratographer.com/tenant-api/internal/validation"
+)
+
+const (
+	apiPath = "/api/v1"
+
+	defaultPageSize = 100
+	maxPageSize     = 1000
+
+	// CodeTenantHasChildren is the code of errors deleting a tenant which
+	// still has children
+	CodeTenantHasChildren = "TENANT_HAS_CHILDREN"
+	// CodeTenantLimitExceeded is the code of errors creating a tenant which
+	// would exceed the depth or children limit of its root tenant
+	CodeTenantLimitExceeded = "TENANT_LIMIT_EXCEEDED"
+)
+
+var (
+	// ErrTenantHasChildren is returned when deleting a tenant which still has children
+	ErrTenantHasChildren = tenants.ErrHasChildren
+	// ErrInvalidPageSize is returned when the requested page size is out of range
+	ErrInvalidPageSize = errors.New("first must be between 1 and 1000")
+	// ErrNameRequired is returned when creating a tenant without a name
+	ErrNameRequired = errors.New("name is required")
+)
+
+// ErrorResponse is the body of error responses, failures which share a status
+// are told apart by their code
+type ErrorResponse struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+}
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// Handler serves the REST API
+type Handler struct {
+	client     *ent.Client
+	middleware []echo.MiddlewareFunc
+}
+
+// NewHandler returns a REST API handler using the given ent client, the
+// middleware is applied to all tenant routes
+func NewHandler(client *ent.Client, middleware []echo.MiddlewareFunc) *Handler {
+	return &Handler{
+		client:     client,
+		middleware: middleware,
+	}
+}
+
+// Routes registers the REST API routes
+func (h *Handler) Routes(e *echo.Group) {
+	g := e.Group(apiPath)
+
+	// the document describes the API so it's served without authentication
+	g.GET("/openapi.json", h.openAPI)
+
+	g.Use(h.middleware...)
+
+	g.GET("/tenants", h.listTenants)
+	g.POST("/tenants", h.createTenant)
+	g.GET("/tenants/:id", h.getTenant)
+	g.PATCH("/tenants/:id", h.updateTenant)
+	g.DELETE("/tenants/:id", h.deleteTenant)
+	g.GET("/tenants/:id/children", h.listChildren)
+}
+
+func (h *Handler) openAPI(c echo.Context) error {
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, openAPIDocument)
+}
+
+// httpError converts errors returned by ent, the permissions checker and
+// admission hooks to the matching http status
+func httpError(err error) error {
+	switch {
+	case errors.Is(err, permissions.ErrPermissionDenied), errors.Is(err, admission.ErrDenied):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error()).SetInternal(err)
+	case errors.Is(err, admission.ErrUnavailable):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error()).SetInternal(err)
+	case ent.IsNotFound(err):
+		return echo.NewHTTPError(http.StatusNotFound, "tenant not found").SetInternal(err)
+	case ent.IsValidationError(err), ent.IsConstraintError(err), validation.IsFieldError(err), errors.Is(err, kinds.ErrKindNotAllowed):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	case errors.Is(err, ErrTenantHasChildren):
+		return codedError(http.StatusConflict, CodeTenantHasChildren, err)
+	case errors.Is(err, limits.ErrMaxDepth), errors.Is(err, limits.ErrMaxChildren):
+		return codedError(http.StatusUnprocessableEntity, CodeTenantLimitExceeded, err)
+	case errors.Is(err, ErrInvalidPageSize), errors.Is(err, ErrNameRequired):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	default:
+		return err
+	}
+}
+
+// codedError returns an http error whose body has the code
+func codedError(status int, code string, err error) error {
+	return echo.NewHTTPError(status, ErrorResponse{Message: err.Error(), Code: code}).SetInternal(err)
+}
+
+func parseID(c echo.Context) (gidx.PrefixedID, error) {
+	id, err := gidx.Parse(c.Param("id"))
+	if err != nil {
+		return gidx.NullPrefixedID, echo.NewHTTPError(http.StatusBadRequest, "invalid tenant id").SetInternal(err)
+	}
+
+	return id, nil
+}
+
+func encodeCursor(cursor *ent.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	cursor.MarshalGQL(&sb)
+
+	return strings.Trim(sb.String(), `"`)
+}
@@ -0,0 +1,264 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/tenant-api/internal/admission"
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/kinds"
+	"go.infratographer.com/tenant-api/internal/limits"
+)
+
+func withChecker(checker permissions.Checker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := context.WithValue(c.Request().Context(), permissions.CheckerCtxKey, checker)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
+func newTestServer(t *testing.T, client *ent.Client, checker permissions.Checker) *echo.Echo {
+	t.Helper()
+
+	e := echo.New()
+
+	NewHandler(client, []echo.MiddlewareFunc{withChecker(checker)}).Routes(e.Group(""))
+
+	return e
+}
+
+func do(t *testing.T, e *echo.Echo, method, path, body string, out interface{}) int {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if out != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out), rec.Body.String())
+	}
+
+	return rec.Code
+}
+
+func TestTenants(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:restapi?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	e := newTestServer(t, client, permissions.DefaultAllowChecker)
+
+	var root Tenant
+
+	require.Equal(t, http.StatusCreated, do(t, e, http.MethodPost, "/api/v1/tenants", `{"name": "root", "description": "the root"}`, &root))
+	assert.Equal(t, "root", root.Name)
+	assert.Equal(t, "the root", root.Description)
+	assert.Nil(t, root.ParentID)
+
+	for _, name := range []string{"a", "b", "c"} {
+		var child Tenant
+
+		body := `{"name": "` + name + `", "parent_id": "` + root.ID.String() + `"}`
+
+		require.Equal(t, http.StatusCreated, do(t, e, http.MethodPost, "/api/v1/tenants", body, &child))
+		require.NotNil(t, child.ParentID)
+		assert.Equal(t, root.ID, *child.ParentID)
+	}
+
+	t.Run("get", func(t *testing.T) {
+		var got Tenant
+
+		assert.Equal(t, http.StatusOK, do(t, e, http.MethodGet, "/api/v1/tenants/"+root.ID.String(), "", &got))
+		assert.Equal(t, root.ID, got.ID)
+
+		assert.Equal(t, http.StatusNotFound, do(t, e, http.MethodGet, "/api/v1/tenants/"+gidx.MustNewID("tnntten").String(), "", nil))
+		assert.Equal(t, http.StatusBadRequest, do(t, e, http.MethodGet, "/api/v1/tenants/not-an-id", "", nil))
+	})
+
+	t.Run("list roots", func(t *testing.T) {
+		var list TenantList
+
+		assert.Equal(t, http.StatusOK, do(t, e, http.MethodGet, "/api/v1/tenants", "", &list))
+		require.Len(t, list.Tenants, 1)
+		assert.Equal(t, root.ID, list.Tenants[0].ID)
+	})
+
+	t.Run("paginate children", func(t *testing.T) {
+		var page1, page2 TenantList
+
+		path := "/api/v1/tenants/" + root.ID.String() + "/children?first=2"
+
+		assert.Equal(t, http.StatusOK, do(t, e, http.MethodGet, path, "", &page1))
+		assert.Len(t, page1.Tenants, 2)
+		assert.True(t, page1.PageInfo.HasNextPage)
+
+		assert.Equal(t, http.StatusOK, do(t, e, http.MethodGet, path+"&after="+page1.PageInfo.EndCursor, "", &page2))
+		assert.Len(t, page2.Tenants, 1)
+		assert.False(t, page2.PageInfo.HasNextPage)
+		assert.NotContains(t, page1.Tenants, page2.Tenants[0])
+
+		assert.Equal(t, http.StatusBadRequest, do(t, e, http.MethodGet, path+"0000", "", nil))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		var updated Tenant
+
+		assert.Equal(t, http.StatusOK, do(t, e, http.MethodPatch, "/api/v1/tenants/"+root.ID.String(), `{"name": "renamed", "clear_description": true}`, &updated))
+		assert.Equal(t, "renamed", updated.Name)
+		assert.Empty(t, updated.Description)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		var errResp ErrorResponse
+
+		assert.Equal(t, http.StatusConflict, do(t, e, http.MethodDelete, "/api/v1/tenants/"+root.ID.String(), "", &errResp))
+		assert.Equal(t, CodeTenantHasChildren, errResp.Code)
+
+		var children TenantList
+
+		do(t, e, http.MethodGet, "/api/v1/tenants/"+root.ID.String()+"/children", "", &children)
+
+		for _, child := range children.Tenants {
+			assert.Equal(t, http.StatusNoContent, do(t, e, http.MethodDelete, "/api/v1/tenants/"+child.ID.String(), "", nil))
+		}
+
+		assert.Equal(t, http.StatusNoContent, do(t, e, http.MethodDelete, "/api/v1/tenants/"+root.ID.String(), "", nil))
+	})
+
+	t.Run("create without name", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, do(t, e, http.MethodPost, "/api/v1/tenants", `{}`, nil))
+	})
+}
+
+func TestPermissionDenied(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:restapi-denied?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	e := newTestServer(t, client, permissions.DefaultDenyChecker)
+
+	assert.Equal(t, http.StatusForbidden, do(t, e, http.MethodGet, "/api/v1/tenants", "", nil))
+	assert.Equal(t, http.StatusForbidden, do(t, e, http.MethodPost, "/api/v1/tenants", `{"name": "root"}`, nil))
+}
+
+func TestAdmission(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:restapi-admission?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	// made before admission hooks are used
+	root := client.Tenant.Create().SetName("root").SaveX(context.Background())
+
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(admission.Response{Allowed: false, Reason: "no new tenants"})
+	}))
+	defer hook.Close()
+
+	controller, err := admission.NewController(admission.Config{Hooks: []admission.HookConfig{
+		{Name: "deny", URL: hook.URL, Operations: []string{admission.OperationCreate}},
+		{Name: "down", URL: "http://127.0.0.1:1", Operations: []string{admission.OperationUpdate}},
+	}})
+	require.NoError(t, err)
+
+	client.Use(controller.Hook)
+
+	e := newTestServer(t, client, permissions.DefaultAllowChecker)
+
+	assert.Equal(t, http.StatusForbidden, do(t, e, http.MethodPost, "/api/v1/tenants", `{"name": "root"}`, nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, do(t, e, http.MethodPatch, "/api/v1/tenants/"+root.ID.String(), `{"name": "renamed"}`, nil))
+}
+
+func TestLimits(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:restapi-limits?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	limiter, err := limits.NewLimiter(limits.Config{MaxDepth: 2, MaxChildren: 1})
+	require.NoError(t, err)
+
+	client.Use(limiter.Hook)
+
+	root := client.Tenant.Create().SetName("root").SaveX(context.Background())
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(context.Background())
+
+	e := newTestServer(t, client, permissions.DefaultAllowChecker)
+
+	var errResp ErrorResponse
+
+	assert.Equal(t, http.StatusUnprocessableEntity, do(t, e, http.MethodPost, "/api/v1/tenants", `{"name": "grandchild", "parent_id": "`+child.ID.String()+`"}`, &errResp))
+	assert.Equal(t, CodeTenantLimitExceeded, errResp.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, do(t, e, http.MethodPost, "/api/v1/tenants", `{"name": "second-child", "parent_id": "`+root.ID.String()+`"}`, nil))
+	assert.Equal(t, http.StatusCreated, do(t, e, http.MethodPost, "/api/v1/tenants", `{"name": "other-root"}`, nil))
+}
+
+func TestKinds(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:restapi-kinds?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	graph, err := kinds.NewGraph(kinds.Config{Rules: []kinds.Rule{
+		{Kind: "ORGANIZATION", Root: true},
+		{Kind: "PROJECT", Parents: []string{"ORGANIZATION"}},
+	}})
+	require.NoError(t, err)
+
+	client.Use(graph.Hook)
+
+	e := newTestServer(t, client, permissions.DefaultAllowChecker)
+
+	var org, project Tenant
+
+	require.Equal(t, http.StatusCreated, do(t, e, http.MethodPost, "/api/v1/tenants", `{"name": "org", "kind": "ORGANIZATION"}`, &org))
+	assert.Equal(t, tenant.KindOrganization, org.Kind)
+
+	require.Equal(t, http.StatusCreated, do(t, e, http.MethodPost, "/api/v1/tenants", `{"name": "project", "kind": "PROJECT", "parent_id": "`+org.ID.String()+`"}`, &project))
+	assert.Equal(t, tenant.KindProject, project.Kind)
+
+	assert.Equal(t, http.StatusBadRequest, do(t, e, http.MethodPost, "/api/v1/tenants", `{"name": "orphan", "kind": "PROJECT"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, do(t, e, http.MethodPost, "/api/v1/tenants", `{"name": "nested", "kind": "ORGANIZATION", "parent_id": "`+project.ID.String()+`"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, do(t, e, http.MethodPost, "/api/v1/tenants", `{"name": "team", "kind": "TEAM"}`, nil))
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	e := newTestServer(t, nil, permissions.DefaultDenyChecker)
+
+	var doc map[string]interface{}
+
+	require.Equal(t, http.StatusOK, do(t, e, http.MethodGet, "/api/v1/openapi.json", "", &doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+
+	paths, ok := doc["paths"].(map[string]interface{})
+	require.True(t, ok)
+
+	for _, route := range e.Routes() {
+		if route.Path == "/api/v1/openapi.json" || !strings.HasPrefix(route.Path, apiPath) {
+			continue
+		}
+
+		path := strings.ReplaceAll(strings.TrimPrefix(route.Path, apiPath), ":id", "{id}")
+
+		// group middleware registers catch all routes
+		if path == "" || strings.HasSuffix(path, "*") {
+			continue
+		}
+
+		op, ok := paths[path].(map[string]interface{})
+		require.True(t, ok, "missing path %s", path)
+		assert.Contains(t, op, strings.ToLower(route.Method), "missing %s %s", route.Method, path)
+	}
+}
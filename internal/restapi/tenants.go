@@ -0,0 +1,261 @@
+package restapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/gidx"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/graphapi"
+	"go.infratographer.com/tenant-api/internal/tenants"
+)
+
+// Tenant is the REST representation of a tenant
+type Tenant struct {
+	ID          gidx.PrefixedID  `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Kind        tenant.Kind      `json:"kind"`
+	ParentID    *gidx.PrefixedID `json:"parent_id,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// TenantList is a page of tenants
+type TenantList struct {
+	Tenants  []Tenant `json:"tenants"`
+	PageInfo PageInfo `json:"page_info"`
+}
+
+// PageInfo describes how to request the next page of a list, EndCursor is
+// passed as the after parameter
+type PageInfo struct {
+	HasNextPage bool   `json:"has_next_page"`
+	EndCursor   string `json:"end_cursor,omitempty"`
+}
+
+// CreateTenantRequest is the body of a tenant create request
+type CreateTenantRequest struct {
+	Name        string           `json:"name"`
+	Description *string          `json:"description,omitempty"`
+	Kind        *tenant.Kind     `json:"kind,omitempty"`
+	ParentID    *gidx.PrefixedID `json:"parent_id,omitempty"`
+}
+
+// UpdateTenantRequest is the body of a tenant update request, only the
+// fields which are set are changed
+type UpdateTenantRequest struct {
+	Name             *string `json:"name,omitempty"`
+	Description      *string `json:"description,omitempty"`
+	ClearDescription bool    `json:"clear_description,omitempty"`
+}
+
+func newTenant(t *ent.Tenant) Tenant {
+	out := Tenant{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Kind:        t.Kind,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+
+	if t.ParentTenantID != "" {
+		parentID := t.ParentTenantID
+		out.ParentID = &parentID
+	}
+
+	return out
+}
+
+func (h *Handler) getTenant(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := permissions.CheckAccess(ctx, id, graphapi.ActionTenantGet); err != nil {
+		return httpError(err)
+	}
+
+	t, err := h.client.Tenant.Get(ctx, id)
+	if err != nil {
+		return httpError(err)
+	}
+
+	return c.JSON(http.StatusOK, newTenant(t))
+}
+
+func (h *Handler) createTenant(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req CreateTenantRequest
+
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if req.Name == "" {
+		return httpError(ErrNameRequired)
+	}
+
+	resource := gidx.NullPrefixedID
+
+	if req.ParentID != nil {
+		resource = *req.ParentID
+	}
+
+	if err := permissions.CheckAccess(ctx, resource, graphapi.ActionTenantCreate); err != nil {
+		return httpError(err)
+	}
+
+	t, err := tenants.Create(ctx, h.client, ent.CreateTenantInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Kind:        req.Kind,
+		ParentID:    req.ParentID,
+	})
+	if err != nil {
+		return httpError(err)
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, apiPath+"/tenants/"+t.ID.String())
+
+	return c.JSON(http.StatusCreated, newTenant(t))
+}
+
+func (h *Handler) updateTenant(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateTenantRequest
+
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := permissions.CheckAccess(ctx, id, graphapi.ActionTenantUpdate); err != nil {
+		return httpError(err)
+	}
+
+	t, err := tenants.Update(ctx, h.client, id, ent.UpdateTenantInput{
+		Name:             req.Name,
+		Description:      req.Description,
+		ClearDescription: req.ClearDescription,
+	})
+	if err != nil {
+		return httpError(err)
+	}
+
+	return c.JSON(http.StatusOK, newTenant(t))
+}
+
+func (h *Handler) deleteTenant(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := permissions.CheckAccess(ctx, id, graphapi.ActionTenantDelete); err != nil {
+		return httpError(err)
+	}
+
+	if err := tenants.Delete(ctx, h.client, id); err != nil {
+		return httpError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// listTenants lists the root tenants
+func (h *Handler) listTenants(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	if err := permissions.CheckAccess(ctx, gidx.NullPrefixedID, graphapi.ActionTenantList); err != nil {
+		return httpError(err)
+	}
+
+	return h.paginate(ctx, c, h.client.Tenant.Query().Where(tenant.ParentTenantIDIsNil()))
+}
+
+func (h *Handler) listChildren(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := parseID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := permissions.CheckAccess(ctx, id, graphapi.ActionTenantList); err != nil {
+		return httpError(err)
+	}
+
+	exists, err := h.client.Tenant.Query().Where(tenant.ID(id)).Exist(ctx)
+	if err != nil {
+		return httpError(err)
+	}
+
+	if !exists {
+		return echo.NewHTTPError(http.StatusNotFound, "tenant not found")
+	}
+
+	return h.paginate(ctx, c, h.client.Tenant.Query().Where(tenant.ParentTenantID(id)))
+}
+
+// paginate returns a page of the query using the first and after query
+// parameters, cursors are compatible with the GraphQL API
+func (h *Handler) paginate(ctx context.Context, c echo.Context, query *ent.TenantQuery) error {
+	first := defaultPageSize
+
+	if v := c.QueryParam("first"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			return httpError(ErrInvalidPageSize)
+		}
+
+		first = n
+	}
+
+	var after *ent.Cursor
+
+	if v := c.QueryParam("after"); v != "" {
+		after = &ent.Cursor{}
+
+		if err := after.UnmarshalGQL(v); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid cursor").SetInternal(err)
+		}
+	}
+
+	conn, err := query.Paginate(ctx, after, &first, nil, nil)
+	if err != nil {
+		return httpError(err)
+	}
+
+	list := TenantList{
+		Tenants: make([]Tenant, 0, len(conn.Edges)),
+		PageInfo: PageInfo{
+			HasNextPage: conn.PageInfo.HasNextPage,
+			EndCursor:   encodeCursor(conn.PageInfo.EndCursor),
+		},
+	}
+
+	for _, edge := range conn.Edges {
+		list.Tenants = append(list.Tenants, newTenant(edge.Node))
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
@@ -0,0 +1,92 @@
+package tenantcache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// Backend stores encoded cache entries. It's implemented by the in-process
+// LRU, shared caches such as Redis can implement it so every replica uses the
+// same entries.
+type Backend interface {
+	// Get returns the entry for the key and whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the entry for the key, it expires after the ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the entries for the keys
+	Delete(ctx context.Context, keys ...string) error
+}
+
+type lruEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// LRU is an in-process Backend which keeps the most recently used entries
+type LRU struct {
+	mu    sync.Mutex
+	cache *lru.Cache[string, lruEntry]
+	now   func() time.Time
+}
+
+var _ Backend = (*LRU)(nil)
+
+// NewLRU returns an in-process backend holding up to size entries
+func NewLRU(size int) (*LRU, error) {
+	cache, err := lru.New[string, lruEntry](size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LRU{cache: cache, now: time.Now}, nil
+}
+
+// Get returns the entry for the key if it hasn't expired
+func (l *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.cache.Get(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	if !entry.expires.IsZero() && !l.now().Before(entry.expires) {
+		l.cache.Remove(key)
+
+		return nil, false, nil
+	}
+
+	return entry.value, true, nil
+}
+
+// Set stores the entry for the key, a zero ttl never expires
+func (l *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := lruEntry{value: value}
+
+	if ttl > 0 {
+		entry.expires = l.now().Add(ttl)
+	}
+
+	l.cache.Add(key, entry)
+
+	return nil
+}
+
+// Delete removes the entries for the keys
+func (l *LRU) Delete(_ context.Context, keys ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		l.cache.Remove(key)
+	}
+
+	return nil
+}
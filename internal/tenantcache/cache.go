@@ -0,0 +1,321 @@
+// Package tenantcache caches tenant records and ancestry for the lookups
+// which dominate database load, such as federation entity resolution.
+//
+// Entries are populated on read and invalidated by tenant change events, so
+// every replica converges once it receives the event. Entries also expire
+// after a ttl, which bounds staleness when events are missed. Tenants changed
+// by this process are invalidated as soon as the change is committed.
+//
+// Tenants are always loaded from the primary database, even when the
+// request allows stale reads, since a stale record would be cached until it's
+// invalidated again or expires.
+package tenantcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"entgo.io/ent"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/tenant-api/internal/database"
+	generated "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/hook"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/metrics"
+)
+
+const (
+	recordKeyPrefix    = "tenant:"
+	ancestorsKeyPrefix = "ancestors:"
+
+	kindRecord    = "record"
+	kindAncestors = "ancestors"
+
+	sourceEvent    = "event"
+	sourceMutation = "mutation"
+
+	// changesTopic matches all tenant change messages
+	changesTopic = "*.tenant"
+)
+
+// ErrSubscriptionClosed is returned by Run when the change subscription ends
+var ErrSubscriptionClosed = errors.New("change subscription closed")
+
+// ChangeSubscriber subscribes to change messages, it's satisfied by
+// *events.Subscriber
+type ChangeSubscriber interface {
+	SubscribeChanges(ctx context.Context, topic string) (<-chan *message.Message, error)
+}
+
+// Cache is a read-through cache of tenants
+type Cache struct {
+	client  *generated.Client
+	backend Backend
+	ttl     time.Duration
+	logger  *zap.SugaredLogger
+}
+
+// Option configures a Cache
+type Option func(*Cache)
+
+// WithTTL sets how long entries are kept, a zero ttl keeps entries until
+// they're invalidated or evicted
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithLogger sets the logger for the cache
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(c *Cache) {
+		c.logger = logger
+	}
+}
+
+// New returns a cache of tenants loaded with client and stored in backend.
+// Mutations made with client invalidate the tenants they change.
+func New(client *generated.Client, backend Backend, opts ...Option) *Cache {
+	c := &Cache{
+		client:  client,
+		backend: backend,
+		ttl:     defaultTTL,
+		logger:  zap.NewNop().Sugar(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	client.Tenant.Use(c.invalidationHook())
+
+	return c
+}
+
+// Get returns the tenant, loading it from the database when it isn't cached
+func (c *Cache) Get(ctx context.Context, id gidx.PrefixedID) (*generated.Tenant, error) {
+	var t generated.Tenant
+
+	if c.lookup(ctx, kindRecord, recordKeyPrefix+id.String(), &t) {
+		return c.client.AttachTenant(&t), nil
+	}
+
+	loaded, err := c.client.Tenant.Get(database.WithoutStaleReads(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	record := *loaded
+	record.Edges = generated.TenantEdges{}
+
+	c.store(ctx, recordKeyPrefix+id.String(), &record)
+
+	return loaded, nil
+}
+
+// GetMany returns the tenants which exist, keyed by ID, loading the ones
+// which aren't cached from the database in a single query
+func (c *Cache) GetMany(ctx context.Context, ids []gidx.PrefixedID) (map[gidx.PrefixedID]*generated.Tenant, error) {
+	tenants := make(map[gidx.PrefixedID]*generated.Tenant, len(ids))
+	misses := make([]gidx.PrefixedID, 0, len(ids))
+
+	for _, id := range ids {
+		var t generated.Tenant
+
+		if c.lookup(ctx, kindRecord, recordKeyPrefix+id.String(), &t) {
+			tenants[id] = c.client.AttachTenant(&t)
+
+			continue
+		}
+
+		misses = append(misses, id)
+	}
+
+	if len(misses) == 0 {
+		return tenants, nil
+	}
+
+	loaded, err := c.client.Tenant.Query().Where(tenant.IDIn(misses...)).All(database.WithoutStaleReads(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range loaded {
+		record := *t
+		record.Edges = generated.TenantEdges{}
+
+		c.store(ctx, recordKeyPrefix+t.ID.String(), &record)
+
+		tenants[t.ID] = t
+	}
+
+	return tenants, nil
+}
+
+// Ancestors returns the IDs of the ancestors of the tenant, nearest first
+func (c *Cache) Ancestors(ctx context.Context, id gidx.PrefixedID) ([]gidx.PrefixedID, error) {
+	var ancestors []gidx.PrefixedID
+
+	if c.lookup(ctx, kindAncestors, ancestorsKeyPrefix+id.String(), &ancestors) {
+		return ancestors, nil
+	}
+
+	t, err := c.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors = []gidx.PrefixedID{}
+	seen := map[gidx.PrefixedID]bool{id: true}
+
+	for t.ParentTenantID != gidx.NullPrefixedID {
+		// guard against a cycle in corrupt data looping forever
+		if seen[t.ParentTenantID] {
+			break
+		}
+
+		seen[t.ParentTenantID] = true
+
+		ancestors = append(ancestors, t.ParentTenantID)
+
+		t, err = c.Get(ctx, t.ParentTenantID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.store(ctx, ancestorsKeyPrefix+id.String(), ancestors)
+
+	return ancestors, nil
+}
+
+// Run invalidates tenants as their change messages are received, it
+// returns when the context is canceled or the subscription ends.
+func (c *Cache) Run(ctx context.Context, sub ChangeSubscriber) error {
+	messages, err := sub.SubscribeChanges(ctx, changesTopic)
+	if err != nil {
+		return fmt.Errorf("subscribing to tenant changes: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return ErrSubscriptionClosed
+			}
+
+			msg.Ack()
+
+			change, err := events.UnmarshalChangeMessage(msg.Payload)
+			if err != nil {
+				c.logger.Warnw("failed to unmarshal change message", "error", err, "message_id", msg.UUID)
+
+				continue
+			}
+
+			switch events.ChangeType(change.EventType) {
+			case events.CreateChangeType:
+				// nothing is cached for tenants which didn't exist
+			case events.DeleteChangeType:
+				c.invalidate(ctx, sourceEvent, true, change.SubjectID)
+			default:
+				c.invalidate(ctx, sourceEvent, false, change.SubjectID)
+			}
+		}
+	}
+}
+
+func (c *Cache) invalidate(ctx context.Context, source string, deleted bool, ids ...gidx.PrefixedID) {
+	keys := make([]string, 0, len(ids)*2)
+
+	for _, id := range ids {
+		keys = append(keys, recordKeyPrefix+id.String())
+
+		if deleted {
+			keys = append(keys, ancestorsKeyPrefix+id.String())
+		}
+
+		metrics.RecordCacheInvalidation(source)
+	}
+
+	if err := c.backend.Delete(ctx, keys...); err != nil {
+		c.logger.Errorw("failed to invalidate cached tenants", "error", err, "tenants", ids)
+	}
+}
+
+// lookup decodes the cached entry into v and reports whether it was found,
+// backend failures are treated as misses so reads fall back to the database
+func (c *Cache) lookup(ctx context.Context, kind, key string, v interface{}) bool {
+	b, ok, err := c.backend.Get(ctx, key)
+	if err != nil {
+		c.logger.Warnw("failed to read tenant cache", "error", err, "key", key)
+	}
+
+	if ok && err == nil {
+		if err := json.Unmarshal(b, v); err != nil {
+			c.logger.Warnw("failed to decode cached tenant", "error", err, "key", key)
+
+			ok = false
+		}
+	}
+
+	hit := ok && err == nil
+
+	metrics.RecordCacheLookup(kind, hit)
+
+	return hit
+}
+
+func (c *Cache) store(ctx context.Context, key string, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		c.logger.Warnw("failed to encode tenant for cache", "error", err, "key", key)
+
+		return
+	}
+
+	if err := c.backend.Set(ctx, key, b, c.ttl); err != nil {
+		c.logger.Warnw("failed to write tenant cache", "error", err, "key", key)
+	}
+}
+
+// invalidationHook invalidates tenants once a mutation changing them is
+// committed, so this process reads its own writes without waiting for
+// events. Invalidating before the commit would let a concurrent lookup cache
+// the tenant as it was before the change.
+func (c *Cache) invalidationHook() ent.Hook {
+	return hook.On(func(next ent.Mutator) ent.Mutator {
+		return hook.TenantFunc(func(ctx context.Context, m *generated.TenantMutation) (ent.Value, error) {
+			ids, err := m.IDs(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := next.Mutate(ctx, m)
+			if err != nil {
+				return v, err
+			}
+
+			deleted := m.Op().Is(ent.OpDelete | ent.OpDeleteOne)
+
+			if err := m.Client().AfterCommit(func(*generated.Client) error {
+				c.invalidate(ctx, sourceMutation, deleted, ids...)
+
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+
+			return v, nil
+		})
+	}, ent.OpUpdate|ent.OpUpdateOne|ent.OpDelete|ent.OpDeleteOne)
+}
@@ -0,0 +1,281 @@
+package tenantcache
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/tenant-api/internal/database"
+	generated "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+)
+
+type fakeSubscriber struct {
+	messages chan *message.Message
+}
+
+func (s fakeSubscriber) SubscribeChanges(_ context.Context, _ string) (<-chan *message.Message, error) {
+	return s.messages, nil
+}
+
+func newTestCache(t *testing.T, name string) (*Cache, *LRU, *generated.Client) {
+	t.Helper()
+
+	client := enttest.Open(t, "sqlite3", "file:"+name+"?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { client.Close() })
+
+	backend, err := NewLRU(100)
+	require.NoError(t, err)
+
+	return New(client, backend), backend, client
+}
+
+// setStale replaces the cached record of the tenant, as if it was changed
+// by another replica after it was cached
+func setStale(t *testing.T, backend *LRU, tnt *generated.Tenant, name string) {
+	t.Helper()
+
+	stale := *tnt
+	stale.Name = name
+
+	b, err := json.Marshal(stale)
+	require.NoError(t, err)
+
+	require.NoError(t, backend.Set(context.Background(), recordKeyPrefix+tnt.ID.String(), b, 0))
+}
+
+func TestLRUExpiry(t *testing.T) {
+	ctx := context.Background()
+
+	backend, err := NewLRU(10)
+	require.NoError(t, err)
+
+	now := time.Now()
+	backend.now = func() time.Time { return now }
+
+	require.NoError(t, backend.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, backend.Set(ctx, "b", []byte("2"), 0))
+
+	v, ok, err := backend.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), v)
+
+	now = now.Add(time.Minute)
+
+	_, ok, _ = backend.Get(ctx, "a")
+	assert.False(t, ok)
+
+	_, ok, _ = backend.Get(ctx, "b")
+	assert.True(t, ok)
+
+	require.NoError(t, backend.Delete(ctx, "b"))
+
+	_, ok, _ = backend.Get(ctx, "b")
+	assert.False(t, ok)
+}
+
+func TestCacheGet(t *testing.T) {
+	ctx := context.Background()
+
+	cache, backend, client := newTestCache(t, "tenantcache-get")
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+
+	got, err := cache.Get(ctx, root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "root", got.Name)
+
+	setStale(t, backend, root, "cached")
+
+	got, err = cache.Get(ctx, root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "cached", got.Name)
+
+	// cached tenants are attached to the client so edges can be queried
+	children, err := got.QueryChildren().All(ctx)
+	require.NoError(t, err)
+	assert.Len(t, children, 1)
+
+	// mutations made with the client invalidate the tenant
+	client.Tenant.UpdateOneID(root.ID).SetName("renamed").ExecX(ctx)
+
+	got, err = cache.Get(ctx, root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", got.Name)
+
+	_, err = cache.Get(ctx, gidx.MustNewID("tnntten"))
+	assert.True(t, generated.IsNotFound(err))
+}
+
+func TestCacheGetIgnoresStaleReads(t *testing.T) {
+	ctx := context.Background()
+
+	primary := enttest.Open(t, "sqlite3", "file:tenantcache-primary?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { primary.Close() })
+
+	// the read pool has no tables, so loads fail unless they use the primary
+	reads, err := entsql.Open(dialect.SQLite, "file:tenantcache-reads?mode=memory&cache=shared")
+	require.NoError(t, err)
+
+	primaryDrv, err := entsql.Open(dialect.SQLite, "file:tenantcache-primary?mode=memory&cache=shared&_fk=1")
+	require.NoError(t, err)
+
+	client := generated.NewClient(generated.Driver(database.NewReadRouter(primaryDrv, reads)))
+	t.Cleanup(func() { client.Close() })
+
+	backend, err := NewLRU(100)
+	require.NoError(t, err)
+
+	cache := New(client, backend)
+
+	root := primary.Tenant.Create().SetName("root").SaveX(ctx)
+
+	_, err = client.Tenant.Get(database.WithStaleReads(ctx), root.ID)
+	require.Error(t, err)
+
+	got, err := cache.Get(database.WithStaleReads(ctx), root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "root", got.Name)
+
+	many, err := cache.GetMany(database.WithStaleReads(ctx), []gidx.PrefixedID{root.ID, gidx.MustNewID("tnntten")})
+	require.NoError(t, err)
+	assert.Len(t, many, 1)
+}
+
+func TestCacheInvalidatesOnCommit(t *testing.T) {
+	ctx := context.Background()
+
+	cache, backend, client := newTestCache(t, "tenantcache-commit")
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+
+	tx, err := client.Tx(ctx)
+	require.NoError(t, err)
+
+	tx.Tenant.UpdateOneID(root.ID).SetName("renamed").ExecX(ctx)
+
+	// a lookup before the commit caches the tenant as it was
+	setStale(t, backend, root, "root")
+
+	require.NoError(t, tx.Commit())
+
+	got, err := cache.Get(ctx, root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", got.Name)
+
+	// nothing is invalidated when the transaction is rolled back
+	tx, err = client.Tx(ctx)
+	require.NoError(t, err)
+
+	tx.Tenant.UpdateOneID(root.ID).SetName("rolled-back").ExecX(ctx)
+	require.NoError(t, tx.Rollback())
+
+	_, ok, err := backend.Get(ctx, recordKeyPrefix+root.ID.String())
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCacheAncestors(t *testing.T) {
+	ctx := context.Background()
+
+	cache, _, client := newTestCache(t, "tenantcache-ancestors")
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+	grandchild := client.Tenant.Create().SetName("grandchild").SetParent(child).SaveX(ctx)
+
+	ancestors, err := cache.Ancestors(ctx, grandchild.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []gidx.PrefixedID{child.ID, root.ID}, ancestors)
+
+	ancestors, err = cache.Ancestors(ctx, root.ID)
+	require.NoError(t, err)
+	assert.Empty(t, ancestors)
+
+	client.Tenant.DeleteOneID(grandchild.ID).ExecX(ctx)
+
+	_, err = cache.Ancestors(ctx, grandchild.ID)
+	assert.True(t, generated.IsNotFound(err), "deleted tenants shouldn't have cached ancestry")
+}
+
+func TestCacheRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cache, backend, client := newTestCache(t, "tenantcache-run")
+
+	tnt := client.Tenant.Create().SetName("tenant").SaveX(ctx)
+
+	sub := fakeSubscriber{messages: make(chan *message.Message)}
+
+	done := make(chan error)
+
+	go func() {
+		done <- cache.Run(ctx, sub)
+	}()
+
+	send := func(eventType events.ChangeType, subject gidx.PrefixedID) {
+		payload, err := json.Marshal(events.ChangeMessage{
+			SubjectID: subject,
+			EventType: string(eventType),
+			Timestamp: time.Now(),
+		})
+		require.NoError(t, err)
+
+		sub.messages <- message.NewMessage(watermill.NewUUID(), payload)
+	}
+
+	setStale(t, backend, tnt, "stale")
+
+	send(events.CreateChangeType, tnt.ID)
+	send(events.CreateChangeType, gidx.MustNewID("tnntten")) // sync with the previous message
+
+	got, err := cache.Get(ctx, tnt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "stale", got.Name, "create events shouldn't invalidate")
+
+	send(events.UpdateChangeType, tnt.ID)
+	send(events.CreateChangeType, gidx.MustNewID("tnntten"))
+
+	got, err = cache.Get(ctx, tnt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant", got.Name)
+
+	close(sub.messages)
+	assert.ErrorIs(t, <-done, ErrSubscriptionClosed)
+}
+
+func TestCacheGetMany(t *testing.T) {
+	ctx := context.Background()
+
+	cache, backend, client := newTestCache(t, "tenantcache-getmany")
+
+	cached := client.Tenant.Create().SetName("cached").SaveX(ctx)
+	uncached := client.Tenant.Create().SetName("uncached").SaveX(ctx)
+	missing := gidx.MustNewID("tnntten")
+
+	setStale(t, backend, cached, "stale")
+
+	tenants, err := cache.GetMany(ctx, []gidx.PrefixedID{cached.ID, uncached.ID, missing})
+	require.NoError(t, err)
+	require.Len(t, tenants, 2)
+	assert.Equal(t, "stale", tenants[cached.ID].Name)
+	assert.Equal(t, "uncached", tenants[uncached.ID].Name)
+
+	_, ok, err := backend.Get(ctx, recordKeyPrefix+uncached.ID.String())
+	require.NoError(t, err)
+	assert.True(t, ok, "loaded tenants should be cached")
+}
@@ -0,0 +1,29 @@
+package tenantcache
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+const defaultTTL = time.Minute
+
+// Config stores the configuration for the tenant cache
+type Config struct {
+	// Size is the number of entries kept in memory, zero disables the cache
+	Size int `mapstructure:"size"`
+	// TTL is how long entries are kept, it bounds how stale a cached tenant
+	// may be when a change event is missed
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+// MustViperFlags returns the cobra flags and viper config for the tenant cache
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Int("cache-size", 0, "number of tenant records and ancestries to cache in memory, 0 disables the cache")
+	viperx.MustBindFlag(v, "cache.size", flags.Lookup("cache-size"))
+
+	flags.Duration("cache-ttl", defaultTTL, "how long cached tenants are kept before they're reloaded")
+	viperx.MustBindFlag(v, "cache.ttl", flags.Lookup("cache-ttl"))
+}
@@ -0,0 +1,78 @@
+// Package tenants is the tenant mutations shared by the GraphQL and REST
+// APIs, the command worker and the CLI, which have to run in a transaction.
+//
+// Events are published once the transaction is committed, so a mutation
+// which fails or is rolled back is never published.
+package tenants
+
+import (
+	"context"
+	"errors"
+
+	"go.infratographer.com/x/gidx"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+)
+
+// ErrHasChildren is returned when deleting a tenant which still has children
+var ErrHasChildren = errors.New("tenant has children and can't be deleted")
+
+// Create creates the tenant and returns it, unwrapped from the transaction it
+// was created in so its edges can be loaded once it's committed. The tenant
+// limits and kind graph are checked in the transaction it's created in.
+func Create(ctx context.Context, client *ent.Client, input ent.CreateTenantInput) (*ent.Tenant, error) {
+	var t *ent.Tenant
+
+	err := client.WithTx(ctx, func(tx *ent.Tx) error {
+		var err error
+
+		t, err = tx.Tenant.Create().SetInput(input).Save(ctx)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t.Unwrap(), nil
+}
+
+// Update updates the tenant and returns it, unwrapped from the transaction it
+// was updated in so its edges can be loaded once it's committed. Sibling names
+// are checked, and the update event's sequence number read back, in the
+// transaction the tenant is updated in.
+func Update(ctx context.Context, client *ent.Client, id gidx.PrefixedID, input ent.UpdateTenantInput) (*ent.Tenant, error) {
+	var t *ent.Tenant
+
+	err := client.WithTx(ctx, func(tx *ent.Tx) error {
+		var err error
+
+		t, err = tx.Tenant.UpdateOneID(id).SetInput(input).Save(ctx)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t.Unwrap(), nil
+}
+
+// Delete deletes the tenant, which can't have children. The children are
+// counted, and the delete event's snapshot loaded, in the transaction the
+// tenant is deleted in.
+func Delete(ctx context.Context, client *ent.Client, id gidx.PrefixedID) error {
+	return client.WithTx(ctx, func(tx *ent.Tx) error {
+		childrenCount, err := tx.Tenant.Query().Where(tenant.ParentTenantID(id)).Count(ctx)
+		if err != nil {
+			return err
+		}
+
+		if childrenCount != 0 {
+			return ErrHasChildren
+		}
+
+		return tx.Tenant.DeleteOneID(id).Exec(ctx)
+	})
+}
@@ -0,0 +1,154 @@
+package tenants
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+	"go.infratographer.com/tenant-api/internal/ent/generated/eventhooks"
+)
+
+// recorder records published changes along with whether their subject was
+// committed when they were published
+type recorder struct {
+	mu        sync.Mutex
+	changes   []events.ChangeMessage
+	committed []bool
+}
+
+func (r *recorder) PublishChange(ctx context.Context, _ string, change events.ChangeMessage) error {
+	exists, err := ent.FromContext(ctx).Tenant.Get(ctx, change.SubjectID)
+	if err != nil && !ent.IsNotFound(err) {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.changes = append(r.changes, change)
+	r.committed = append(r.committed, exists != nil)
+
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func newTestClient(t *testing.T, name string) (*ent.Client, *recorder) {
+	t.Helper()
+
+	rec := &recorder{}
+
+	client := enttest.Open(t, "sqlite3", "file:"+name+"?mode=memory&cache=shared&_fk=1",
+		enttest.WithOptions(ent.EventsPublisher(rec)),
+	)
+	t.Cleanup(func() { client.Close() })
+
+	eventhooks.EventHooks(client)
+
+	return client, rec
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+
+	client, rec := newTestClient(t, "tenants-create")
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+
+	published := len(rec.changes)
+
+	created, err := Create(ctx, client, ent.CreateTenantInput{Name: "child", ParentID: &root.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "child", created.Name)
+
+	// the tenant isn't bound to the committed transaction
+	assert.Equal(t, root.ID, created.QueryParent().OnlyIDX(ctx))
+
+	require.Len(t, rec.changes, published+1)
+	assert.Equal(t, created.ID, rec.changes[published].SubjectID)
+	assert.True(t, rec.committed[published], "the create is published once it's committed")
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	client, rec := newTestClient(t, "tenants-update")
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	client.Tenant.Create().SetName("child").SetParent(root).ExecX(ctx)
+
+	published := len(rec.changes)
+
+	updated, err := Update(ctx, client, root.ID, ent.UpdateTenantInput{Name: ptr("renamed")})
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", updated.Name)
+
+	// the tenant isn't bound to the committed transaction
+	assert.Equal(t, 1, updated.QueryChildren().CountX(ctx))
+
+	require.Len(t, rec.changes, published+1)
+	assert.Equal(t, root.ID, rec.changes[published].SubjectID)
+	assert.True(t, rec.committed[published], "the update is published once it's committed")
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+
+	client, rec := newTestClient(t, "tenants-delete")
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+
+	published := len(rec.changes)
+
+	require.ErrorIs(t, Delete(ctx, client, root.ID), ErrHasChildren)
+	assert.Len(t, rec.changes, published, "a failed delete isn't published")
+
+	require.NoError(t, Delete(ctx, client, child.ID))
+	require.Len(t, rec.changes, published+1)
+	assert.Equal(t, child.ID, rec.changes[published].SubjectID)
+	assert.False(t, rec.committed[published], "the delete is published once it's committed")
+}
+
+func TestEventsPublishedAfterCommit(t *testing.T) {
+	ctx := context.Background()
+
+	client, rec := newTestClient(t, "tenants-commit")
+
+	errRollback := errors.New("rollback")
+
+	err := client.WithTx(ctx, func(tx *ent.Tx) error {
+		tx.Tenant.Create().SetName("rolled-back").ExecX(ctx)
+
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+	assert.Empty(t, rec.changes, "a rolled back change isn't published")
+
+	var first, second *ent.Tenant
+
+	err = client.WithTx(ctx, func(tx *ent.Tx) error {
+		first = tx.Tenant.Create().SetName("first").SaveX(ctx)
+		second = tx.Tenant.Create().SetName("second").SetParent(first).SaveX(ctx)
+
+		assert.Empty(t, rec.changes, "changes aren't published before the commit")
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, rec.changes, 2)
+	assert.Equal(t, first.ID, rec.changes[0].SubjectID, "changes are published in the order they're made")
+	assert.Equal(t, second.ID, rec.changes[1].SubjectID)
+	assert.Equal(t, []bool{true, true}, rec.committed)
+}
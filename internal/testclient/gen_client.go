@@ -15,6 +15,7 @@ type TestClient interface {
 	GetTenant(ctx context.Context, id gidx.PrefixedID, httpRequestOptions ...client.HTTPRequestOption) (*GetTenant, error)
 	GetTenantChildByID(ctx context.Context, id gidx.PrefixedID, childID gidx.PrefixedID, httpRequestOptions ...client.HTTPRequestOption) (*GetTenantChildByID, error)
 	GetTenantChildren(ctx context.Context, id gidx.PrefixedID, orderBy *TenantOrder, httpRequestOptions ...client.HTTPRequestOption) (*GetTenantChildren, error)
+	GetTenantChildrenOfKinds(ctx context.Context, id gidx.PrefixedID, kinds []TenantKind, httpRequestOptions ...client.HTTPRequestOption) (*GetTenantChildrenOfKinds, error)
 	TenantCreate(ctx context.Context, input CreateTenantInput, httpRequestOptions ...client.HTTPRequestOption) (*TenantCreate, error)
 	TenantDelete(ctx context.Context, id gidx.PrefixedID, httpRequestOptions ...client.HTTPRequestOption) (*TenantDelete, error)
 	TenantUpdate(ctx context.Context, id gidx.PrefixedID, input UpdateTenantInput, httpRequestOptions ...client.HTTPRequestOption) (*TenantUpdate, error)
@@ -29,20 +30,27 @@ func NewClient(cli *http.Client, baseURL string, options ...client.HTTPRequestOp
 }
 
 type Query struct {
-	Tenant   Tenant   "json:\"tenant\" graphql:\"tenant\""
-	Entities []Entity "json:\"_entities\" graphql:\"_entities\""
-	Service  Service  "json:\"_service\" graphql:\"_service\""
+	Tenant               Tenant                        "json:\"tenant\" graphql:\"tenant\""
+	WebhookSubscription  WebhookSubscription           "json:\"webhookSubscription\" graphql:\"webhookSubscription\""
+	WebhookSubscriptions WebhookSubscriptionConnection "json:\"webhookSubscriptions\" graphql:\"webhookSubscriptions\""
+	Entities             []Entity                      "json:\"_entities\" graphql:\"_entities\""
+	Service              Service                       "json:\"_service\" graphql:\"_service\""
 }
 type Mutation struct {
-	TenantCreate TenantCreatePayload "json:\"tenantCreate\" graphql:\"tenantCreate\""
-	TenantUpdate TenantUpdatePayload "json:\"tenantUpdate\" graphql:\"tenantUpdate\""
-	TenantDelete TenantDeletePayload "json:\"tenantDelete\" graphql:\"tenantDelete\""
+	TenantCreate              TenantCreatePayload              "json:\"tenantCreate\" graphql:\"tenantCreate\""
+	TenantUpdate              TenantUpdatePayload              "json:\"tenantUpdate\" graphql:\"tenantUpdate\""
+	TenantDelete              TenantDeletePayload              "json:\"tenantDelete\" graphql:\"tenantDelete\""
+	WebhookSubscriptionCreate WebhookSubscriptionCreatePayload "json:\"webhookSubscriptionCreate\" graphql:\"webhookSubscriptionCreate\""
+	WebhookSubscriptionUpdate WebhookSubscriptionUpdatePayload "json:\"webhookSubscriptionUpdate\" graphql:\"webhookSubscriptionUpdate\""
+	WebhookSubscriptionDelete WebhookSubscriptionDeletePayload "json:\"webhookSubscriptionDelete\" graphql:\"webhookSubscriptionDelete\""
+	WebhookDeliveryRetry      WebhookDeliveryRetryPayload      "json:\"webhookDeliveryRetry\" graphql:\"webhookDeliveryRetry\""
 }
 type GetTenant struct {
 	Tenant struct {
 		ID          gidx.PrefixedID "json:\"id\" graphql:\"id\""
 		Name        string          "json:\"name\" graphql:\"name\""
 		Description *string         "json:\"description\" graphql:\"description\""
+		Kind        TenantKind      "json:\"kind\" graphql:\"kind\""
 		CreatedAt   time.Time       "json:\"createdAt\" graphql:\"createdAt\""
 		UpdatedAt   time.Time       "json:\"updatedAt\" graphql:\"updatedAt\""
 		Parent      *struct {
@@ -77,12 +85,26 @@ type GetTenantChildren struct {
 		} "json:\"children\" graphql:\"children\""
 	} "json:\"tenant\" graphql:\"tenant\""
 }
+type GetTenantChildrenOfKinds struct {
+	Tenant struct {
+		Children struct {
+			Edges []*struct {
+				Node *struct {
+					ID   gidx.PrefixedID "json:\"id\" graphql:\"id\""
+					Name string          "json:\"name\" graphql:\"name\""
+					Kind TenantKind      "json:\"kind\" graphql:\"kind\""
+				} "json:\"node\" graphql:\"node\""
+			} "json:\"edges\" graphql:\"edges\""
+		} "json:\"children\" graphql:\"children\""
+	} "json:\"tenant\" graphql:\"tenant\""
+}
 type TenantCreate struct {
 	TenantCreate struct {
 		Tenant struct {
 			ID          gidx.PrefixedID "json:\"id\" graphql:\"id\""
 			Name        string          "json:\"name\" graphql:\"name\""
 			Description *string         "json:\"description\" graphql:\"description\""
+			Kind        TenantKind      "json:\"kind\" graphql:\"kind\""
 			Parent      *struct {
 				ID gidx.PrefixedID "json:\"id\" graphql:\"id\""
 			} "json:\"parent\" graphql:\"parent\""
@@ -112,6 +134,7 @@ const GetTenantDocument = `query GetTenant ($id: ID!) {
 		id
 		name
 		description
+		kind
 		createdAt
 		updatedAt
 		parent {
@@ -193,12 +216,42 @@ func (c *Client) GetTenantChildren(ctx context.Context, id gidx.PrefixedID, orde
 	return &res, nil
 }
 
+const GetTenantChildrenOfKindsDocument = `query GetTenantChildrenOfKinds ($id: ID!, $kinds: [TenantKind!]) {
+	tenant(id: $id) {
+		children(where: {kindIn:$kinds}) {
+			edges {
+				node {
+					id
+					name
+					kind
+				}
+			}
+		}
+	}
+}
+`
+
+func (c *Client) GetTenantChildrenOfKinds(ctx context.Context, id gidx.PrefixedID, kinds []TenantKind, httpRequestOptions ...client.HTTPRequestOption) (*GetTenantChildrenOfKinds, error) {
+	vars := map[string]interface{}{
+		"id":    id,
+		"kinds": kinds,
+	}
+
+	var res GetTenantChildrenOfKinds
+	if err := c.Client.Post(ctx, "GetTenantChildrenOfKinds", GetTenantChildrenOfKindsDocument, &res, vars, httpRequestOptions...); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
 const TenantCreateDocument = `mutation TenantCreate ($input: CreateTenantInput!) {
 	tenantCreate(input: $input) {
 		tenant {
 			id
 			name
 			description
+			kind
 			parent {
 				id
 			}
@@ -38,8 +38,22 @@ type CreateTenantInput struct {
 	// The name of a tenant.
 	Name string `json:"name"`
 	// An optional description of the tenant.
-	Description *string          `json:"description,omitempty"`
-	ParentID    *gidx.PrefixedID `json:"parentID,omitempty"`
+	Description *string `json:"description,omitempty"`
+	// The kind of the tenant, the layer of the hierarchy it's in. The kinds a tenant may be a child of are configurable.
+	Kind     *TenantKind      `json:"kind,omitempty"`
+	ParentID *gidx.PrefixedID `json:"parentID,omitempty"`
+}
+
+// Input information to create a webhook subscription.
+type CreateWebhookSubscriptionInput struct {
+	// The URL change messages are posted to.
+	URL string `json:"url"`
+	// The secret used to sign the posted change messages, it can't be read back.
+	Secret string `json:"secret"`
+	// The change event types delivered, every event type is delivered when empty.
+	EventTypes []string `json:"eventTypes,omitempty"`
+	// The ID of the tenant whose subtree the subscription is scoped to, changes to every tenant are delivered when empty.
+	TenantID *gidx.PrefixedID `json:"tenantID,omitempty"`
 }
 
 // Information about pagination in a connection.
@@ -63,9 +77,11 @@ type Tenant struct {
 	// The name of a tenant.
 	Name string `json:"name"`
 	// An optional description of the tenant.
-	Description *string          `json:"description,omitempty"`
-	Parent      *Tenant          `json:"parent,omitempty"`
-	Children    TenantConnection `json:"children"`
+	Description *string `json:"description,omitempty"`
+	// The kind of the tenant, the layer of the hierarchy it's in. The kinds a tenant may be a child of are configurable.
+	Kind     TenantKind       `json:"kind"`
+	Parent   *Tenant          `json:"parent,omitempty"`
+	Children TenantConnection `json:"children"`
 }
 
 func (Tenant) IsMetadataNode()             {}
@@ -156,6 +172,11 @@ type TenantWhereInput struct {
 	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
 	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
 	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// kind field predicates
+	Kind      *TenantKind  `json:"kind,omitempty"`
+	KindNeq   *TenantKind  `json:"kindNEQ,omitempty"`
+	KindIn    []TenantKind `json:"kindIn,omitempty"`
+	KindNotIn []TenantKind `json:"kindNotIn,omitempty"`
 	// parent edge predicates
 	HasParent     *bool               `json:"hasParent,omitempty"`
 	HasParentWith []*TenantWhereInput `json:"hasParentWith,omitempty"`
@@ -173,6 +194,253 @@ type UpdateTenantInput struct {
 	ClearDescription *bool   `json:"clearDescription,omitempty"`
 }
 
+// Input information to update a webhook subscription.
+type UpdateWebhookSubscriptionInput struct {
+	// The URL change messages are posted to.
+	URL *string `json:"url,omitempty"`
+	// The secret used to sign the posted change messages, it can't be read back.
+	Secret *string `json:"secret,omitempty"`
+	// The change event types delivered, every event type is delivered when empty.
+	EventTypes       []string `json:"eventTypes,omitempty"`
+	AppendEventTypes []string `json:"appendEventTypes,omitempty"`
+	ClearEventTypes  *bool    `json:"clearEventTypes,omitempty"`
+}
+
+type WebhookDelivery struct {
+	// ID for the webhook delivery.
+	ID        gidx.PrefixedID `json:"id"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	// The event type of the delivered change.
+	EventType string `json:"eventType"`
+	// The ID of the tenant which changed.
+	SubjectID gidx.PrefixedID `json:"subjectID"`
+	// The change message posted to the webhook.
+	Payload string `json:"payload"`
+	// The status of the delivery, deliveries are dead once they run out of attempts.
+	Status WebhookDeliveryStatus `json:"status"`
+	// The number of times delivery was attempted.
+	Attempts int64 `json:"attempts"`
+	// When delivery is next attempted while the delivery is pending.
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	// When delivery was last attempted.
+	LastAttemptAt *time.Time `json:"lastAttemptAt,omitempty"`
+	// The HTTP status of the response to the last attempt.
+	ResponseStatus *int64 `json:"responseStatus,omitempty"`
+	// Why the last attempt failed.
+	LastError    *string             `json:"lastError,omitempty"`
+	Subscription WebhookSubscription `json:"subscription"`
+}
+
+func (WebhookDelivery) IsNode() {}
+
+// The id of the object.
+func (this WebhookDelivery) GetID() gidx.PrefixedID { return this.ID }
+
+// A connection to a list of items.
+type WebhookDeliveryConnection struct {
+	// A list of edges.
+	Edges []*WebhookDeliveryEdge `json:"edges,omitempty"`
+	// Information to aid in pagination.
+	PageInfo PageInfo `json:"pageInfo"`
+	// Identifies the total count of items in the connection.
+	TotalCount int64 `json:"totalCount"`
+}
+
+// An edge in a connection.
+type WebhookDeliveryEdge struct {
+	// The item at the end of the edge.
+	Node *WebhookDelivery `json:"node,omitempty"`
+	// A cursor for use in pagination.
+	Cursor string `json:"cursor"`
+}
+
+// Ordering options for WebhookDelivery connections
+type WebhookDeliveryOrder struct {
+	// The ordering direction.
+	Direction OrderDirection `json:"direction"`
+	// The field by which to order WebhookDeliveries.
+	Field WebhookDeliveryOrderField `json:"field"`
+}
+
+// Return response from webhookDeliveryRetry.
+type WebhookDeliveryRetryPayload struct {
+	// The delivery, pending another attempt.
+	WebhookDelivery WebhookDelivery `json:"webhookDelivery"`
+}
+
+// WebhookDeliveryWhereInput is used for filtering WebhookDelivery objects.
+// Input was generated by ent.
+type WebhookDeliveryWhereInput struct {
+	Not *WebhookDeliveryWhereInput   `json:"not,omitempty"`
+	And []*WebhookDeliveryWhereInput `json:"and,omitempty"`
+	Or  []*WebhookDeliveryWhereInput `json:"or,omitempty"`
+	// id field predicates
+	ID      *gidx.PrefixedID  `json:"id,omitempty"`
+	IDNeq   *gidx.PrefixedID  `json:"idNEQ,omitempty"`
+	IDIn    []gidx.PrefixedID `json:"idIn,omitempty"`
+	IDNotIn []gidx.PrefixedID `json:"idNotIn,omitempty"`
+	IDGt    *gidx.PrefixedID  `json:"idGT,omitempty"`
+	IDGte   *gidx.PrefixedID  `json:"idGTE,omitempty"`
+	IDLt    *gidx.PrefixedID  `json:"idLT,omitempty"`
+	IDLte   *gidx.PrefixedID  `json:"idLTE,omitempty"`
+	// created_at field predicates
+	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
+	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
+	CreatedAtIn    []*time.Time `json:"createdAtIn,omitempty"`
+	CreatedAtNotIn []*time.Time `json:"createdAtNotIn,omitempty"`
+	CreatedAtGt    *time.Time   `json:"createdAtGT,omitempty"`
+	CreatedAtGte   *time.Time   `json:"createdAtGTE,omitempty"`
+	CreatedAtLt    *time.Time   `json:"createdAtLT,omitempty"`
+	CreatedAtLte   *time.Time   `json:"createdAtLTE,omitempty"`
+	// updated_at field predicates
+	UpdatedAt      *time.Time   `json:"updatedAt,omitempty"`
+	UpdatedAtNeq   *time.Time   `json:"updatedAtNEQ,omitempty"`
+	UpdatedAtIn    []*time.Time `json:"updatedAtIn,omitempty"`
+	UpdatedAtNotIn []*time.Time `json:"updatedAtNotIn,omitempty"`
+	UpdatedAtGt    *time.Time   `json:"updatedAtGT,omitempty"`
+	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
+	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
+	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// event_type field predicates
+	EventType             *string  `json:"eventType,omitempty"`
+	EventTypeNeq          *string  `json:"eventTypeNEQ,omitempty"`
+	EventTypeIn           []string `json:"eventTypeIn,omitempty"`
+	EventTypeNotIn        []string `json:"eventTypeNotIn,omitempty"`
+	EventTypeGt           *string  `json:"eventTypeGT,omitempty"`
+	EventTypeGte          *string  `json:"eventTypeGTE,omitempty"`
+	EventTypeLt           *string  `json:"eventTypeLT,omitempty"`
+	EventTypeLte          *string  `json:"eventTypeLTE,omitempty"`
+	EventTypeContains     *string  `json:"eventTypeContains,omitempty"`
+	EventTypeHasPrefix    *string  `json:"eventTypeHasPrefix,omitempty"`
+	EventTypeHasSuffix    *string  `json:"eventTypeHasSuffix,omitempty"`
+	EventTypeEqualFold    *string  `json:"eventTypeEqualFold,omitempty"`
+	EventTypeContainsFold *string  `json:"eventTypeContainsFold,omitempty"`
+	// subject_id field predicates
+	SubjectID             *gidx.PrefixedID  `json:"subjectID,omitempty"`
+	SubjectIDNeq          *gidx.PrefixedID  `json:"subjectIDNEQ,omitempty"`
+	SubjectIDIn           []gidx.PrefixedID `json:"subjectIDIn,omitempty"`
+	SubjectIDNotIn        []gidx.PrefixedID `json:"subjectIDNotIn,omitempty"`
+	SubjectIDGt           *gidx.PrefixedID  `json:"subjectIDGT,omitempty"`
+	SubjectIDGte          *gidx.PrefixedID  `json:"subjectIDGTE,omitempty"`
+	SubjectIDLt           *gidx.PrefixedID  `json:"subjectIDLT,omitempty"`
+	SubjectIDLte          *gidx.PrefixedID  `json:"subjectIDLTE,omitempty"`
+	SubjectIDContains     *gidx.PrefixedID  `json:"subjectIDContains,omitempty"`
+	SubjectIDHasPrefix    *gidx.PrefixedID  `json:"subjectIDHasPrefix,omitempty"`
+	SubjectIDHasSuffix    *gidx.PrefixedID  `json:"subjectIDHasSuffix,omitempty"`
+	SubjectIDEqualFold    *gidx.PrefixedID  `json:"subjectIDEqualFold,omitempty"`
+	SubjectIDContainsFold *gidx.PrefixedID  `json:"subjectIDContainsFold,omitempty"`
+	// status field predicates
+	Status      *WebhookDeliveryStatus  `json:"status,omitempty"`
+	StatusNeq   *WebhookDeliveryStatus  `json:"statusNEQ,omitempty"`
+	StatusIn    []WebhookDeliveryStatus `json:"statusIn,omitempty"`
+	StatusNotIn []WebhookDeliveryStatus `json:"statusNotIn,omitempty"`
+	// subscription edge predicates
+	HasSubscription     *bool                            `json:"hasSubscription,omitempty"`
+	HasSubscriptionWith []*WebhookSubscriptionWhereInput `json:"hasSubscriptionWith,omitempty"`
+}
+
+type WebhookSubscription struct {
+	// ID for the webhook subscription.
+	ID        gidx.PrefixedID `json:"id"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	// The URL change messages are posted to.
+	URL string `json:"url"`
+	// The change event types delivered, every event type is delivered when empty.
+	EventTypes []string `json:"eventTypes,omitempty"`
+	// The ID of the tenant whose subtree the subscription is scoped to, changes to every tenant are delivered when empty.
+	TenantID   *gidx.PrefixedID          `json:"tenantID,omitempty"`
+	Deliveries WebhookDeliveryConnection `json:"deliveries"`
+}
+
+func (WebhookSubscription) IsNode() {}
+
+// The id of the object.
+func (this WebhookSubscription) GetID() gidx.PrefixedID { return this.ID }
+
+// A connection to a list of items.
+type WebhookSubscriptionConnection struct {
+	// A list of edges.
+	Edges []*WebhookSubscriptionEdge `json:"edges,omitempty"`
+	// Information to aid in pagination.
+	PageInfo PageInfo `json:"pageInfo"`
+	// Identifies the total count of items in the connection.
+	TotalCount int64 `json:"totalCount"`
+}
+
+// Return response from webhookSubscriptionCreate.
+type WebhookSubscriptionCreatePayload struct {
+	// The created webhook subscription.
+	WebhookSubscription WebhookSubscription `json:"webhookSubscription"`
+}
+
+// Return response from webhookSubscriptionDelete.
+type WebhookSubscriptionDeletePayload struct {
+	// The ID of the deleted webhook subscription.
+	DeletedID gidx.PrefixedID `json:"deletedID"`
+}
+
+// An edge in a connection.
+type WebhookSubscriptionEdge struct {
+	// The item at the end of the edge.
+	Node *WebhookSubscription `json:"node,omitempty"`
+	// A cursor for use in pagination.
+	Cursor string `json:"cursor"`
+}
+
+// Ordering options for WebhookSubscription connections
+type WebhookSubscriptionOrder struct {
+	// The ordering direction.
+	Direction OrderDirection `json:"direction"`
+	// The field by which to order WebhookSubscriptions.
+	Field WebhookSubscriptionOrderField `json:"field"`
+}
+
+// Return response from webhookSubscriptionUpdate.
+type WebhookSubscriptionUpdatePayload struct {
+	// The updated webhook subscription.
+	WebhookSubscription WebhookSubscription `json:"webhookSubscription"`
+}
+
+// WebhookSubscriptionWhereInput is used for filtering WebhookSubscription objects.
+// Input was generated by ent.
+type WebhookSubscriptionWhereInput struct {
+	Not *WebhookSubscriptionWhereInput   `json:"not,omitempty"`
+	And []*WebhookSubscriptionWhereInput `json:"and,omitempty"`
+	Or  []*WebhookSubscriptionWhereInput `json:"or,omitempty"`
+	// id field predicates
+	ID      *gidx.PrefixedID  `json:"id,omitempty"`
+	IDNeq   *gidx.PrefixedID  `json:"idNEQ,omitempty"`
+	IDIn    []gidx.PrefixedID `json:"idIn,omitempty"`
+	IDNotIn []gidx.PrefixedID `json:"idNotIn,omitempty"`
+	IDGt    *gidx.PrefixedID  `json:"idGT,omitempty"`
+	IDGte   *gidx.PrefixedID  `json:"idGTE,omitempty"`
+	IDLt    *gidx.PrefixedID  `json:"idLT,omitempty"`
+	IDLte   *gidx.PrefixedID  `json:"idLTE,omitempty"`
+	// created_at field predicates
+	CreatedAt      *time.Time   `json:"createdAt,omitempty"`
+	CreatedAtNeq   *time.Time   `json:"createdAtNEQ,omitempty"`
+	CreatedAtIn    []*time.Time `json:"createdAtIn,omitempty"`
+	CreatedAtNotIn []*time.Time `json:"createdAtNotIn,omitempty"`
+	CreatedAtGt    *time.Time   `json:"createdAtGT,omitempty"`
+	CreatedAtGte   *time.Time   `json:"createdAtGTE,omitempty"`
+	CreatedAtLt    *time.Time   `json:"createdAtLT,omitempty"`
+	CreatedAtLte   *time.Time   `json:"createdAtLTE,omitempty"`
+	// updated_at field predicates
+	UpdatedAt      *time.Time   `json:"updatedAt,omitempty"`
+	UpdatedAtNeq   *time.Time   `json:"updatedAtNEQ,omitempty"`
+	UpdatedAtIn    []*time.Time `json:"updatedAtIn,omitempty"`
+	UpdatedAtNotIn []*time.Time `json:"updatedAtNotIn,omitempty"`
+	UpdatedAtGt    *time.Time   `json:"updatedAtGT,omitempty"`
+	UpdatedAtGte   *time.Time   `json:"updatedAtGTE,omitempty"`
+	UpdatedAtLt    *time.Time   `json:"updatedAtLT,omitempty"`
+	UpdatedAtLte   *time.Time   `json:"updatedAtLTE,omitempty"`
+	// deliveries edge predicates
+	HasDeliveries     *bool                        `json:"hasDeliveries,omitempty"`
+	HasDeliveriesWith []*WebhookDeliveryWhereInput `json:"hasDeliveriesWith,omitempty"`
+}
+
 type Service struct {
 	Sdl *string `json:"sdl,omitempty"`
 }
@@ -221,6 +489,54 @@ func (e OrderDirection) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+// TenantKind is enum for the field kind
+type TenantKind string
+
+const (
+	TenantKindTenant       TenantKind = "TENANT"
+	TenantKindOrganization TenantKind = "ORGANIZATION"
+	TenantKindBusinessUnit TenantKind = "BUSINESS_UNIT"
+	TenantKindProject      TenantKind = "PROJECT"
+	TenantKindEnvironment  TenantKind = "ENVIRONMENT"
+)
+
+var AllTenantKind = []TenantKind{
+	TenantKindTenant,
+	TenantKindOrganization,
+	TenantKindBusinessUnit,
+	TenantKindProject,
+	TenantKindEnvironment,
+}
+
+func (e TenantKind) IsValid() bool {
+	switch e {
+	case TenantKindTenant, TenantKindOrganization, TenantKindBusinessUnit, TenantKindProject, TenantKindEnvironment:
+		return true
+	}
+	return false
+}
+
+func (e TenantKind) String() string {
+	return string(e)
+}
+
+func (e *TenantKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TenantKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TenantKind", str)
+	}
+	return nil
+}
+
+func (e TenantKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 // Properties by which Tenant connections can be ordered.
 type TenantOrderField string
 
@@ -264,3 +580,133 @@ func (e *TenantOrderField) UnmarshalGQL(v interface{}) error {
 func (e TenantOrderField) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+// Properties by which WebhookDelivery connections can be ordered.
+type WebhookDeliveryOrderField string
+
+const (
+	WebhookDeliveryOrderFieldCreatedAt     WebhookDeliveryOrderField = "CREATED_AT"
+	WebhookDeliveryOrderFieldUpdatedAt     WebhookDeliveryOrderField = "UPDATED_AT"
+	WebhookDeliveryOrderFieldNextAttemptAt WebhookDeliveryOrderField = "NEXT_ATTEMPT_AT"
+)
+
+var AllWebhookDeliveryOrderField = []WebhookDeliveryOrderField{
+	WebhookDeliveryOrderFieldCreatedAt,
+	WebhookDeliveryOrderFieldUpdatedAt,
+	WebhookDeliveryOrderFieldNextAttemptAt,
+}
+
+func (e WebhookDeliveryOrderField) IsValid() bool {
+	switch e {
+	case WebhookDeliveryOrderFieldCreatedAt, WebhookDeliveryOrderFieldUpdatedAt, WebhookDeliveryOrderFieldNextAttemptAt:
+		return true
+	}
+	return false
+}
+
+func (e WebhookDeliveryOrderField) String() string {
+	return string(e)
+}
+
+func (e *WebhookDeliveryOrderField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookDeliveryOrderField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookDeliveryOrderField", str)
+	}
+	return nil
+}
+
+func (e WebhookDeliveryOrderField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// WebhookDeliveryStatus is enum for the field status
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryStatusDead      WebhookDeliveryStatus = "DEAD"
+)
+
+var AllWebhookDeliveryStatus = []WebhookDeliveryStatus{
+	WebhookDeliveryStatusPending,
+	WebhookDeliveryStatusDelivered,
+	WebhookDeliveryStatusDead,
+}
+
+func (e WebhookDeliveryStatus) IsValid() bool {
+	switch e {
+	case WebhookDeliveryStatusPending, WebhookDeliveryStatusDelivered, WebhookDeliveryStatusDead:
+		return true
+	}
+	return false
+}
+
+func (e WebhookDeliveryStatus) String() string {
+	return string(e)
+}
+
+func (e *WebhookDeliveryStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookDeliveryStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookDeliveryStatus", str)
+	}
+	return nil
+}
+
+func (e WebhookDeliveryStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Properties by which WebhookSubscription connections can be ordered.
+type WebhookSubscriptionOrderField string
+
+const (
+	WebhookSubscriptionOrderFieldCreatedAt WebhookSubscriptionOrderField = "CREATED_AT"
+	WebhookSubscriptionOrderFieldUpdatedAt WebhookSubscriptionOrderField = "UPDATED_AT"
+)
+
+var AllWebhookSubscriptionOrderField = []WebhookSubscriptionOrderField{
+	WebhookSubscriptionOrderFieldCreatedAt,
+	WebhookSubscriptionOrderFieldUpdatedAt,
+}
+
+func (e WebhookSubscriptionOrderField) IsValid() bool {
+	switch e {
+	case WebhookSubscriptionOrderFieldCreatedAt, WebhookSubscriptionOrderFieldUpdatedAt:
+		return true
+	}
+	return false
+}
+
+func (e WebhookSubscriptionOrderField) String() string {
+	return string(e)
+}
+
+func (e *WebhookSubscriptionOrderField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = WebhookSubscriptionOrderField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid WebhookSubscriptionOrderField", str)
+	}
+	return nil
+}
+
+func (e WebhookSubscriptionOrderField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -0,0 +1,117 @@
+// Package hooks enforces the parts of the validation policy which can't be
+// enforced by field validators, it normalizes tenant names and descriptions
+// before they're stored and keeps sibling names unique.
+package hooks
+
+import (
+	"context"
+	"fmt"
+
+	"entgo.io/ent"
+	"go.infratographer.com/x/gidx"
+
+	generated "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/predicate"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+	"go.infratographer.com/tenant-api/internal/validation"
+)
+
+// TenantPolicy is an ent hook which normalizes the names and descriptions of
+// tenant mutations with the active policy, and returns a *validation.FieldError
+// when a name is already used by a sibling and the policy requires unique
+// sibling names
+func TenantPolicy(next ent.Mutator) ent.Mutator {
+	return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+		tm, ok := m.(*generated.TenantMutation)
+		if !ok || !m.Op().Is(ent.OpCreate|ent.OpUpdate|ent.OpUpdateOne) {
+			return next.Mutate(ctx, m)
+		}
+
+		policy := validation.ActivePolicy()
+
+		if name, ok := tm.Name(); ok {
+			tm.SetName(policy.Name.Normalize(name))
+		}
+
+		if desc, ok := tm.Description(); ok {
+			tm.SetDescription(policy.Description.Normalize(desc))
+		}
+
+		if policy.Siblings.UniqueNames {
+			if err := uniqueName(ctx, tm, policy.Siblings); err != nil {
+				return nil, err
+			}
+		}
+
+		return next.Mutate(ctx, m)
+	})
+}
+
+// uniqueName returns an error when the mutation's name is used by a sibling
+// of a tenant it creates or renames
+func uniqueName(ctx context.Context, m *generated.TenantMutation, policy validation.SiblingPolicy) error {
+	name, ok := m.Name()
+	if !ok {
+		return nil
+	}
+
+	if m.Op().Is(ent.OpCreate) {
+		parent, _ := m.ParentTenantID()
+
+		return checkSiblings(ctx, m.Client(), name, parent, gidx.NullPrefixedID, policy)
+	}
+
+	ids, err := m.IDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		t, err := m.Client().Tenant.Get(ctx, id)
+
+		switch {
+		case generated.IsNotFound(err):
+			// the mutation fails with not found
+			continue
+		case err != nil:
+			return fmt.Errorf("loading tenant %s for validation: %w", id, err)
+		}
+
+		if err := checkSiblings(ctx, m.Client(), name, t.ParentTenantID, id, policy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkSiblings returns an error when a tenant other than self with the parent
+// is named name
+func checkSiblings(ctx context.Context, client *generated.Client, name string, parent, self gidx.PrefixedID, policy validation.SiblingPolicy) error {
+	preds := []predicate.Tenant{tenant.NameEQ(name)}
+
+	if policy.CaseInsensitive {
+		preds[0] = tenant.NameEqualFold(name)
+	}
+
+	if parent == gidx.NullPrefixedID {
+		preds = append(preds, tenant.ParentTenantIDIsNil())
+	} else {
+		preds = append(preds, tenant.ParentTenantIDEQ(parent))
+	}
+
+	if self != gidx.NullPrefixedID {
+		preds = append(preds, tenant.IDNEQ(self))
+	}
+
+	exists, err := client.Tenant.Query().Where(preds...).Exist(ctx)
+	if err != nil {
+		return fmt.Errorf("checking sibling names: %w", err)
+	}
+
+	if exists {
+		return &validation.FieldError{Field: tenant.FieldName, Err: fmt.Errorf("%w: %q", validation.ErrDuplicateName, name)}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package hooks
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+	"go.infratographer.com/tenant-api/internal/validation"
+)
+
+func newTestClient(t *testing.T, name string, policy *validation.Policy) *ent.Client {
+	t.Helper()
+
+	require.NoError(t, validation.SetPolicy(policy))
+	t.Cleanup(func() { _ = validation.SetPolicy(validation.DefaultPolicy()) })
+
+	client := enttest.Open(t, "sqlite3", "file:"+name+"?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { client.Close() })
+
+	client.Use(TenantPolicy)
+
+	return client
+}
+
+func TestTenantPolicyNormalizes(t *testing.T) {
+	ctx := context.Background()
+
+	policy := validation.DefaultPolicy()
+	policy.Name.CollapseSpaces = true
+	policy.Name.Normalization = validation.NormalizationNFC
+
+	client := newTestClient(t, "validation-normalizes", policy)
+
+	tnt := client.Tenant.Create().SetName("  Cafe\u0301   Corp ").SetDescription(" the cafe ").SaveX(ctx)
+	assert.Equal(t, "Caf\u00e9 Corp", tnt.Name)
+	assert.Equal(t, "the cafe", tnt.Description)
+
+	tnt = client.Tenant.UpdateOne(tnt).SetName(" Acme ").SaveX(ctx)
+	assert.Equal(t, "Acme", tnt.Name)
+}
+
+func TestTenantPolicyValidates(t *testing.T) {
+	ctx := context.Background()
+
+	policy := validation.DefaultPolicy()
+	policy.Name.Reserved = []string{"admin"}
+
+	client := newTestClient(t, "validation-validates", policy)
+
+	_, err := client.Tenant.Create().SetName(strings.Repeat(" ", 10<<10)).Save(ctx)
+	require.True(t, ent.IsValidationError(err), "blank names are invalid")
+	assert.ErrorIs(t, err, validation.ErrTooShort)
+
+	var validationErr *ent.ValidationError
+
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "name", validationErr.Name)
+
+	_, err = client.Tenant.Create().SetName("ADMIN").Save(ctx)
+	assert.ErrorIs(t, err, validation.ErrReserved)
+
+	_, err = client.Tenant.Create().SetName("acme").SetDescription(strings.Repeat("a", 4097)).Save(ctx)
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "description", validationErr.Name)
+	assert.ErrorIs(t, err, validation.ErrTooLong)
+}
+
+func TestTenantPolicyUniqueSiblings(t *testing.T) {
+	ctx := context.Background()
+
+	policy := validation.DefaultPolicy()
+	policy.Siblings = validation.SiblingPolicy{UniqueNames: true, CaseInsensitive: true}
+
+	client := newTestClient(t, "validation-siblings", policy)
+
+	acme := client.Tenant.Create().SetName("acme").SaveX(ctx)
+
+	_, err := client.Tenant.Create().SetName("ACME").Save(ctx)
+	require.ErrorIs(t, err, validation.ErrDuplicateName, "root tenants are siblings")
+	assert.True(t, validation.IsFieldError(err))
+
+	finance := client.Tenant.Create().SetName("finance").SetParent(acme).SaveX(ctx)
+	client.Tenant.Create().SetName("finance").SetParent(finance).ExecX(ctx)
+
+	_, err = client.Tenant.Create().SetName("Finance").SetParent(acme).Save(ctx)
+	assert.ErrorIs(t, err, validation.ErrDuplicateName)
+
+	payroll := client.Tenant.Create().SetName("payroll").SetParent(acme).SaveX(ctx)
+
+	_, err = client.Tenant.UpdateOne(payroll).SetName("finance").Save(ctx)
+	assert.ErrorIs(t, err, validation.ErrDuplicateName)
+
+	client.Tenant.UpdateOne(payroll).SetName("Payroll").ExecX(ctx)
+}
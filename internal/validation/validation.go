@@ -0,0 +1,346 @@
+// Package validation is the validation policy of tenant names and
+// descriptions, loaded from a YAML policy file.
+//
+// The policy's rules are enforced by the validators of the tenant schema's
+// fields, so every ent client, whether it's used by the APIs, the CLI or an
+// importer, shares them and fails with field-specific validation errors.
+// Values are normalized, and sibling names kept unique, by the ent hook in
+// the hooks package, which is used wherever the event hooks are.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// ClassLetter allows unicode letters
+	ClassLetter = "letter"
+	// ClassDigit allows unicode digits
+	ClassDigit = "digit"
+	// ClassSpace allows unicode whitespace
+	ClassSpace = "space"
+	// ClassPunct allows unicode punctuation
+	ClassPunct = "punct"
+	// ClassSymbol allows unicode symbols
+	ClassSymbol = "symbol"
+
+	// NormalizationNFC composes characters, so equivalent values are stored
+	// the same way
+	NormalizationNFC = "nfc"
+	// NormalizationNFKC also replaces compatibility characters, such as
+	// full-width letters, with their canonical equivalent
+	NormalizationNFKC = "nfkc"
+
+	defaultNameMaxLength        = 255
+	defaultDescriptionMaxLength = 4096
+)
+
+var classes = map[string]func(rune) bool{
+	ClassLetter: unicode.IsLetter,
+	ClassDigit:  unicode.IsDigit,
+	ClassSpace:  unicode.IsSpace,
+	ClassPunct:  unicode.IsPunct,
+	ClassSymbol: unicode.IsSymbol,
+}
+
+var (
+	// ErrTooShort is returned when a value is shorter than the policy allows
+	ErrTooShort = errors.New("too short")
+	// ErrTooLong is returned when a value is longer than the policy allows
+	ErrTooLong = errors.New("too long")
+	// ErrInvalidCharacter is returned when a value has a character outside of
+	// the allowed character classes
+	ErrInvalidCharacter = errors.New("invalid character")
+	// ErrReserved is returned when a value is a reserved name
+	ErrReserved = errors.New("reserved")
+	// ErrDenied is returned when a value matches a denylist pattern
+	ErrDenied = errors.New("not allowed")
+	// ErrDuplicateName is returned when a tenant's name is already used by
+	// another tenant with the same parent
+	ErrDuplicateName = errors.New("already used by a sibling")
+	// ErrInvalidPolicy is returned when a policy can't be loaded
+	ErrInvalidPolicy = errors.New("invalid validation policy")
+)
+
+// FieldError is a violation of the policy by a field's value which isn't
+// returned by the field's validator, such as a duplicate sibling name
+type FieldError struct {
+	// Field is the name of the field, name or description
+	Field string
+	Err   error
+}
+
+// Error implements the error interface
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", e.Field, e.Err)
+}
+
+// Unwrap returns the violation
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// IsFieldError returns a boolean indicating whether the error is a field error
+func IsFieldError(err error) bool {
+	var e *FieldError
+
+	return errors.As(err, &e)
+}
+
+// Config stores the configuration for the validation policy
+type Config struct {
+	// PolicyFile is the YAML file the policy is loaded from, the default
+	// policy is used when it's not set
+	PolicyFile string `mapstructure:"policy_file"`
+}
+
+// MustViperFlags returns the cobra flags and viper config for the validation policy
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.String("validation-policy-file", "", "YAML file with the validation policy of tenant names and descriptions")
+	viperx.MustBindFlag(v, "validation.policy_file", flags.Lookup("validation-policy-file"))
+}
+
+// Policy is the validation policy of tenant names and descriptions
+type Policy struct {
+	Name        FieldPolicy   `mapstructure:"name"`
+	Description FieldPolicy   `mapstructure:"description"`
+	Siblings    SiblingPolicy `mapstructure:"siblings"`
+}
+
+// FieldPolicy is the validation policy of a field. Values are normalized
+// before they're validated.
+type FieldPolicy struct {
+	// Trim removes leading and trailing whitespace
+	Trim bool `mapstructure:"trim"`
+	// CollapseSpaces replaces each run of whitespace with a single space
+	CollapseSpaces bool `mapstructure:"collapse_spaces"`
+	// Normalization is the unicode normalization form values are stored in,
+	// nfc or nfkc, they aren't normalized when it's empty
+	Normalization string `mapstructure:"normalization"`
+	// MinLength is the fewest characters a value may have
+	MinLength int `mapstructure:"min_length"`
+	// MaxLength is the most characters a value may have, 0 doesn't limit it
+	MaxLength int `mapstructure:"max_length"`
+	// Allowed are the character classes values may have, letter, digit,
+	// space, punct or symbol, any character is allowed when it's empty
+	Allowed []string `mapstructure:"allowed"`
+	// AllowedChars are characters values may have in addition to the
+	// allowed classes
+	AllowedChars string `mapstructure:"allowed_chars"`
+	// Reserved are values which can't be used, compared case-insensitively
+	Reserved []string `mapstructure:"reserved"`
+	// Denylist are regular expressions values can't match, such as
+	// profanity, (?i) makes a pattern case-insensitive
+	Denylist []string `mapstructure:"denylist"`
+
+	denylist []*regexp.Regexp
+}
+
+// SiblingPolicy is the validation policy of tenants with the same parent, or
+// of root tenants
+type SiblingPolicy struct {
+	// UniqueNames requires siblings to have different names
+	UniqueNames bool `mapstructure:"unique_names"`
+	// CaseInsensitive compares sibling names case-insensitively
+	CaseInsensitive bool `mapstructure:"case_insensitive"`
+}
+
+// DefaultPolicy returns the policy used without a policy file, names can't
+// be blank and names and descriptions are trimmed and bounded in length
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Name: FieldPolicy{
+			Trim:      true,
+			MinLength: 1,
+			MaxLength: defaultNameMaxLength,
+		},
+		Description: FieldPolicy{
+			Trim:      true,
+			MaxLength: defaultDescriptionMaxLength,
+		},
+	}
+}
+
+var active atomic.Pointer[Policy]
+
+func init() {
+	active.Store(DefaultPolicy())
+}
+
+// SetPolicy sets the policy enforced by the validators and hooks
+func SetPolicy(p *Policy) error {
+	if err := p.compile(); err != nil {
+		return err
+	}
+
+	active.Store(p)
+
+	return nil
+}
+
+// ActivePolicy returns the policy enforced by the validators and hooks
+func ActivePolicy() *Policy {
+	return active.Load()
+}
+
+// ValidateName is the validator of tenant names, it validates the name with
+// the active policy
+func ValidateName(name string) error {
+	return ActivePolicy().Name.Validate(name)
+}
+
+// ValidateDescription is the validator of tenant descriptions, it validates
+// the description with the active policy
+func ValidateDescription(desc string) error {
+	return ActivePolicy().Description.Validate(desc)
+}
+
+// LoadPolicy loads a policy from the YAML file, rules which aren't set keep
+// their default
+func LoadPolicy(path string) (*Policy, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+
+	p := DefaultPolicy()
+
+	if err := v.UnmarshalExact(p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+
+	if err := p.compile(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// compile checks the policy's rules and compiles its denylists
+func (p *Policy) compile() error {
+	for field, fp := range map[string]*FieldPolicy{"name": &p.Name, "description": &p.Description} {
+		if err := fp.compile(); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidPolicy, field, err)
+		}
+	}
+
+	return nil
+}
+
+func (fp *FieldPolicy) compile() error {
+	switch fp.Normalization {
+	case "", NormalizationNFC, NormalizationNFKC:
+	default:
+		return fmt.Errorf("unknown normalization %q", fp.Normalization)
+	}
+
+	for _, class := range fp.Allowed {
+		if _, ok := classes[class]; !ok {
+			return fmt.Errorf("unknown character class %q", class)
+		}
+	}
+
+	if fp.MaxLength > 0 && fp.MinLength > fp.MaxLength {
+		return fmt.Errorf("min_length %d is greater than max_length %d", fp.MinLength, fp.MaxLength)
+	}
+
+	fp.denylist = make([]*regexp.Regexp, len(fp.Denylist))
+
+	for i, pattern := range fp.Denylist {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("denylist pattern %q: %v", pattern, err)
+		}
+
+		fp.denylist[i] = re
+	}
+
+	return nil
+}
+
+// Normalize returns the value as it's stored
+func (fp FieldPolicy) Normalize(v string) string {
+	switch fp.Normalization {
+	case NormalizationNFC:
+		v = norm.NFC.String(v)
+	case NormalizationNFKC:
+		v = norm.NFKC.String(v)
+	}
+
+	if fp.CollapseSpaces {
+		v = strings.Join(strings.Fields(v), " ")
+	}
+
+	if fp.Trim {
+		v = strings.TrimSpace(v)
+	}
+
+	return v
+}
+
+// Validate returns the policy violation of the value once it's normalized
+func (fp FieldPolicy) Validate(v string) error {
+	v = fp.Normalize(v)
+
+	length := utf8.RuneCountInString(v)
+
+	if length < fp.MinLength {
+		return fmt.Errorf("%w: at least %d characters are required", ErrTooShort, fp.MinLength)
+	}
+
+	if fp.MaxLength > 0 && length > fp.MaxLength {
+		return fmt.Errorf("%w: at most %d characters are allowed", ErrTooLong, fp.MaxLength)
+	}
+
+	if len(fp.Allowed) > 0 {
+		for _, r := range v {
+			if !fp.allowed(r) {
+				return fmt.Errorf("%w: %q", ErrInvalidCharacter, r)
+			}
+		}
+	}
+
+	for _, reserved := range fp.Reserved {
+		if strings.EqualFold(v, reserved) {
+			return fmt.Errorf("%w: %q is reserved", ErrReserved, v)
+		}
+	}
+
+	for _, re := range fp.denylist {
+		if re.MatchString(v) {
+			return fmt.Errorf("%w: matches a denied pattern", ErrDenied)
+		}
+	}
+
+	return nil
+}
+
+// allowed reports whether the character is in an allowed class or one of the
+// allowed characters
+func (fp FieldPolicy) allowed(r rune) bool {
+	if strings.ContainsRune(fp.AllowedChars, r) {
+		return true
+	}
+
+	for _, class := range fp.Allowed {
+		if classes[class](r) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,157 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr error
+	}{
+		{name: "name", value: "acme"},
+		{name: "empty", value: "", wantErr: ErrTooShort},
+		{name: "whitespace", value: strings.Repeat(" ", 10<<10), wantErr: ErrTooShort},
+		{name: "too long", value: strings.Repeat("a", defaultNameMaxLength+1), wantErr: ErrTooLong},
+		{name: "max length after trimming", value: " " + strings.Repeat("a", defaultNameMaxLength) + " "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DefaultPolicy().Name.Validate(tt.value)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, DefaultPolicy().Description.Validate(""), "descriptions are optional")
+}
+
+func TestFieldPolicyNormalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy FieldPolicy
+		value  string
+		want   string
+	}{
+		{name: "unchanged", value: "  Acme  Corp ", want: "  Acme  Corp "},
+		{name: "trim", policy: FieldPolicy{Trim: true}, value: "  Acme  Corp ", want: "Acme  Corp"},
+		{name: "collapse spaces", policy: FieldPolicy{CollapseSpaces: true}, value: "  Acme \t\n Corp ", want: "Acme Corp"},
+		{name: "nfc", policy: FieldPolicy{Normalization: NormalizationNFC}, value: "Cafe\u0301", want: "Caf\u00e9"},
+		{name: "nfkc", policy: FieldPolicy{Normalization: NormalizationNFKC}, value: "Ａcme", want: "Acme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.Normalize(tt.value))
+		})
+	}
+}
+
+func TestFieldPolicyValidate(t *testing.T) {
+	policy := FieldPolicy{
+		Trim:         true,
+		MinLength:    3,
+		MaxLength:    10,
+		Allowed:      []string{ClassLetter, ClassDigit},
+		AllowedChars: "-_",
+		Reserved:     []string{"admin", "root"},
+		Denylist:     []string{`(?i)darn`},
+	}
+
+	require.NoError(t, policy.compile())
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr error
+	}{
+		{name: "valid", value: "team-42"},
+		{name: "valid after trimming", value: "  team_42  "},
+		{name: "too short", value: "ab", wantErr: ErrTooShort},
+		{name: "too long counts characters", value: "ééééééééééé", wantErr: ErrTooLong},
+		{name: "not too long in characters", value: "éééééééééé"},
+		{name: "invalid character", value: "team 42", wantErr: ErrInvalidCharacter},
+		{name: "reserved", value: "Admin", wantErr: ErrReserved},
+		{name: "denied", value: "DarnTeam", wantErr: ErrDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.value)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+
+	require.NoError(t, os.WriteFile(path, []byte(`
+name:
+  collapse_spaces: true
+  max_length: 63
+  allowed: [letter, digit, space]
+  allowed_chars: "-"
+  reserved: [admin]
+  denylist: ["(?i)heck"]
+siblings:
+  unique_names: true
+  case_insensitive: true
+`), 0o600))
+
+	policy, err := LoadPolicy(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, 63, policy.Name.MaxLength)
+	assert.Equal(t, 1, policy.Name.MinLength, "unset rules keep their default")
+	assert.True(t, policy.Name.Trim, "unset rules keep their default")
+	assert.Equal(t, defaultDescriptionMaxLength, policy.Description.MaxLength)
+	assert.Equal(t, SiblingPolicy{UniqueNames: true, CaseInsensitive: true}, policy.Siblings)
+
+	assert.Equal(t, "Acme Corp", policy.Name.Normalize("  Acme   Corp "))
+	assert.ErrorIs(t, policy.Name.Validate("what the heck"), ErrDenied)
+	assert.ErrorIs(t, policy.Name.Validate("acme!"), ErrInvalidCharacter)
+}
+
+func TestLoadPolicyErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+	}{
+		{name: "unknown rule", policy: "name:\n  max_len: 10\n"},
+		{name: "unknown class", policy: "name:\n  allowed: [emoji]\n"},
+		{name: "unknown normalization", policy: "description:\n  normalization: nfd\n"},
+		{name: "invalid pattern", policy: "name:\n  denylist: [\"(\"]\n"},
+		{name: "min greater than max", policy: "name:\n  min_length: 10\n  max_length: 5\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.policy), 0o600))
+
+			_, err := LoadPolicy(path)
+			assert.ErrorIs(t, err, ErrInvalidPolicy)
+		})
+	}
+
+	_, err := LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, ErrInvalidPolicy)
+}
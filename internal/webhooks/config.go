@@ -0,0 +1,98 @@
+package webhooks
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"go.infratographer.com/x/viperx"
+)
+
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultMaxAttempts  = 10
+	defaultMinBackoff   = 10 * time.Second
+	defaultMaxBackoff   = time.Hour
+	defaultPollInterval = 5 * time.Second
+	defaultConcurrency  = 4
+	defaultBatchSize    = 100
+)
+
+// Config stores the configuration for webhook delivery
+type Config struct {
+	// Enabled runs the delivery worker, subscriptions can be managed when
+	// it's disabled but nothing is delivered
+	Enabled bool `mapstructure:"enabled"`
+	// Timeout bounds each delivery attempt
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxAttempts is the number of attempts made before a delivery is dead
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// MinBackoff is the delay before the first retry, it doubles with each
+	// failed attempt up to MaxBackoff
+	MinBackoff time.Duration `mapstructure:"min_backoff"`
+	// MaxBackoff is the longest delay between attempts
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+	// PollInterval is how often pending deliveries are checked for
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// Concurrency is the number of deliveries attempted at once
+	Concurrency int `mapstructure:"concurrency"`
+	// AllowedNetworks are the networks, in CIDR notation, webhooks may be
+	// delivered to even though they aren't public, such as 10.0.0.0/8
+	AllowedNetworks []string `mapstructure:"allowed_networks"`
+}
+
+// MustViperFlags returns the cobra flags and viper config for webhook delivery
+func MustViperFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	flags.Bool("webhooks-enabled", false, "deliver tenant changes to webhook subscriptions, requires an events subscriber")
+	viperx.MustBindFlag(v, "webhooks.enabled", flags.Lookup("webhooks-enabled"))
+
+	flags.Duration("webhooks-timeout", defaultTimeout, "timeout for each webhook delivery attempt")
+	viperx.MustBindFlag(v, "webhooks.timeout", flags.Lookup("webhooks-timeout"))
+
+	flags.Int("webhooks-max-attempts", defaultMaxAttempts, "number of attempts made before a webhook delivery is dead")
+	viperx.MustBindFlag(v, "webhooks.max_attempts", flags.Lookup("webhooks-max-attempts"))
+
+	flags.Duration("webhooks-min-backoff", defaultMinBackoff, "delay before a failed webhook delivery is first retried")
+	viperx.MustBindFlag(v, "webhooks.min_backoff", flags.Lookup("webhooks-min-backoff"))
+
+	flags.Duration("webhooks-max-backoff", defaultMaxBackoff, "longest delay between webhook delivery attempts")
+	viperx.MustBindFlag(v, "webhooks.max_backoff", flags.Lookup("webhooks-max-backoff"))
+
+	flags.Duration("webhooks-poll-interval", defaultPollInterval, "how often pending webhook deliveries are checked for")
+	viperx.MustBindFlag(v, "webhooks.poll_interval", flags.Lookup("webhooks-poll-interval"))
+
+	flags.Int("webhooks-concurrency", defaultConcurrency, "number of webhook deliveries attempted at once")
+	viperx.MustBindFlag(v, "webhooks.concurrency", flags.Lookup("webhooks-concurrency"))
+
+	flags.StringSlice("webhooks-allowed-networks", nil, "networks, in CIDR notation, webhooks may be delivered to even though they aren't public")
+	viperx.MustBindFlag(v, "webhooks.allowed_networks", flags.Lookup("webhooks-allowed-networks"))
+}
+
+// withDefaults returns the config with defaults for unset values
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = defaultMaxAttempts
+	}
+
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = defaultMinBackoff
+	}
+
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = c.MinBackoff
+	}
+
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaultPollInterval
+	}
+
+	if c.Concurrency <= 0 {
+		c.Concurrency = defaultConcurrency
+	}
+
+	return c
+}
@@ -0,0 +1,187 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"entgo.io/ent"
+
+	generated "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/webhooksubscription"
+	"go.infratographer.com/tenant-api/internal/validation"
+)
+
+const (
+	dialTimeout   = 30 * time.Second
+	dialKeepAlive = 30 * time.Second
+)
+
+var (
+	// ErrPrivateTarget is returned when a webhook url resolves to an address
+	// which isn't public and isn't in an allowed network
+	ErrPrivateTarget = errors.New("webhook url must resolve to a public address")
+	// ErrInvalidNetwork is returned when an allowed network isn't a CIDR
+	ErrInvalidNetwork = errors.New("invalid allowed webhook network")
+
+	// nonPublicNetworks are the networks which aren't reachable on the
+	// internet and aren't covered by the netip address classes
+	nonPublicNetworks = []netip.Prefix{
+		// "this" network
+		netip.MustParsePrefix("0.0.0.0/8"),
+		// shared address space, used by carrier-grade NAT and some cloud
+		// metadata services
+		netip.MustParsePrefix("100.64.0.0/10"),
+		// benchmarking
+		netip.MustParsePrefix("198.18.0.0/15"),
+		// NAT64
+		netip.MustParsePrefix("64:ff9b::/96"),
+	}
+)
+
+// Targets decides which addresses webhooks may be delivered to. Only public
+// addresses are allowed, unless they're in one of the allowed networks, so
+// subscriptions can't be used to reach the loopback interface, private
+// networks or cloud metadata services.
+type Targets struct {
+	allowed  []netip.Prefix
+	resolver *net.Resolver
+}
+
+// NewTargets returns targets allowing public addresses and the given
+// networks, in CIDR notation
+func NewTargets(allowedNetworks []string) (*Targets, error) {
+	t := publicTargets()
+
+	for _, n := range allowedNetworks {
+		prefix, err := netip.ParsePrefix(n)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidNetwork, n)
+		}
+
+		t.allowed = append(t.allowed, prefix.Masked())
+	}
+
+	return t, nil
+}
+
+// publicTargets returns targets only allowing public addresses
+func publicTargets() *Targets {
+	return &Targets{resolver: net.DefaultResolver}
+}
+
+// Allowed returns ErrPrivateTarget when webhooks can't be delivered to the
+// address
+func (t *Targets) Allowed(addr netip.Addr) error {
+	addr = addr.Unmap()
+
+	for _, prefix := range t.allowed {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+
+	if !public(addr) {
+		return fmt.Errorf("%w: %s isn't public", ErrPrivateTarget, addr)
+	}
+
+	return nil
+}
+
+// public reports whether the address is reachable on the internet
+func public(addr netip.Addr) bool {
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range nonPublicNetworks {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckURL returns ErrPrivateTarget when any address the url's host resolves
+// to isn't allowed
+func (t *Targets) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := u.Hostname()
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return t.Allowed(addr)
+	}
+
+	addrs, err := t.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: resolving %s: %v", ErrPrivateTarget, host, err)
+	}
+
+	for _, addr := range addrs {
+		if err := t.Allowed(addr); err != nil {
+			return fmt.Errorf("%s: %w", host, err)
+		}
+	}
+
+	return nil
+}
+
+// Hook is an ent hook which rejects webhook subscriptions whose url isn't
+// allowed. The address is checked again when each delivery is dialed, as the
+// host may resolve differently by then.
+func (t *Targets) Hook(next ent.Mutator) ent.Mutator {
+	return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+		sm, ok := m.(*generated.WebhookSubscriptionMutation)
+		if !ok {
+			return next.Mutate(ctx, m)
+		}
+
+		if u, ok := sm.URL(); ok {
+			if err := t.CheckURL(ctx, u); err != nil {
+				return nil, &validation.FieldError{Field: webhooksubscription.FieldURL, Err: err}
+			}
+		}
+
+		return next.Mutate(ctx, m)
+	})
+}
+
+// control rejects connections to addresses which aren't allowed, it's run
+// once the address is resolved so hosts resolving differently than when the
+// subscription was saved are caught
+func (t *Targets) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPrivateTarget, err)
+	}
+
+	return t.Allowed(addrPort.Addr())
+}
+
+// httpClient returns a client which only connects to allowed addresses.
+// Deliveries aren't proxied, as the proxy's address would be checked instead
+// of the webhook's.
+func (t *Targets) httpClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: dialKeepAlive,
+		Control:   t.control,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
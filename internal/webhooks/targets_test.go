@@ -0,0 +1,103 @@
+package webhooks
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+
+	"go.infratographer.com/tenant-api/internal/ent/generated/webhookdelivery"
+	"go.infratographer.com/tenant-api/internal/validation"
+)
+
+func TestTargetsAllowed(t *testing.T) {
+	targets, err := NewTargets([]string{"10.1.0.0/16"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{addr: "93.184.216.34", allowed: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", allowed: true},
+		{addr: "10.1.2.3", allowed: true},
+		{addr: "10.2.0.1"},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "169.254.169.254"},
+		{addr: "fe80::1"},
+		{addr: "fd00:ec2::254"},
+		{addr: "192.168.1.1"},
+		{addr: "172.16.0.1"},
+		{addr: "100.100.100.200"},
+		{addr: "0.0.0.0"},
+		{addr: "224.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			err := targets.Allowed(netip.MustParseAddr(tt.addr))
+
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrPrivateTarget)
+			}
+		})
+	}
+}
+
+func TestNewTargetsInvalidNetwork(t *testing.T) {
+	_, err := NewTargets([]string{"10.0.0.0"})
+	assert.ErrorIs(t, err, ErrInvalidNetwork)
+}
+
+func TestTargetsHook(t *testing.T) {
+	ctx := context.Background()
+
+	client := newTestClient(t, "webhooks-targets-hook")
+
+	client.Use(publicTargets().Hook)
+
+	for _, u := range []string{"http://127.0.0.1:8080/hook", "http://localhost/hook", "http://[::1]/hook", "http://169.254.169.254/latest/meta-data"} {
+		err := client.WebhookSubscription.Create().SetURL(u).SetSecret("shh").Exec(ctx)
+		assert.ErrorIs(t, err, ErrPrivateTarget, u)
+		assert.True(t, validation.IsFieldError(err), u)
+	}
+
+	sub := client.WebhookSubscription.Create().SetURL("http://93.184.216.34/hook").SetSecret("shh").SaveX(ctx)
+
+	err := client.WebhookSubscription.UpdateOne(sub).SetURL("http://10.0.0.1/hook").Exec(ctx)
+	assert.ErrorIs(t, err, ErrPrivateTarget, "updated urls are checked")
+}
+
+func TestDeliverPrivateTarget(t *testing.T) {
+	ctx := context.Background()
+
+	client := newTestClient(t, "webhooks-private-target")
+
+	tnt := client.Tenant.Create().SetName("tenant").SaveX(ctx)
+
+	recv := newReceiver(t)
+
+	// the subscription was saved without the hook, or its host has since
+	// been rebound to a private address
+	client.WebhookSubscription.Create().SetURL(recv.URL).SetSecret("shh").ExecX(ctx)
+
+	w := NewWorker(client, Config{})
+
+	_, err := w.Enqueue(ctx, events.ChangeMessage{SubjectID: tnt.ID, EventType: string(events.UpdateChangeType)})
+	require.NoError(t, err)
+
+	_, err = w.DeliverPending(ctx)
+	require.NoError(t, err)
+
+	delivery := client.WebhookDelivery.Query().OnlyX(ctx)
+	assert.Equal(t, webhookdelivery.StatusPENDING, delivery.Status)
+	assert.Contains(t, delivery.LastError, ErrPrivateTarget.Error())
+	assert.Zero(t, recv.received(), "private addresses aren't dialed")
+}
@@ -0,0 +1,106 @@
+// Package webhooks delivers tenant change messages to webhook subscriptions.
+//
+// Change messages are received from the events subscriber and recorded as a
+// delivery for every matching subscription, so deliveries survive restarts
+// and can be inspected. Pending deliveries are posted as JSON, signed with
+// the subscription's secret, and retried with exponential backoff until they
+// succeed or run out of attempts, when they're dead. Delivery is at least
+// once, receivers should use the delivery ID header to drop duplicates.
+//
+// Webhooks are only delivered to public addresses, unless they're in an
+// allowed network, which is checked when a subscription is saved and again
+// when each delivery is dialed.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.infratographer.com/x/events"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 of the request body, keyed
+	// with the subscription secret, as sha256=<hex>
+	SignatureHeader = "X-Webhook-Signature"
+	// DeliveryHeader carries the ID of the delivery, it's the same for every
+	// attempt of a delivery
+	DeliveryHeader = "X-Webhook-Delivery"
+	// EventHeader carries the event type of the change
+	EventHeader = "X-Webhook-Event"
+
+	signaturePrefix = "sha256="
+	userAgent       = "tenant-api-webhooks"
+)
+
+var (
+	// ErrUnknownEventType is returned when a subscription names an event type
+	// which isn't published
+	ErrUnknownEventType = errors.New("unknown event type")
+	// ErrDeliveryNotDead is returned when retrying a delivery which is still
+	// pending or was delivered
+	ErrDeliveryNotDead = errors.New("only dead deliveries can be retried")
+)
+
+// EventTypes are the event types which can be subscribed to
+var EventTypes = []events.ChangeType{
+	events.CreateChangeType,
+	events.UpdateChangeType,
+	events.DeleteChangeType,
+}
+
+// Sign returns the signature header value for the body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature header value was made by signing the
+// body with the secret
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(strings.TrimSpace(signature)))
+}
+
+// ValidateEventTypes returns an error when any of the event types can't be
+// subscribed to
+func ValidateEventTypes(eventTypes []string) error {
+	for _, t := range eventTypes {
+		if !subscribable(t) {
+			return fmt.Errorf("%w: %q", ErrUnknownEventType, t)
+		}
+	}
+
+	return nil
+}
+
+func subscribable(eventType string) bool {
+	for _, t := range EventTypes {
+		if string(t) == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// subscribed reports whether a subscription to the event types receives the
+// event type, subscriptions without event types receive every event type
+func subscribed(eventTypes []string, eventType string) bool {
+	if len(eventTypes) == 0 {
+		return true
+	}
+
+	for _, t := range eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
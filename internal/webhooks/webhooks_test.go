@@ -0,0 +1,42 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"subject_id":"tnntten-abc"}`)
+
+	sig := Sign("secret", body)
+
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, sig)
+	assert.True(t, Verify("secret", body, sig))
+	assert.False(t, Verify("other", body, sig))
+	assert.False(t, Verify("secret", []byte(`{}`), sig))
+	assert.False(t, Verify("secret", body, ""))
+}
+
+func TestValidateEventTypes(t *testing.T) {
+	assert.NoError(t, ValidateEventTypes(nil))
+	assert.NoError(t, ValidateEventTypes([]string{"create", "delete"}))
+	assert.ErrorIs(t, ValidateEventTypes([]string{"update", "rename"}), ErrUnknownEventType)
+}
+
+func TestSubscribed(t *testing.T) {
+	assert.True(t, subscribed(nil, "create"))
+	assert.True(t, subscribed([]string{"create", "update"}, "update"))
+	assert.False(t, subscribed([]string{"create"}, "delete"))
+}
+
+func TestBackoff(t *testing.T) {
+	w := &Worker{cfg: Config{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}}
+
+	assert.Equal(t, time.Second, w.backoff(1))
+	assert.Equal(t, 2*time.Second, w.backoff(2))
+	assert.Equal(t, 8*time.Second, w.backoff(4))
+	assert.Equal(t, 10*time.Second, w.backoff(5))
+	assert.Equal(t, 10*time.Second, w.backoff(100))
+}
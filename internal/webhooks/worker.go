@@ -0,0 +1,512 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+	"go.uber.org/zap"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/webhookdelivery"
+	"go.infratographer.com/tenant-api/internal/ent/generated/webhooksubscription"
+	"go.infratographer.com/tenant-api/internal/ent/schema"
+	"go.infratographer.com/tenant-api/internal/metrics"
+	"go.infratographer.com/tenant-api/internal/pubsub"
+)
+
+const (
+	// changesTopic matches all tenant change messages
+	changesTopic = "*.tenant"
+
+	// maxResponseBody is how much of a response is read so the connection
+	// can be reused, the body itself isn't kept
+	maxResponseBody = 64 << 10
+
+	resultDelivered = "delivered"
+	resultRetrying  = "retrying"
+	resultDead      = "dead"
+)
+
+var (
+	// ErrSubscriptionClosed is returned by Run when the change subscription ends
+	ErrSubscriptionClosed = errors.New("change subscription closed")
+	// ErrUnexpectedStatus is recorded when a webhook responds with a status
+	// other than 2xx
+	ErrUnexpectedStatus = errors.New("unexpected response status")
+)
+
+// ChangeSubscriber subscribes to change messages, it's satisfied by
+// *events.Subscriber
+type ChangeSubscriber interface {
+	SubscribeChanges(ctx context.Context, topic string) (<-chan *message.Message, error)
+}
+
+// Worker records deliveries for tenant changes and posts them to the
+// subscribed webhooks
+type Worker struct {
+	client     *ent.Client
+	httpClient *http.Client
+	targets    *Targets
+	cfg        Config
+	logger     *zap.SugaredLogger
+	now        func() time.Time
+}
+
+// Option configures a Worker
+type Option func(*Worker)
+
+// WithLogger sets the logger for the worker
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(w *Worker) {
+		w.logger = logger
+	}
+}
+
+// WithHTTPClient sets the client deliveries are posted with, by default a
+// client with the configured timeout which only connects to the allowed
+// targets is used
+func WithHTTPClient(client *http.Client) Option {
+	return func(w *Worker) {
+		w.httpClient = client
+	}
+}
+
+// WithTargets sets the addresses deliveries may be posted to, by default only
+// public addresses are allowed
+func WithTargets(targets *Targets) Option {
+	return func(w *Worker) {
+		w.targets = targets
+	}
+}
+
+// NewWorker returns a worker delivering the changes of tenants loaded with
+// client to the webhook subscriptions stored with it
+func NewWorker(client *ent.Client, cfg Config, opts ...Option) *Worker {
+	cfg = cfg.withDefaults()
+
+	w := &Worker{
+		client: client,
+		cfg:    cfg,
+		logger: zap.NewNop().Sugar(),
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.targets == nil {
+		w.targets = publicTargets()
+	}
+
+	if w.httpClient == nil {
+		w.httpClient = w.targets.httpClient(cfg.Timeout)
+	}
+
+	return w
+}
+
+// Run records deliveries for the tenant changes received from sub and
+// attempts pending deliveries, it returns when the context is canceled or the
+// subscription ends.
+func (w *Worker) Run(ctx context.Context, sub ChangeSubscriber) error {
+	messages, err := sub.SubscribeChanges(ctx, changesTopic)
+	if err != nil {
+		return fmt.Errorf("subscribing to tenant changes: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		w.deliverLoop(ctx)
+	}()
+
+	// stop delivering before returning
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return ErrSubscriptionClosed
+			}
+
+			w.handleMessage(ctx, msg)
+		}
+	}
+}
+
+func (w *Worker) handleMessage(ctx context.Context, msg *message.Message) {
+	change, err := events.UnmarshalChangeMessage(msg.Payload)
+	if err != nil {
+		// redelivering a malformed message won't fix it
+		w.logger.Warnw("failed to unmarshal change message", "error", err, "message_id", msg.UUID)
+
+		msg.Ack()
+
+		return
+	}
+
+	if _, err := w.Enqueue(ctx, change); err != nil {
+		w.logger.Errorw("failed to record webhook deliveries", "error", err, "message_id", msg.UUID, "subject_id", change.SubjectID)
+
+		msg.Nack()
+
+		return
+	}
+
+	msg.Ack()
+}
+
+func (w *Worker) deliverLoop(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.DeliverPending(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Errorw("failed to deliver pending webhooks", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Enqueue records a pending delivery of the change for every subscription
+// which receives it, and returns the number recorded. Changes with an event
+// sequence number are recorded once for each subscription, so redelivered
+// change messages don't record duplicate deliveries.
+func (w *Worker) Enqueue(ctx context.Context, change events.ChangeMessage) (int, error) {
+	scopes, err := w.scopes(ctx, change)
+	if err != nil {
+		return 0, fmt.Errorf("loading tenant ancestry: %w", err)
+	}
+
+	subs, err := w.client.WebhookSubscription.Query().
+		Where(webhooksubscription.Or(
+			webhooksubscription.TenantIDIsNil(),
+			webhooksubscription.TenantIDIn(scopes...),
+		)).
+		All(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("loading webhook subscriptions: %w", err)
+	}
+
+	payload, err := json.Marshal(change)
+	if err != nil {
+		return 0, fmt.Errorf("encoding change message: %w", err)
+	}
+
+	seq, recorded, err := w.recorded(ctx, change)
+	if err != nil {
+		return 0, fmt.Errorf("loading recorded webhook deliveries: %w", err)
+	}
+
+	now := w.now()
+
+	var deliveries []*ent.WebhookDeliveryCreate
+
+	for _, sub := range subs {
+		if !subscribed(sub.EventTypes, change.EventType) || recorded[sub.ID] {
+			continue
+		}
+
+		deliveries = append(deliveries, w.client.WebhookDelivery.Create().
+			SetSubscriptionID(sub.ID).
+			SetEventType(change.EventType).
+			SetSubjectID(change.SubjectID).
+			SetNillableEventSequence(seq).
+			SetPayload(string(payload)).
+			SetNextAttemptAt(now),
+		)
+	}
+
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	err = w.client.WebhookDelivery.CreateBulk(deliveries...).Exec(ctx)
+
+	switch {
+	case ent.IsConstraintError(err) && seq != nil:
+		// another replica recorded the redelivered change first
+		return 0, nil
+	case err != nil:
+		return 0, fmt.Errorf("recording webhook deliveries: %w", err)
+	}
+
+	return len(deliveries), nil
+}
+
+// recorded returns the change's event sequence number and the subscriptions
+// its deliveries are already recorded for, changes without a sequence number
+// can't have been recorded
+func (w *Worker) recorded(ctx context.Context, change events.ChangeMessage) (*int64, map[gidx.PrefixedID]bool, error) {
+	value, ok := change.SubjectFields[pubsub.SequenceField]
+	if !ok {
+		return nil, nil, nil
+	}
+
+	seq, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		w.logger.Warnw("ignoring invalid event sequence", "error", err, "subject_id", change.SubjectID, "event_sequence", value)
+
+		return nil, nil, nil
+	}
+
+	subIDs, err := w.client.WebhookDelivery.Query().
+		Where(
+			webhookdelivery.SubjectID(change.SubjectID),
+			webhookdelivery.EventSequence(seq),
+		).
+		Select(webhookdelivery.FieldSubscriptionID).
+		Strings(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recorded := make(map[gidx.PrefixedID]bool, len(subIDs))
+
+	for _, id := range subIDs {
+		recorded[gidx.PrefixedID(id)] = true
+	}
+
+	return &seq, recorded, nil
+}
+
+// scopes returns the changed tenant and its ancestors, which are the tenants
+// whose subscriptions receive the change
+func (w *Worker) scopes(ctx context.Context, change events.ChangeMessage) ([]gidx.PrefixedID, error) {
+	scopes := []gidx.PrefixedID{}
+	seen := map[gidx.PrefixedID]bool{}
+
+	for id := change.SubjectID; id != gidx.NullPrefixedID && !seen[id]; {
+		seen[id] = true
+		scopes = append(scopes, id)
+
+		t, err := w.client.Tenant.Get(ctx, id)
+
+		switch {
+		case err == nil:
+			id = t.ParentTenantID
+		case ent.IsNotFound(err) && id == change.SubjectID:
+			// a deleted tenant can't be loaded, its parent is published as
+			// an additional subject
+			id = parentSubject(change)
+		case ent.IsNotFound(err):
+			id = gidx.NullPrefixedID
+		default:
+			return nil, err
+		}
+	}
+
+	return scopes, nil
+}
+
+func parentSubject(change events.ChangeMessage) gidx.PrefixedID {
+	for _, id := range change.AdditionalSubjectIDs {
+		if id.Prefix() == schema.TenantPrefix {
+			return id
+		}
+	}
+
+	return gidx.NullPrefixedID
+}
+
+// DeliverPending attempts the deliveries which are due, and returns the
+// number attempted
+func (w *Worker) DeliverPending(ctx context.Context) (int, error) {
+	due, err := w.client.WebhookDelivery.Query().
+		Where(
+			webhookdelivery.StatusEQ(webhookdelivery.StatusPENDING),
+			webhookdelivery.NextAttemptAtLTE(w.now()),
+		).
+		Order(ent.Asc(webhookdelivery.FieldNextAttemptAt)).
+		Limit(defaultBatchSize).
+		WithSubscription().
+		All(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("loading pending webhook deliveries: %w", err)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		attempted int
+		sem       = make(chan struct{}, w.cfg.Concurrency)
+	)
+
+	for _, d := range due {
+		sem <- struct{}{}
+
+		wg.Add(1)
+
+		go func(d *ent.WebhookDelivery) {
+			defer func() {
+				<-sem
+
+				wg.Done()
+			}()
+
+			ok, err := w.attempt(ctx, d)
+			if err != nil {
+				w.logger.Errorw("failed to record webhook delivery attempt", "error", err, "delivery_id", d.ID)
+			}
+
+			if ok {
+				mu.Lock()
+				attempted++
+				mu.Unlock()
+			}
+		}(d)
+	}
+
+	wg.Wait()
+
+	return attempted, nil
+}
+
+// attempt posts the delivery and records the result, it reports whether the
+// delivery was attempted, which it isn't when another worker claimed it
+func (w *Worker) attempt(ctx context.Context, d *ent.WebhookDelivery) (bool, error) {
+	claimed, err := w.claim(ctx, d)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	now := w.now()
+	status, postErr := w.post(ctx, d)
+
+	update := w.client.WebhookDelivery.UpdateOneID(d.ID).
+		AddAttempts(1).
+		SetLastAttemptAt(now)
+
+	if status != 0 {
+		update.SetResponseStatus(status)
+	} else {
+		update.ClearResponseStatus()
+	}
+
+	attempts := d.Attempts + 1
+
+	result := resultDelivered
+
+	switch {
+	case postErr == nil:
+		update.SetStatus(webhookdelivery.StatusDELIVERED).ClearLastError()
+	case attempts >= w.cfg.MaxAttempts:
+		result = resultDead
+
+		update.SetStatus(webhookdelivery.StatusDEAD).SetLastError(postErr.Error())
+	default:
+		result = resultRetrying
+
+		update.SetNextAttemptAt(now.Add(w.backoff(attempts))).SetLastError(postErr.Error())
+	}
+
+	metrics.RecordWebhookDelivery(result)
+
+	if result == resultDead {
+		w.logger.Warnw("webhook delivery is dead", "delivery_id", d.ID, "subscription_id", d.SubscriptionID, "attempts", attempts, "error", postErr)
+	}
+
+	return true, update.Exec(ctx)
+}
+
+// claim takes the delivery so it isn't attempted by other workers, the claim
+// lapses after the attempt could have timed out in case this worker stops
+func (w *Worker) claim(ctx context.Context, d *ent.WebhookDelivery) (bool, error) {
+	now := w.now()
+
+	n, err := w.client.WebhookDelivery.Update().
+		Where(
+			webhookdelivery.ID(d.ID),
+			webhookdelivery.StatusEQ(webhookdelivery.StatusPENDING),
+			webhookdelivery.AttemptsEQ(d.Attempts),
+			webhookdelivery.NextAttemptAtLTE(now),
+		).
+		SetNextAttemptAt(now.Add(2 * w.cfg.Timeout)).
+		Save(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// post sends the delivery to the webhook and returns the response status,
+// which is zero when no response was received
+func (w *Worker) post(ctx context.Context, d *ent.WebhookDelivery) (int, error) {
+	sub := d.Edges.Subscription
+	body := []byte(d.Payload)
+
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, body))
+	req.Header.Set(DeliveryHeader, d.ID.String())
+	req.Header.Set(EventHeader, d.EventType)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the attempt after the given number of
+// failed attempts
+func (w *Worker) backoff(failed int) time.Duration {
+	delay := w.cfg.MinBackoff
+
+	for i := 1; i < failed; i++ {
+		delay *= 2
+
+		if delay >= w.cfg.MaxBackoff {
+			return w.cfg.MaxBackoff
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,372 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+	"go.infratographer.com/tenant-api/internal/ent/generated/webhookdelivery"
+	"go.infratographer.com/tenant-api/internal/pubsub"
+)
+
+type fakeSubscriber struct {
+	messages chan *message.Message
+}
+
+func (s fakeSubscriber) SubscribeChanges(_ context.Context, _ string) (<-chan *message.Message, error) {
+	return s.messages, nil
+}
+
+// receiver records the requests posted to it and responds with the next
+// queued status, or 200 once the queue is empty
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+
+	r := &receiver{statuses: statuses}
+
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+
+		status := http.StatusOK
+
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+
+		w.WriteHeader(status)
+	}))
+
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func (r *receiver) received() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.requests)
+}
+
+func newTestClient(t *testing.T, name string) *ent.Client {
+	t.Helper()
+
+	client := enttest.Open(t, "sqlite3", "file:"+name+"?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+// newTestWorker returns a worker which may deliver to the loopback receivers
+func newTestWorker(t *testing.T, client *ent.Client, cfg Config) *Worker {
+	t.Helper()
+
+	targets, err := NewTargets([]string{"127.0.0.0/8", "::1/128"})
+	require.NoError(t, err)
+
+	return NewWorker(client, cfg, WithTargets(targets))
+}
+
+func TestEnqueue(t *testing.T) {
+	ctx := context.Background()
+
+	client := newTestClient(t, "webhooks-enqueue")
+
+	root := client.Tenant.Create().SetName("root").SaveX(ctx)
+	child := client.Tenant.Create().SetName("child").SetParent(root).SaveX(ctx)
+	other := client.Tenant.Create().SetName("other").SaveX(ctx)
+
+	global := client.WebhookSubscription.Create().SetURL("http://example.com/all").SetSecret("s").SaveX(ctx)
+	rootUpdates := client.WebhookSubscription.Create().SetURL("http://example.com/root").SetSecret("s").
+		SetTenantID(root.ID).SetEventTypes([]string{"update"}).SaveX(ctx)
+	client.WebhookSubscription.Create().SetURL("http://example.com/other").SetSecret("s").SetTenantID(other.ID).ExecX(ctx)
+
+	w := newTestWorker(t, client, Config{})
+
+	update := events.ChangeMessage{
+		SubjectID:            child.ID,
+		EventType:            string(events.UpdateChangeType),
+		AdditionalSubjectIDs: []gidx.PrefixedID{root.ID},
+		Timestamp:            time.Now().UTC(),
+	}
+
+	n, err := w.Enqueue(ctx, update)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	deliveries := client.WebhookDelivery.Query().Order(ent.Asc(webhookdelivery.FieldSubscriptionID)).AllX(ctx)
+	require.Len(t, deliveries, 2)
+
+	subs := []gidx.PrefixedID{deliveries[0].SubscriptionID, deliveries[1].SubscriptionID}
+	assert.ElementsMatch(t, []gidx.PrefixedID{global.ID, rootUpdates.ID}, subs)
+
+	for _, d := range deliveries {
+		assert.Equal(t, webhookdelivery.StatusPENDING, d.Status)
+		assert.Equal(t, child.ID, d.SubjectID)
+		assert.Equal(t, "update", d.EventType)
+
+		var posted events.ChangeMessage
+
+		require.NoError(t, json.Unmarshal([]byte(d.Payload), &posted))
+		assert.Equal(t, update.SubjectID, posted.SubjectID)
+	}
+
+	// deleted tenants are matched to their ancestors through their parent
+	client.Tenant.DeleteOneID(child.ID).ExecX(ctx)
+
+	n, err = w.Enqueue(ctx, events.ChangeMessage{
+		SubjectID:            child.ID,
+		EventType:            string(events.DeleteChangeType),
+		AdditionalSubjectIDs: []gidx.PrefixedID{root.ID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "only the global subscription receives deletes")
+
+	client.WebhookSubscription.UpdateOneID(rootUpdates.ID).ClearEventTypes().ExecX(ctx)
+
+	n, err = w.Enqueue(ctx, events.ChangeMessage{
+		SubjectID:            child.ID,
+		EventType:            string(events.DeleteChangeType),
+		AdditionalSubjectIDs: []gidx.PrefixedID{root.ID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestEnqueueRedelivered(t *testing.T) {
+	ctx := context.Background()
+
+	client := newTestClient(t, "webhooks-redelivered")
+
+	tnt := client.Tenant.Create().SetName("root").SaveX(ctx)
+
+	client.WebhookSubscription.Create().SetURL("http://example.com/all").SetSecret("s").ExecX(ctx)
+	client.WebhookSubscription.Create().SetURL("http://example.com/root").SetSecret("s").SetTenantID(tnt.ID).ExecX(ctx)
+
+	w := newTestWorker(t, client, Config{})
+
+	update := events.ChangeMessage{
+		SubjectID:     tnt.ID,
+		SubjectFields: map[string]string{pubsub.SequenceField: "2"},
+		EventType:     string(events.UpdateChangeType),
+	}
+
+	n, err := w.Enqueue(ctx, update)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = w.Enqueue(ctx, update)
+	require.NoError(t, err)
+	assert.Zero(t, n, "a redelivered change isn't recorded again")
+
+	deliveries := client.WebhookDelivery.Query().AllX(ctx)
+	require.Len(t, deliveries, 2)
+
+	for _, d := range deliveries {
+		require.NotNil(t, d.EventSequence)
+		assert.Equal(t, int64(2), *d.EventSequence)
+	}
+
+	update.SubjectFields[pubsub.SequenceField] = "3"
+
+	n, err = w.Enqueue(ctx, update)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestDeliverPending(t *testing.T) {
+	ctx := context.Background()
+
+	client := newTestClient(t, "webhooks-deliver")
+
+	tnt := client.Tenant.Create().SetName("tenant").SaveX(ctx)
+
+	recv := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+
+	sub := client.WebhookSubscription.Create().SetURL(recv.URL).SetSecret("shh").SaveX(ctx)
+
+	now := time.Now()
+
+	w := newTestWorker(t, client, Config{MaxAttempts: 3, MinBackoff: time.Minute, MaxBackoff: time.Hour})
+	w.now = func() time.Time { return now }
+
+	_, err := w.Enqueue(ctx, events.ChangeMessage{SubjectID: tnt.ID, EventType: string(events.CreateChangeType)})
+	require.NoError(t, err)
+
+	delivery := client.WebhookDelivery.Query().OnlyX(ctx)
+
+	n, err := w.DeliverPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	delivery = client.WebhookDelivery.GetX(ctx, delivery.ID)
+	assert.Equal(t, webhookdelivery.StatusPENDING, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, *delivery.ResponseStatus)
+	assert.Contains(t, delivery.LastError, "500")
+	assert.WithinDuration(t, now.Add(time.Minute), delivery.NextAttemptAt, time.Second)
+
+	n, err = w.DeliverPending(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "retries wait for the backoff")
+
+	now = now.Add(time.Minute)
+
+	n, err = w.DeliverPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	delivery = client.WebhookDelivery.GetX(ctx, delivery.ID)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.WithinDuration(t, now.Add(2*time.Minute), delivery.NextAttemptAt, time.Second)
+
+	now = now.Add(2 * time.Minute)
+
+	n, err = w.DeliverPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	delivery = client.WebhookDelivery.GetX(ctx, delivery.ID)
+	assert.Equal(t, webhookdelivery.StatusDELIVERED, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, *delivery.ResponseStatus)
+	assert.Empty(t, delivery.LastError)
+
+	require.Equal(t, 3, recv.received())
+
+	req, body := recv.requests[2], recv.bodies[2]
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, delivery.ID.String(), req.Header.Get(DeliveryHeader))
+	assert.Equal(t, "create", req.Header.Get(EventHeader))
+	assert.True(t, Verify("shh", body, req.Header.Get(SignatureHeader)))
+	assert.JSONEq(t, delivery.Payload, string(body))
+
+	assert.Equal(t, sub.ID, delivery.SubscriptionID)
+}
+
+func TestDeliverPendingDead(t *testing.T) {
+	ctx := context.Background()
+
+	client := newTestClient(t, "webhooks-dead")
+
+	tnt := client.Tenant.Create().SetName("tenant").SaveX(ctx)
+
+	recv := newReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError)
+
+	client.WebhookSubscription.Create().SetURL(recv.URL).SetSecret("shh").ExecX(ctx)
+
+	now := time.Now()
+
+	w := newTestWorker(t, client, Config{MaxAttempts: 2, MinBackoff: time.Minute})
+	w.now = func() time.Time { return now }
+
+	_, err := w.Enqueue(ctx, events.ChangeMessage{SubjectID: tnt.ID, EventType: string(events.UpdateChangeType)})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := w.DeliverPending(ctx)
+		require.NoError(t, err)
+
+		now = now.Add(time.Hour)
+	}
+
+	delivery := client.WebhookDelivery.Query().OnlyX(ctx)
+	assert.Equal(t, webhookdelivery.StatusDEAD, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, 2, recv.received(), "dead deliveries aren't attempted")
+}
+
+func TestClaim(t *testing.T) {
+	ctx := context.Background()
+
+	client := newTestClient(t, "webhooks-claim")
+
+	tnt := client.Tenant.Create().SetName("tenant").SaveX(ctx)
+	client.WebhookSubscription.Create().SetURL("http://example.com").SetSecret("shh").ExecX(ctx)
+
+	w := newTestWorker(t, client, Config{})
+
+	_, err := w.Enqueue(ctx, events.ChangeMessage{SubjectID: tnt.ID, EventType: string(events.UpdateChangeType)})
+	require.NoError(t, err)
+
+	delivery := client.WebhookDelivery.Query().OnlyX(ctx)
+
+	claimed, err := w.claim(ctx, delivery)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = w.claim(ctx, delivery)
+	require.NoError(t, err)
+	assert.False(t, claimed, "claimed deliveries can't be claimed again")
+}
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := newTestClient(t, "webhooks-run")
+
+	tnt := client.Tenant.Create().SetName("tenant").SaveX(ctx)
+
+	recv := newReceiver(t)
+
+	client.WebhookSubscription.Create().SetURL(recv.URL).SetSecret("shh").ExecX(ctx)
+
+	sub := fakeSubscriber{messages: make(chan *message.Message)}
+
+	w := newTestWorker(t, client, Config{PollInterval: 10 * time.Millisecond})
+
+	done := make(chan error)
+
+	go func() {
+		done <- w.Run(ctx, sub)
+	}()
+
+	payload, err := json.Marshal(events.ChangeMessage{SubjectID: tnt.ID, EventType: string(events.UpdateChangeType)})
+	require.NoError(t, err)
+
+	msg := message.NewMessage(watermill.NewUUID(), payload)
+	sub.messages <- msg
+
+	select {
+	case <-msg.Acked():
+	case <-time.After(time.Second):
+		t.Fatal("message wasn't acked")
+	}
+
+	assert.Eventually(t, func() bool { return recv.received() == 1 }, time.Second, 10*time.Millisecond)
+
+	close(sub.messages)
+	assert.ErrorIs(t, <-done, ErrSubscriptionClosed)
+}
@@ -0,0 +1,309 @@
+// Package client provides a Go client for the tenant-api.
+//
+// The client uses the versioned REST API, so it only depends on the API
+// remaining compatible and not on the GraphQL schema or generated code.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	apiPath = "/api/v1"
+
+	defaultMaxAttempts = 3
+	defaultMinBackoff  = 100 * time.Millisecond
+	defaultMaxBackoff  = 2 * time.Second
+	defaultTimeout     = 30 * time.Second
+
+	codeTenantHasChildren   = "TENANT_HAS_CHILDREN"
+	codeTenantLimitExceeded = "TENANT_LIMIT_EXCEEDED"
+)
+
+// TokenSource returns the bearer token used to authenticate requests, it's
+// called before every request so tokens may be refreshed
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token returns the token from the function
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticToken returns a TokenSource which always returns the same token
+func StaticToken(token string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// Client is a tenant-api client, it's safe for concurrent use
+type Client struct {
+	baseURL     *url.URL
+	httpClient  *http.Client
+	tokenSource TokenSource
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the http client used to make requests
+func WithHTTPClient(c *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = c
+	}
+}
+
+// WithTokenSource sets the source of the bearer token sent with each request
+func WithTokenSource(ts TokenSource) Option {
+	return func(client *Client) {
+		client.tokenSource = ts
+	}
+}
+
+// WithRetry sets how many times a request is attempted and the bounds of the
+// exponential backoff between attempts. A maxAttempts of 1 disables retries.
+func WithRetry(maxAttempts int, minBackoff, maxBackoff time.Duration) Option {
+	return func(client *Client) {
+		client.maxAttempts = maxAttempts
+		client.minBackoff = minBackoff
+		client.maxBackoff = maxBackoff
+	}
+}
+
+// New returns a client for the tenant-api at baseURL, e.g. https://tenant-api.example.com
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing base url: %w", err)
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidBaseURL, baseURL)
+	}
+
+	c := &Client{
+		baseURL:     u,
+		httpClient:  &http.Client{Timeout: defaultTimeout},
+		maxAttempts: defaultMaxAttempts,
+		minBackoff:  defaultMinBackoff,
+		maxBackoff:  defaultMaxBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.maxAttempts < 1 {
+		c.maxAttempts = 1
+	}
+
+	return c, nil
+}
+
+// do sends the request and decodes the response into out, transient failures
+// are retried with backoff
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body []byte
+
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+
+		body = b
+	}
+
+	u := c.baseURL.JoinPath(apiPath, path)
+	u.RawQuery = query.Encode()
+
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		var retry bool
+
+		retry, err = c.attempt(ctx, method, u.String(), body, out)
+		if err == nil || !retry || attempt >= c.maxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.backoff(attempt)):
+		}
+	}
+}
+
+// attempt makes a single request and reports whether a failure may be retried
+func (c *Client) attempt(ctx context.Context, method, u string, body []byte, out interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.tokenSource != nil {
+		token, err := c.tokenSource.Token(ctx)
+		if err != nil {
+			return false, fmt.Errorf("getting token: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// the server may have processed a request which never got a response,
+		// so only idempotent requests are retried, unless the caller gave up
+		return ctx.Err() == nil && idempotent(method), err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := newError(resp)
+
+		return retryable(method, resp.StatusCode), apiErr
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return false, nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return false, nil
+}
+
+// retryable reports whether a failed response may be retried. Creates are
+// only retried when the server didn't process the request.
+func retryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(method)
+	default:
+		return false
+	}
+}
+
+// idempotent reports whether repeating a request has the same effect as
+// making it once, creates aren't
+func idempotent(method string) bool {
+	return method != http.MethodPost
+}
+
+// backoff returns the full jitter exponential backoff before the next attempt
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.minBackoff << (attempt - 1)
+	if d <= 0 || d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+
+	if d <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(d))) //nolint:gosec // jitter doesn't need a secure random source
+}
+
+func newError(resp *http.Response) *Error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16)) //nolint:errcheck // the status is enough when the body can't be read
+
+	var body struct {
+		Message string `json:"message"`
+		Code    string `json:"code"`
+	}
+
+	if json.Unmarshal(b, &body) == nil && body.Message != "" {
+		apiErr.Message = body.Message
+		apiErr.Code = body.Code
+	} else {
+		apiErr.Message = strings.TrimSpace(string(b))
+	}
+
+	return apiErr
+}
+
+// Error is returned when the tenant-api responds with an error status. Use
+// errors.Is with ErrNotFound, ErrForbidden, ErrUnauthorized, ErrHasChildren
+// and ErrLimitExceeded to check for specific failures.
+type Error struct {
+	StatusCode int
+	Message    string
+	// Code tells apart failures which share a status, it's empty for most
+	// failures
+	Code string
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("tenant-api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	return fmt.Sprintf("tenant-api: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is matches the sentinel error for the status code
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrHasChildren:
+		return e.Code == codeTenantHasChildren
+	case ErrLimitExceeded:
+		return e.Code == codeTenantLimitExceeded
+	default:
+		return false
+	}
+}
+
+var (
+	// ErrNotFound is returned when the tenant doesn't exist
+	ErrNotFound = errors.New("tenant not found")
+	// ErrForbidden is returned when the caller doesn't have permission
+	ErrForbidden = errors.New("permission denied")
+	// ErrUnauthorized is returned when the caller isn't authenticated
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrHasChildren is returned when deleting a tenant which has children
+	ErrHasChildren = errors.New("tenant has children")
+	// ErrLimitExceeded is returned when creating a tenant which would exceed
+	// the depth or children limit of its root tenant
+	ErrLimitExceeded = errors.New("tenant limit exceeded")
+	// ErrInvalidBaseURL is returned when the client's base url isn't absolute
+	ErrInvalidBaseURL = errors.New("base url must be absolute")
+)
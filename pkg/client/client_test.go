@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/tenant-api/internal/ent/generated/enttest"
+	"go.infratographer.com/tenant-api/internal/restapi"
+)
+
+const testToken = "test-token"
+
+// testAuth allows requests with the test token and rejects all others
+func testAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Request().Header.Get(echo.HeaderAuthorization) != "Bearer "+testToken {
+			return echo.ErrUnauthorized
+		}
+
+		ctx := context.WithValue(c.Request().Context(), permissions.CheckerCtxKey, permissions.DefaultAllowChecker)
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		return next(c)
+	}
+}
+
+func newTestClient(t *testing.T, opts ...Option) *Client {
+	t.Helper()
+
+	client := enttest.Open(t, "sqlite3", "file:client?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { client.Close() })
+
+	e := echo.New()
+	restapi.NewHandler(client, []echo.MiddlewareFunc{testAuth}).Routes(e.Group(""))
+
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	c, err := New(srv.URL, opts...)
+	require.NoError(t, err)
+
+	return c
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	c := newTestClient(t, WithTokenSource(StaticToken(testToken)))
+
+	root, err := c.Create(ctx, CreateInput{Name: "root", Description: ptr("the root")})
+	require.NoError(t, err)
+	assert.Equal(t, "root", root.Name)
+	assert.Equal(t, "the root", root.Description)
+	assert.Empty(t, root.ParentID)
+	assert.Equal(t, "TENANT", root.Kind)
+
+	for _, name := range []string{"a", "b", "c"} {
+		child, err := c.Create(ctx, CreateInput{Name: name, ParentID: &root.ID, Kind: "PROJECT"})
+		require.NoError(t, err)
+		assert.Equal(t, root.ID, child.ParentID)
+		assert.Equal(t, "PROJECT", child.Kind)
+	}
+
+	got, err := c.Get(ctx, root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, root.ID, got.ID)
+
+	updated, err := c.Update(ctx, root.ID, UpdateInput{Name: ptr("renamed"), ClearDescription: true})
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", updated.Name)
+	assert.Empty(t, updated.Description)
+
+	var names []string
+
+	it := c.ListChildren(ctx, root.ID, ListOptions{PageSize: 2})
+	for it.Next() {
+		names = append(names, it.Tenant().Name)
+	}
+
+	require.NoError(t, it.Err())
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, names)
+
+	roots := c.ListRoots(ctx, ListOptions{})
+	require.True(t, roots.Next())
+	assert.Equal(t, root.ID, roots.Tenant().ID)
+	assert.False(t, roots.Next())
+	require.NoError(t, roots.Err())
+
+	err = c.Delete(ctx, root.ID)
+	assert.ErrorIs(t, err, ErrHasChildren)
+
+	_, err = c.Get(ctx, gidx.MustNewID("tnntten"))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = c.Delete(ctx, gidx.MustNewID("tnntten"))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestClientUnauthorized(t *testing.T) {
+	c := newTestClient(t, WithTokenSource(StaticToken("wrong")))
+
+	_, err := c.Get(context.Background(), gidx.MustNewID("tnntten"))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.NotErrorIs(t, err, ErrForbidden)
+
+	it := c.ListRoots(context.Background(), ListOptions{})
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Err(), ErrUnauthorized)
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		attempts int32
+	}{
+		{"get retries unavailable", http.MethodGet, http.StatusServiceUnavailable, 3},
+		{"get retries bad gateway", http.MethodGet, http.StatusBadGateway, 3},
+		{"create retries too many requests", http.MethodPost, http.StatusTooManyRequests, 3},
+		{"create doesn't retry bad gateway", http.MethodPost, http.StatusBadGateway, 1},
+		{"forbidden isn't retried", http.MethodGet, http.StatusForbidden, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"message": "failed"}`))
+			}))
+			defer srv.Close()
+
+			c, err := New(srv.URL, WithRetry(3, time.Millisecond, 5*time.Millisecond))
+			require.NoError(t, err)
+
+			if tt.method == http.MethodPost {
+				_, err = c.Create(context.Background(), CreateInput{Name: "test"})
+			} else {
+				_, err = c.Get(context.Background(), gidx.MustNewID("tnntten"))
+			}
+
+			var apiErr *Error
+
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, "failed", apiErr.Message)
+			assert.Equal(t, tt.attempts, atomic.LoadInt32(&calls))
+		})
+	}
+
+	t.Run("transport errors", func(t *testing.T) {
+		for method, attempts := range map[string]int32{http.MethodGet: 3, http.MethodPost: 1} {
+			var calls int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				atomic.AddInt32(&calls, 1)
+
+				// close the connection without a response
+				conn, _, err := w.(http.Hijacker).Hijack()
+				require.NoError(t, err)
+
+				conn.Close()
+			}))
+
+			c, err := New(srv.URL, WithRetry(3, time.Millisecond, 5*time.Millisecond))
+			require.NoError(t, err)
+
+			if method == http.MethodPost {
+				_, err = c.Create(context.Background(), CreateInput{Name: "test"})
+			} else {
+				_, err = c.Get(context.Background(), gidx.MustNewID("tnntten"))
+			}
+
+			srv.Close()
+
+			assert.Error(t, err)
+			assert.Equal(t, attempts, atomic.LoadInt32(&calls), method)
+		}
+	})
+
+	t.Run("recovers", func(t *testing.T) {
+		var calls int32
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"id": "tnntten-abc", "name": "ok"}`))
+		}))
+		defer srv.Close()
+
+		c, err := New(srv.URL, WithRetry(3, time.Millisecond, 5*time.Millisecond))
+		require.NoError(t, err)
+
+		tnt, err := c.Get(context.Background(), "tnntten-abc")
+		require.NoError(t, err)
+		assert.Equal(t, "ok", tnt.Name)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestErrorIs(t *testing.T) {
+	hasChildren := &Error{StatusCode: http.StatusConflict, Message: "tenant has children", Code: codeTenantHasChildren}
+	assert.ErrorIs(t, hasChildren, ErrHasChildren)
+
+	// other conflicts aren't mistaken for a tenant with children
+	assert.NotErrorIs(t, &Error{StatusCode: http.StatusConflict, Message: "conflict"}, ErrHasChildren)
+
+	limitExceeded := &Error{StatusCode: http.StatusUnprocessableEntity, Message: "max children", Code: codeTenantLimitExceeded}
+	assert.ErrorIs(t, limitExceeded, ErrLimitExceeded)
+	assert.NotErrorIs(t, limitExceeded, ErrHasChildren)
+}
+
+func TestNewInvalidBaseURL(t *testing.T) {
+	_, err := New("localhost:7902")
+	assert.ErrorIs(t, err, ErrInvalidBaseURL)
+}
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.infratographer.com/x/gidx"
+)
+
+// Tenant is a tenant in the hierarchy
+type Tenant struct {
+	ID          gidx.PrefixedID `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	// ParentID is empty for root tenants
+	ParentID gidx.PrefixedID `json:"parent_id,omitempty"`
+	// Kind is the kind of tenant, such as ORGANIZATION or PROJECT
+	Kind      string    `json:"kind"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateInput is the tenant to create
+type CreateInput struct {
+	Name        string           `json:"name"`
+	Description *string          `json:"description,omitempty"`
+	ParentID    *gidx.PrefixedID `json:"parent_id,omitempty"`
+	// Kind defaults to TENANT when it's empty
+	Kind string `json:"kind,omitempty"`
+}
+
+// UpdateInput are the changes to make to a tenant, nil fields aren't changed
+type UpdateInput struct {
+	Name             *string `json:"name,omitempty"`
+	Description      *string `json:"description,omitempty"`
+	ClearDescription bool    `json:"clear_description,omitempty"`
+}
+
+// ListOptions configures how tenants are listed
+type ListOptions struct {
+	// PageSize is the number of tenants requested at a time, the server
+	// default is used when it's zero
+	PageSize int
+}
+
+// Get returns the tenant with the given ID
+func (c *Client) Get(ctx context.Context, id gidx.PrefixedID) (*Tenant, error) {
+	var t Tenant
+
+	if err := c.do(ctx, http.MethodGet, "/tenants/"+url.PathEscape(id.String()), nil, nil, &t); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// Create creates a tenant
+func (c *Client) Create(ctx context.Context, input CreateInput) (*Tenant, error) {
+	var t Tenant
+
+	if err := c.do(ctx, http.MethodPost, "/tenants", nil, input, &t); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// Update updates a tenant
+func (c *Client) Update(ctx context.Context, id gidx.PrefixedID, input UpdateInput) (*Tenant, error) {
+	var t Tenant
+
+	if err := c.do(ctx, http.MethodPatch, "/tenants/"+url.PathEscape(id.String()), nil, input, &t); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// Delete deletes a tenant, tenants with children can't be deleted
+func (c *Client) Delete(ctx context.Context, id gidx.PrefixedID) error {
+	return c.do(ctx, http.MethodDelete, "/tenants/"+url.PathEscape(id.String()), nil, nil, nil)
+}
+
+// ListChildren returns an iterator over the direct children of a tenant,
+// pages are requested as the iterator advances
+func (c *Client) ListChildren(ctx context.Context, id gidx.PrefixedID, opts ListOptions) *TenantIterator {
+	return &TenantIterator{
+		ctx:      ctx,
+		client:   c,
+		path:     "/tenants/" + url.PathEscape(id.String()) + "/children",
+		pageSize: opts.PageSize,
+		more:     true,
+	}
+}
+
+// ListRoots returns an iterator over the root tenants
+func (c *Client) ListRoots(ctx context.Context, opts ListOptions) *TenantIterator {
+	return &TenantIterator{
+		ctx:      ctx,
+		client:   c,
+		path:     "/tenants",
+		pageSize: opts.PageSize,
+		more:     true,
+	}
+}
+
+// TenantIterator iterates over a list of tenants
+//
+//	it := c.ListChildren(ctx, id, client.ListOptions{})
+//	for it.Next() {
+//		t := it.Tenant()
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type TenantIterator struct {
+	ctx      context.Context
+	client   *Client
+	path     string
+	pageSize int
+
+	page   []Tenant
+	cursor string
+	more   bool
+
+	current Tenant
+	err     error
+}
+
+type tenantPage struct {
+	Tenants  []Tenant `json:"tenants"`
+	PageInfo struct {
+		HasNextPage bool   `json:"has_next_page"`
+		EndCursor   string `json:"end_cursor"`
+	} `json:"page_info"`
+}
+
+// Next advances to the next tenant, it returns false when there are no more
+// tenants or an error occurred
+func (it *TenantIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	for len(it.page) == 0 {
+		if !it.more {
+			return false
+		}
+
+		if err := it.fetch(); err != nil {
+			it.err = err
+
+			return false
+		}
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+
+	return true
+}
+
+// Tenant returns the current tenant
+func (it *TenantIterator) Tenant() Tenant {
+	return it.current
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *TenantIterator) Err() error {
+	return it.err
+}
+
+func (it *TenantIterator) fetch() error {
+	query := url.Values{}
+
+	if it.pageSize > 0 {
+		query.Set("first", strconv.Itoa(it.pageSize))
+	}
+
+	if it.cursor != "" {
+		query.Set("after", it.cursor)
+	}
+
+	var page tenantPage
+
+	if err := it.client.do(it.ctx, http.MethodGet, it.path, query, nil, &page); err != nil {
+		return err
+	}
+
+	it.page = page.Tenants
+	it.cursor = page.PageInfo.EndCursor
+	it.more = page.PageInfo.HasNextPage && page.PageInfo.EndCursor != ""
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package tenanttest
+
+import (
+	"context"
+	"sync"
+
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/tenant-api/internal/pubsub"
+)
+
+// Change is a change message published by the server
+type Change struct {
+	SubjectType string
+	events.ChangeMessage
+}
+
+// EventRecorder records the change messages published by the server
+type EventRecorder struct {
+	mu      sync.Mutex
+	changes []Change
+}
+
+var _ pubsub.Publisher = (*EventRecorder)(nil)
+
+type skipRecordingCtxKey struct{}
+
+// PublishChange records the change
+func (r *EventRecorder) PublishChange(ctx context.Context, subjectType string, change events.ChangeMessage) error {
+	if ctx.Value(skipRecordingCtxKey{}) != nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.changes = append(r.changes, Change{SubjectType: subjectType, ChangeMessage: change})
+
+	return nil
+}
+
+// Changes returns the changes published so far, in the order they were
+// published
+func (r *EventRecorder) Changes() []Change {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Change(nil), r.changes...)
+}
+
+// ChangesFor returns the published changes with the subject, or the subject
+// in their additional subjects
+func (r *EventRecorder) ChangesFor(id gidx.PrefixedID) []Change {
+	var changes []Change
+
+	for _, c := range r.Changes() {
+		if c.SubjectID == id {
+			changes = append(changes, c)
+
+			continue
+		}
+
+		for _, additional := range c.AdditionalSubjectIDs {
+			if additional == id {
+				changes = append(changes, c)
+
+				break
+			}
+		}
+	}
+
+	return changes
+}
+
+// Reset removes the recorded changes
+func (r *EventRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.changes = nil
+}
@@ -0,0 +1,153 @@
+package tenanttest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/gidx"
+)
+
+// AnyAction matches every action in a permission rule
+const AnyAction = ""
+
+// Check is a permission check made by the server
+type Check struct {
+	Resource gidx.PrefixedID
+	Action   string
+	Allowed  bool
+}
+
+type rule struct {
+	resource gidx.PrefixedID
+	action   string
+}
+
+// Permissions is a scriptable permissions checker. Rules for a resource and
+// action take precedence over rules for AnyAction, which take precedence
+// over the default. It's safe to change while the server is handling
+// requests.
+type Permissions struct {
+	mu           sync.Mutex
+	defaultAllow bool
+	rules        map[rule]bool
+	checker      permissions.Checker
+	checks       []Check
+}
+
+// NewPermissions returns a checker which allows everything
+func NewPermissions() *Permissions {
+	return &Permissions{
+		defaultAllow: true,
+		rules:        map[rule]bool{},
+	}
+}
+
+// AllowAll allows every check without a matching rule
+func (p *Permissions) AllowAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.defaultAllow = true
+}
+
+// DenyAll denies every check without a matching rule
+func (p *Permissions) DenyAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.defaultAllow = false
+}
+
+// Allow allows the action on the resource, use AnyAction to allow every action
+func (p *Permissions) Allow(resource gidx.PrefixedID, action string) {
+	p.setRule(resource, action, true)
+}
+
+// Deny denies the action on the resource, use AnyAction to deny every action
+func (p *Permissions) Deny(resource gidx.PrefixedID, action string) {
+	p.setRule(resource, action, false)
+}
+
+// SetChecker replaces the rules with a custom checker, pass nil to go back
+// to using the rules
+func (p *Permissions) SetChecker(checker permissions.Checker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.checker = checker
+}
+
+// Reset removes all rules, the custom checker and recorded checks, and
+// allows everything again
+func (p *Permissions) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.defaultAllow = true
+	p.rules = map[rule]bool{}
+	p.checker = nil
+	p.checks = nil
+}
+
+// Checks returns the permission checks made so far
+func (p *Permissions) Checks() []Check {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Check(nil), p.checks...)
+}
+
+func (p *Permissions) setRule(resource gidx.PrefixedID, action string, allow bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rules[rule{resource: resource, action: action}] = allow
+}
+
+func (p *Permissions) check(ctx context.Context, resource gidx.PrefixedID, action string) error {
+	p.mu.Lock()
+	checker := p.checker
+	p.mu.Unlock()
+
+	var err error
+
+	if checker != nil {
+		err = checker(ctx, resource, action)
+	} else if !p.allowed(resource, action) {
+		err = permissions.ErrPermissionDenied
+	}
+
+	p.mu.Lock()
+	p.checks = append(p.checks, Check{Resource: resource, Action: action, Allowed: err == nil})
+	p.mu.Unlock()
+
+	return err
+}
+
+func (p *Permissions) allowed(resource gidx.PrefixedID, action string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if allow, ok := p.rules[rule{resource: resource, action: action}]; ok {
+		return allow
+	}
+
+	if allow, ok := p.rules[rule{resource: resource, action: AnyAction}]; ok {
+		return allow
+	}
+
+	return p.defaultAllow
+}
+
+// middleware sets the checker used by the handlers, like the permissions-api
+// middleware does in production
+func (p *Permissions) middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := context.WithValue(c.Request().Context(), permissions.CheckerCtxKey, permissions.Checker(p.check))
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		return next(c)
+	}
+}
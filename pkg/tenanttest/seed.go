@@ -0,0 +1,88 @@
+package tenanttest
+
+import (
+	"context"
+	"testing"
+
+	"go.infratographer.com/x/gidx"
+
+	ent "go.infratographer.com/tenant-api/internal/ent/generated"
+	"go.infratographer.com/tenant-api/internal/ent/generated/tenant"
+)
+
+// Tenant describes a tenant to seed, along with its children
+//
+//	tree := srv.Seed(t, tenanttest.Tenant{
+//		Name: "acme",
+//		Children: []tenanttest.Tenant{
+//			{Name: "engineering"},
+//			{Name: "sales"},
+//		},
+//	})
+//	engineering := tree["acme/engineering"]
+type Tenant struct {
+	// ID is generated when it's empty
+	ID          gidx.PrefixedID
+	Name        string
+	Description string
+	// Kind is the kind of tenant, such as PROJECT, it defaults to TENANT
+	// when it's empty
+	Kind     string
+	Children []Tenant
+}
+
+// Tree maps the path of each seeded tenant, its name joined to its
+// ancestors' names with a slash, to its ID
+type Tree map[string]gidx.PrefixedID
+
+// Seed creates the tenants as roots, along with all their descendants.
+// Seeding doesn't make permission checks or record events.
+func (s *Server) Seed(t testing.TB, tenants ...Tenant) Tree {
+	t.Helper()
+
+	ctx := context.WithValue(context.Background(), skipRecordingCtxKey{}, true)
+
+	tree := Tree{}
+
+	for _, tnt := range tenants {
+		if err := s.seed(ctx, tree, "", nil, tnt); err != nil {
+			t.Fatalf("seeding tenant %q: %s", tnt.Name, err)
+		}
+	}
+
+	return tree
+}
+
+func (s *Server) seed(ctx context.Context, tree Tree, prefix string, parent *ent.Tenant, tnt Tenant) error {
+	create := s.client.Tenant.Create().
+		SetName(tnt.Name).
+		SetDescription(tnt.Description)
+
+	if tnt.ID != "" {
+		create.SetID(tnt.ID)
+	}
+
+	if tnt.Kind != "" {
+		create.SetKind(tenant.Kind(tnt.Kind))
+	}
+
+	if parent != nil {
+		create.SetParent(parent)
+	}
+
+	created, err := create.Save(ctx)
+	if err != nil {
+		return err
+	}
+
+	path := prefix + tnt.Name
+	tree[path] = created.ID
+
+	for _, child := range tnt.Children {
+		if err := s.seed(ctx, tree, path+"/", created, child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
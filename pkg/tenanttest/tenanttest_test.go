@@ -0,0 +1,134 @@
+package tenanttest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.infratographer.com/permissions-api/pkg/permissions"
+	"go.infratographer.com/x/events"
+	"go.infratographer.com/x/gidx"
+
+	"go.infratographer.com/tenant-api/internal/testclient"
+	"go.infratographer.com/tenant-api/pkg/client"
+	"go.infratographer.com/tenant-api/pkg/tenanttest"
+)
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	srv := tenanttest.NewServer(t)
+
+	tree := srv.Seed(t, tenanttest.Tenant{
+		Name: "acme",
+		Children: []tenanttest.Tenant{
+			{Name: "engineering", Children: []tenanttest.Tenant{{Name: "platform"}}},
+			{Name: "sales", Description: "sells things", Kind: "PROJECT"},
+		},
+	})
+
+	require.Len(t, tree, 4)
+	assert.Empty(t, srv.Events.Changes(), "seeding shouldn't record events")
+
+	graph := testclient.NewClient(http.DefaultClient, srv.GraphURL())
+
+	resp, err := graph.GetTenant(ctx, tree["acme/sales"])
+	require.NoError(t, err)
+	assert.Equal(t, "sales", resp.Tenant.Name)
+	assert.Equal(t, "sells things", *resp.Tenant.Description)
+	assert.Equal(t, testclient.TenantKindProject, resp.Tenant.Kind)
+
+	parentID := tree["acme/engineering"]
+
+	created, err := graph.TenantCreate(ctx, testclient.CreateTenantInput{Name: "infra", ParentID: &parentID})
+	require.NoError(t, err)
+
+	changes := srv.Events.Changes()
+	require.Len(t, changes, 1)
+	assert.Equal(t, "tenant", changes[0].SubjectType)
+	assert.Equal(t, string(events.CreateChangeType), changes[0].EventType)
+	assert.Equal(t, created.TenantCreate.Tenant.ID, changes[0].SubjectID)
+	assert.Equal(t, []gidx.PrefixedID{parentID}, changes[0].AdditionalSubjectIDs)
+
+	assert.Len(t, srv.Events.ChangesFor(parentID), 1)
+	assert.Empty(t, srv.Events.ChangesFor(tree["acme"]))
+
+	sdk, err := client.New(srv.URL)
+	require.NoError(t, err)
+
+	var names []string
+
+	it := sdk.ListChildren(ctx, parentID, client.ListOptions{})
+	for it.Next() {
+		names = append(names, it.Tenant().Name)
+	}
+
+	require.NoError(t, it.Err())
+	assert.ElementsMatch(t, []string{"platform", "infra"}, names)
+}
+
+func TestServerPermissions(t *testing.T) {
+	ctx := context.Background()
+
+	srv := tenanttest.NewServer(t)
+
+	tree := srv.Seed(t, tenanttest.Tenant{
+		Name:     "acme",
+		Children: []tenanttest.Tenant{{Name: "engineering"}, {Name: "sales"}},
+	})
+
+	sdk, err := client.New(srv.URL, client.WithRetry(1, 0, 0))
+	require.NoError(t, err)
+
+	srv.Permissions.Deny(tree["acme/sales"], tenanttest.AnyAction)
+	srv.Permissions.Allow(tree["acme/sales"], tenanttest.ActionTenantGet)
+
+	_, err = sdk.Get(ctx, tree["acme/sales"])
+	require.NoError(t, err)
+
+	err = sdk.Delete(ctx, tree["acme/sales"])
+	assert.ErrorIs(t, err, client.ErrForbidden)
+	assert.Empty(t, srv.Events.Changes())
+
+	assert.Equal(t, []tenanttest.Check{
+		{Resource: tree["acme/sales"], Action: tenanttest.ActionTenantGet, Allowed: true},
+		{Resource: tree["acme/sales"], Action: tenanttest.ActionTenantDelete, Allowed: false},
+	}, srv.Permissions.Checks())
+
+	srv.Permissions.DenyAll()
+
+	_, err = sdk.Get(ctx, tree["acme/engineering"])
+	assert.ErrorIs(t, err, client.ErrForbidden)
+
+	srv.Permissions.SetChecker(func(_ context.Context, resource gidx.PrefixedID, _ string) error {
+		if resource == tree["acme/engineering"] {
+			return nil
+		}
+
+		return permissions.ErrPermissionDenied
+	})
+
+	_, err = sdk.Get(ctx, tree["acme/engineering"])
+	require.NoError(t, err)
+
+	srv.Permissions.Reset()
+
+	err = sdk.Delete(ctx, tree["acme/sales"])
+	require.NoError(t, err)
+
+	changes := srv.Events.Changes()
+	require.Len(t, changes, 1)
+	assert.Equal(t, string(events.DeleteChangeType), changes[0].EventType)
+	assert.Len(t, srv.Permissions.Checks(), 1)
+}
+
+func TestServerSeedIDs(t *testing.T) {
+	srv := tenanttest.NewServer(t)
+
+	id := gidx.MustNewID("tnntten")
+
+	tree := srv.Seed(t, tenanttest.Tenant{ID: id, Name: "fixed"})
+	assert.Equal(t, id, tree["fixed"])
+}